@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"r3/config"
 	"r3/types"
+	"strings"
 	"sync"
 )
 
@@ -35,10 +36,56 @@ func GetCounts() (int, int) {
 	return len(hostMapTracked), len(hostMapBlocked)
 }
 
+// returns client host address of request
+// requests from trusted reverse proxies (config file: web.trustedProxies) use the last untrusted address of the X-Forwarded-For header
+// proxies must be trusted explicitly, as the header can be set by any client
+func GetHost(r *http.Request) (string, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !isTrustedProxy(host) {
+		return host, err
+	}
+
+	// each proxy appends the address it received the request from, client is the last address not being a trusted proxy
+	addresses := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(addresses) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(addresses[i])
+		if address == "" {
+			continue
+		}
+		if net.ParseIP(address) == nil {
+			// invalid header value, fall back to last known address
+			return host, nil
+		}
+		host = address
+
+		if !isTrustedProxy(address) {
+			break
+		}
+	}
+	return host, nil
+}
+
+func isTrustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range config.File.Web.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxyIp := net.ParseIP(proxy); proxyIp != nil && proxyIp.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // returns if request should be blocked due to assumed bruteforce attempt
 func Check(r *http.Request) bool {
 
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	host, err := GetHost(r)
 	if err != nil {
 		return true
 	}
@@ -63,7 +110,7 @@ func CheckByHost(host string) bool {
 // uses host part of source address to identify source
 func BadAttempt(r *http.Request) {
 
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	host, err := GetHost(r)
 	if err != nil {
 		// logging error case could flood the logs
 		return
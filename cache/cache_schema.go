@@ -28,6 +28,7 @@ import (
 	"r3/schema/role"
 	"r3/schema/searchBar"
 	"r3/schema/variable"
+	"r3/schema/webhook"
 	"r3/schema/widget"
 	"r3/tools"
 	"r3/types"
@@ -175,6 +176,7 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 		mod.ClientEvents = make([]types.ClientEvent, 0)
 		mod.SearchBars = make([]types.SearchBar, 0)
 		mod.Variables = make([]types.Variable, 0)
+		mod.Webhooks = make([]types.Webhook, 0)
 		mod.Widgets = make([]types.Widget, 0)
 		ModuleApiNameMapId[mod.Name] = make(map[string]uuid.UUID)
 
@@ -341,6 +343,14 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 			return err
 		}
 
+		// get webhooks
+		log.Info(log.ContextCache, "load webhooks")
+
+		mod.Webhooks, err = webhook.Get_tx(ctx, tx, mod.Id)
+		if err != nil {
+			return err
+		}
+
 		// get widgets
 		log.Info(log.ContextCache, "load widgets")
 
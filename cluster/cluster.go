@@ -90,6 +90,14 @@ func StartNode_tx(ctx context.Context, tx pgx.Tx) error {
 		`, nodeId); err != nil {
 			return err
 		}
+
+		// record locks of clients from the last run are not valid anymore
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.data_lock
+			WHERE node_id = $1
+		`, nodeId); err != nil {
+			return err
+		}
 	}
 
 	// store node details
@@ -204,6 +212,19 @@ func CreateEventForNodes_tx(ctx context.Context, tx pgx.Tx, nodeIds []uuid.UUID,
 	}
 	return nil
 }
+
+// creates node events for all nodes, including the current one
+// events are processed after the transaction is committed, useful to inform clients about committed changes
+func createEventsForAllNodes_tx(ctx context.Context, tx pgx.Tx, content string, payload interface{}, target types.ClusterEventTarget) error {
+	nodeIds := make([]uuid.UUID, 0)
+	if err := tx.QueryRow(ctx, `
+		SELECT ARRAY_AGG(id)
+		FROM instance_cluster.node
+	`).Scan(&nodeIds); err != nil {
+		return err
+	}
+	return CreateEventForNodes_tx(ctx, tx, nodeIds, content, payload, target)
+}
 func createEventsForOtherNodes_tx(ctx context.Context, tx pgx.Tx, content string, payload interface{}, target types.ClusterEventTarget) error {
 	return CreateEventForNodes_tx(ctx, tx, []uuid.UUID{}, content, payload, target)
 }
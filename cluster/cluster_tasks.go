@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"r3/bruteforce"
 	"r3/cache"
@@ -144,6 +145,51 @@ func ConfigChanged_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loadConf
 	config.SetLogLevels()
 	return nil
 }
+
+// informs clients watching the given relations about changed data
+// informs clients about changed relations, except the client that originated the change (nil UUID if none)
+func DataChanged(relationIdMapClientIdOrigin map[uuid.UUID]uuid.UUID) {
+	for relationId, clientIdOrigin := range relationIdMapClientIdOrigin {
+		WebsocketClientEvents <- types.ClusterEvent{
+			Content: "dataChanged",
+			Payload: relationId,
+			Target: types.ClusterEventTarget{
+				ClientIdExcluded: clientIdOrigin,
+				Device:           types.WebsocketClientDeviceBrowser,
+			},
+		}
+	}
+}
+
+// data changes are distributed via events to all nodes, including this one
+// clients are only informed once the events are processed, after the changes were committed
+// client ID is the websocket client that changed the data, it already knows about its own changes
+// identical relations of the same call are only stored once, events of separate calls are merged when processed
+func DataChanged_tx(ctx context.Context, tx pgx.Tx, clientId uuid.UUID, relationIds []uuid.UUID) error {
+	payloads := make([]string, 0, len(relationIds))
+	for _, relationId := range relationIds {
+		payloadJson, err := json.Marshal(types.ClusterEventDataChanged{
+			ClientIdOrigin: clientId,
+			RelationId:     relationId,
+		})
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, string(payloadJson))
+	}
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance_cluster.node_event (node_id, content, payload, target_device)
+		SELECT n.id, 'dataChanged', p.payload, $1
+		FROM instance_cluster.node AS n
+		CROSS JOIN (SELECT DISTINCT UNNEST($2::TEXT[])) AS p(payload)
+		WHERE n.date_check_in > $3
+	`, int16(types.WebsocketClientDeviceBrowser), payloads, tools.GetTimeUnix()-3600)
+	return err
+}
 func FilesCopied_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, address string, loginId int64,
 	attributeId uuid.UUID, fileIds []uuid.UUID, recordId int64) error {
 
@@ -277,6 +323,22 @@ func MasterAssigned(state bool) error {
 	SchedulerRestart <- true
 	return nil
 }
+
+// informs clients watching the relation of the given record lock change
+func RecordLockChanged(payload types.ClusterEventRecordLockChanged) {
+	WebsocketClientEvents <- types.ClusterEvent{
+		Content: "recordLockChanged",
+		Payload: payload,
+		Target:  types.ClusterEventTarget{Device: types.WebsocketClientDeviceBrowser},
+	}
+}
+
+// lock changes are distributed via events to all nodes, including this one
+// clients are only informed once the events are processed, after the changes were committed
+func RecordLockChanged_tx(ctx context.Context, tx pgx.Tx, payload types.ClusterEventRecordLockChanged) error {
+	return createEventsForAllNodes_tx(ctx, tx, "recordLockChanged", payload,
+		types.ClusterEventTarget{Device: types.WebsocketClientDeviceBrowser})
+}
 func SchemaChanged_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, moduleIds []uuid.UUID) error {
 	target := types.ClusterEventTarget{Device: types.WebsocketClientDeviceBrowser}
 
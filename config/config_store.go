@@ -17,28 +17,32 @@ var (
 	storeUint64      = make(map[string]uint64)
 	storeUint64Slice = make(map[string][]uint64)
 
-	NamesString = []string{"adminMails", "appName", "appNameShort", "backupDir",
+	NamesString = []string{"adminMails", "apiRateLimitsCustom", "appName", "appNameShort", "backupDir",
 		"companyColorHeader", "companyColorLogin", "companyLoginImage",
 		"companyLogo", "companyLogoUrl", "companyName", "companyWelcome", "css",
 		"dbVersionCut", "exportPrivateKey", "iconPwa1", "iconPwa2",
 		"instanceId", "licenseFile", "publicHostName", "proxyUrl", "repoPass",
-		"repoPublicKeys", "repoUrl", "repoUser", "systemMsgText", "tokenSecret",
-		"updateCheckUrl", "updateCheckVersion"}
+		"repoPublicKeys", "repoUrl", "repoUser", "systemMsgText",
+		"taskAlertWebhookUrl", "tokenSecret",
+		"updateCheckUrl", "updateCheckVersion", "webhookSecret"}
 
-	NamesUint64 = []string{"backupDaily", "backupMonthly", "backupWeekly",
+	NamesUint64 = []string{"apiBulkRowsMax", "apiIdempotencyHours", "apiRateKeepDays", "apiRateLimitApi",
+		"apiRateLimitLogin", "apiRateLimitNode", "backupDaily", "backupMonthly", "backupWeekly",
 		"backupCountDaily", "backupCountMonthly", "backupCountWeekly",
 		"bruteforceAttempts", "bruteforceProtection", "builderMode",
 		"clusterNodeMissingAfter", "dbTimeoutCsv", "dbTimeoutDataRest",
 		"dbTimeoutDataWs", "dbTimeoutIcs", "filesKeepDaysDeleted",
 		"fileVersionsKeepCount", "fileVersionsKeepDays", "icsDaysPost",
-		"icsDaysPre", "icsDownload", "imagerThumbWidth", "logApi", "logBackup",
+		"icsDaysPre", "icsDownload", "imagerThumbWidth", "jobsKeepDays", "logApi", "logBackup",
 		"logCache", "logCluster", "logCsv", "logFile", "logImager", "logLdap",
 		"logMail", "logModule", "logOauth", "logServer", "logScheduler",
 		"logTransfer", "logWebsocket", "logsKeepDays", "mailTrafficKeepDays",
 		"productionMode", "pwForceDigit", "pwForceLower", "pwForceSpecial",
 		"pwForceUpper", "pwLengthMin", "repoChecked", "repoFeedback",
-		"repoSkipVerify", "systemMsgDate0", "systemMsgDate1",
-		"systemMsgMaintenance", "tokenExpiryHours", "tokenKeepEnable"}
+		"repoSkipVerify", "slowQueryKeepDays", "slowQueryThresholdMs",
+		"systemMsgDate0", "systemMsgDate1",
+		"systemMsgMaintenance", "taskRunsKeepDays", "tokenExpiryHours",
+		"tokenKeepEnable"}
 
 	NamesUint64Slice = []string{"loginBackgrounds"}
 )
@@ -126,6 +130,26 @@ func GetUint64Slice(name string) []uint64 {
 	return storeUint64Slice[name]
 }
 
+// returns REST API rate limit (requests per minute) of given bucket kind (login, api, node) and entity (ID)
+// custom limits of specific logins or APIs overwrite the general limit of their kind, 0 is unlimited
+func GetApiRateLimit(kind string, entity string) uint64 {
+	var custom map[string]map[string]uint64
+	if err := json.Unmarshal([]byte(GetString("apiRateLimitsCustom")), &custom); err == nil {
+		if limit, exists := custom[kind][entity]; exists {
+			return limit
+		}
+	}
+	switch kind {
+	case "api":
+		return GetUint64("apiRateLimitApi")
+	case "login":
+		return GetUint64("apiRateLimitLogin")
+	case "node":
+		return GetUint64("apiRateLimitNode")
+	}
+	return 0
+}
+
 func LoadFromDb() error {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
 	defer ctxCanc()
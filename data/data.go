@@ -188,6 +188,14 @@ func getPolicyFilter(loginId int64, action string, tableAlias string,
 	return fmt.Sprintf("\nAND %s", strings.Join(clauses, "\nAND ")), nil
 }
 
+// get filter (e. g. WHERE clause) that excludes soft deleted records, if relation uses soft delete
+func getSoftDeleteFilter(rel types.Relation, tableAlias string) string {
+	if !rel.SoftDelete {
+		return ""
+	}
+	return fmt.Sprintf("\nAND \"%s\".\"%s\" IS NULL", tableAlias, schema.SoftDeleteName)
+}
+
 func getFunctionName(pgFunctionId uuid.UUID) (string, error) {
 	fnc, exists := cache.PgFunctionIdMap[pgFunctionId]
 	if !exists {
@@ -2,19 +2,25 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"r3/cache"
+	"r3/cluster"
 	"r3/handler"
 	"r3/schema"
+	"r3/tools"
 	"r3/types"
+	"strings"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// client ID is used to check record locks, nil UUID if request does not come from websocket client
 func Del_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
-	recordId int64, loginId int64) error {
+	recordId int64, loginId int64, clientId uuid.UUID) error {
 
 	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
 		return errors.New(handler.ErrUnauthorized)
@@ -40,6 +46,11 @@ func Del_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 		return handler.ErrSchemaUnknownModule(rel.ModuleId)
 	}
 
+	// records locked by other clients cannot be deleted
+	if err := checkLock_tx(ctx, tx, rel, recordId, clientId); err != nil {
+		return err
+	}
+
 	// get policy filter if applicable
 	tableAlias := "t"
 	policyFilter, err := getPolicyFilter(loginId, "delete", tableAlias, rel.Policies)
@@ -47,12 +58,232 @@ func Del_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 		return err
 	}
 
-	_, err = tx.Exec(ctx, fmt.Sprintf(`
+	// webhook payloads must be retrieved before the record is deleted
+	calls, err := webhookGetCalls_tx(ctx, tx, relationId, recordId, "delete", loginId)
+	if err != nil {
+		return err
+	}
+
+	// soft delete, record is moved to trash bin
+	if rel.SoftDelete {
+		deleted, err := softDel_tx(ctx, tx, mod, rel, tableAlias, policyFilter, recordId, tools.GetTimeUnix(), loginId, clientId)
+		if err != nil || !deleted {
+			return err
+		}
+		if err := dataChanged_tx(ctx, tx, clientId, []uuid.UUID{relationId}); err != nil {
+			return err
+		}
+		return webhookSpool_tx(ctx, tx, calls)
+	}
+
+	query := fmt.Sprintf(`
 		DELETE FROM "%s"."%s" AS "%s"
 		WHERE "%s"."%s" = $1
 		%s
 	`, mod.Name, rel.Name, tableAlias, tableAlias,
-		schema.PkName, policyFilter), recordId)
+		schema.PkName, policyFilter)
+
+	if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		tag, err := tx.Exec(ctx, query, recordId)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		if err := dataChanged_tx(ctx, tx, clientId, []uuid.UUID{relationId}); err != nil {
+			return err
+		}
+		return webhookSpool_tx(ctx, tx, calls)
+	}
+
+	// log deletion with snapshot of last record values
+	var rowJson []byte
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`%s RETURNING ROW_TO_JSON("%s".*)`,
+		query, tableAlias), recordId).Scan(&rowJson); err != nil {
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if err := setLogDelete_tx(ctx, tx, rel, recordId, rowJson, loginId); err != nil {
+		return fmt.Errorf("failed to set data log, %v", err)
+	}
+	if err := dataChanged_tx(ctx, tx, clientId, []uuid.UUID{relationId}); err != nil {
+		return err
+	}
+	return webhookSpool_tx(ctx, tx, calls)
+}
 
-	return err
+// informs clients about changed relations, once the transaction is committed
+// all writes are published, the websocket client that made the change (if any) is not informed about it
+func dataChanged_tx(ctx context.Context, tx pgx.Tx, clientId uuid.UUID, relationIds []uuid.UUID) error {
+	return cluster.DataChanged_tx(ctx, tx, clientId, relationIds)
+}
+
+// re-creates deleted record with its original ID, from snapshot of the last data change log of its deletion
+// fails if constraints do not allow it, like references to records that do not exist anymore
+// file attributes cannot be restored as they are not part of the snapshot
+func Undelete_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, loginId int64) error {
+
+	// check for authorized access, WRITE(2) for record creation
+	if !authorizedRelation(loginId, relationId, types.AccessWrite) {
+		return errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	// data keys of encrypted records are removed on deletion, values could not be decrypted anymore
+	if rel.Encryption {
+		return fmt.Errorf("records of relation '%s' are encrypted and cannot be restored", rel.Name)
+	}
+
+	var recordExists bool
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM "%s"."%s"
+			WHERE "%s" = $1
+		)
+	`, mod.Name, rel.Name, schema.PkName), recordId).Scan(&recordExists); err != nil {
+		return err
+	}
+	if recordExists {
+		return fmt.Errorf("record %d already exists", recordId)
+	}
+
+	// get snapshot from last deletion log
+	var logId uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM instance.data_log
+		WHERE relation_id    = $1
+		AND   record_id_wofk = $2
+		AND   action         = 'delete'
+		ORDER BY date_change DESC
+		LIMIT 1
+	`, relationId, recordId).Scan(&logId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no deletion log exists for record %d", recordId)
+		}
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT attribute_id, value
+		FROM instance.data_log_value
+		WHERE data_log_id = $1
+		AND outside_in = FALSE
+	`, logId)
+	if err != nil {
+		return err
+	}
+
+	attributes := make([]types.DataSetAttribute, 0)
+	row := map[string]json.RawMessage{
+		schema.PkName: json.RawMessage(fmt.Sprintf("%d", recordId)),
+	}
+	for rows.Next() {
+		var atrId uuid.UUID
+		var value pgtype.Text
+		if err := rows.Scan(&atrId, &value); err != nil {
+			rows.Close()
+			return err
+		}
+
+		// attributes might have been removed since the record was deleted
+		atr, exists := cache.AttributeIdMap[atrId]
+		// generated attributes are recomputed by the database
+		if !exists || atr.RelationId != relationId || atr.Id == rel.AttributeIdPk || atr.Generated.Valid {
+			continue
+		}
+
+		// restoring a value is the same as setting it, WRITE(2) for attribute
+		if !authorizedAttribute(loginId, atrId, types.AccessWrite) {
+			rows.Close()
+			return errors.New(handler.ErrUnauthorized)
+		}
+
+		v := json.RawMessage("null")
+		if value.Valid {
+			v = json.RawMessage(value.String)
+		}
+		row[atr.Name] = v
+		attributes = append(attributes, types.DataSetAttribute{AttributeId: atrId, Value: v})
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rowJson, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	// only restored columns are inserted, generated columns cannot be written to
+	names := make([]string, 0)
+	for name := range row {
+		names = append(names, fmt.Sprintf(`"%s"`, name))
+	}
+	namesList := strings.Join(names, ", ")
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO "%s"."%s" (%s)
+		SELECT %s FROM JSONB_POPULATE_RECORD(NULL::"%s"."%s", $1)
+	`, mod.Name, rel.Name, namesList, namesList, mod.Name, rel.Name), rowJson); err != nil {
+		return err
+	}
+
+	// policies filter by record, restored record must be writable by login under its update policy
+	// checked after insert as policy functions can depend on record values, caller rolls back on error
+	tableAlias := "t"
+	policyFilter, err := getPolicyFilter(loginId, "update", tableAlias, rel.Policies)
+	if err != nil {
+		return err
+	}
+	if policyFilter != "" {
+		var allowed bool
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT EXISTS(
+				SELECT 1
+				FROM "%s"."%s" AS "%s"
+				WHERE "%s"."%s" = $1
+				%s
+			)
+		`, mod.Name, rel.Name, tableAlias, tableAlias, schema.PkName, policyFilter),
+			recordId).Scan(&allowed); err != nil {
+
+			return err
+		}
+		if !allowed {
+			return errors.New(handler.ErrUnauthorized)
+		}
+	}
+
+	// log restored record, so its state can be reconstructed from the change log
+	if err := setLogForRecords_tx(ctx, tx, rel, []int64{recordId},
+		"undelete", attributes, loginId); err != nil {
+
+		return fmt.Errorf("failed to set data log, %v", err)
+	}
+
+	calls, err := webhookGetCalls_tx(ctx, tx, relationId, recordId, "insert", loginId)
+	if err != nil {
+		return err
+	}
+	if err := dataChanged_tx(ctx, tx, uuid.Nil, []uuid.UUID{relationId}); err != nil {
+		return err
+	}
+	return webhookSpool_tx(ctx, tx, calls)
 }
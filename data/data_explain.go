@@ -0,0 +1,224 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/types"
+	"regexp"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sequential scans on relations with at least this many records are reported
+const explainSeqScanRecordsMin int64 = 10000
+
+// attributes are referred to via relation alias in plan filters: t0.name or "t0"."name"
+var explainFilterAttributeRx = regexp.MustCompile(`\."?([A-Za-z0-9_]+)"?`)
+
+// query plan node as returned by EXPLAIN (FORMAT JSON)
+type explainPlanNode struct {
+	NodeType     string            `json:"Node Type"`
+	Schema       string            `json:"Schema"`
+	Relation     string            `json:"Relation Name"`
+	Alias        string            `json:"Alias"`
+	Index        string            `json:"Index Name"`
+	CondHash     string            `json:"Hash Cond"`
+	CondIndex    string            `json:"Index Cond"`
+	CondMerge    string            `json:"Merge Cond"`
+	CondRecheck  string            `json:"Recheck Cond"`
+	FilterJoin   string            `json:"Join Filter"`
+	Filter       string            `json:"Filter"`
+	CostTotal    float64           `json:"Total Cost"`
+	RowsPlanned  float64           `json:"Plan Rows"`
+	RowsActual   float64           `json:"Actual Rows"`
+	RowsFiltered float64           `json:"Rows Removed by Filter"`
+	Loops        int64             `json:"Actual Loops"`
+	TimeTotal    float64           `json:"Actual Total Time"`
+	BlocksHit    int64             `json:"Shared Hit Blocks"`
+	BlocksRead   int64             `json:"Shared Read Blocks"`
+	Plans        []explainPlanNode `json:"Plans"`
+}
+
+// executes data GET request with EXPLAIN (ANALYZE, BUFFERS), returns query plan
+// query is executed within a savepoint that is rolled back, to discard any side effects (from functions in filters)
+// sequential scans on large relations are reported together with filtered attributes that have no index
+func Explain_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64) (types.DataExplain, error) {
+
+	var err error
+	var res types.DataExplain
+	res.Warnings = make([]types.DataExplainWarning, 0)
+
+	indexRelationIds := make(map[int]uuid.UUID)
+	queryArgs := make([]interface{}, 0)
+
+	cache.Schema_mx.RLock()
+	res.Query, err = prepareQuery(data, indexRelationIds, &queryArgs, loginId, data.Limit != 0, 0)
+	cache.Schema_mx.RUnlock()
+
+	if err != nil {
+		return res, err
+	}
+
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer sp.Rollback(ctx)
+
+	var planJson []byte
+	if err := sp.QueryRow(ctx, fmt.Sprintf("EXPLAIN (ANALYZE, BUFFERS, VERBOSE, FORMAT JSON) %s",
+		res.Query), queryArgs...).Scan(&planJson); err != nil {
+
+		return res, fmt.Errorf("%s, SQL: %s", err, res.Query)
+	}
+	if err := sp.Rollback(ctx); err != nil {
+		return res, err
+	}
+
+	var plans []struct {
+		Plan          explainPlanNode `json:"Plan"`
+		PlanningTime  float64         `json:"Planning Time"`
+		ExecutionTime float64         `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(planJson, &plans); err != nil {
+		return res, err
+	}
+	if len(plans) != 1 {
+		return res, errors.New("query plan is invalid")
+	}
+	res.Plan = convertExplainNode(plans[0].Plan)
+	res.PlanningTime = plans[0].PlanningTime
+	res.ExecutionTime = plans[0].ExecutionTime
+
+	// report sequential scans on large relations
+	var seqScans []types.DataExplainNode
+	var collectSeqScans func(node types.DataExplainNode)
+	collectSeqScans = func(node types.DataExplainNode) {
+		if node.NodeType == "Seq Scan" {
+			seqScans = append(seqScans, node)
+		}
+		for _, child := range node.Plans {
+			collectSeqScans(child)
+		}
+	}
+	collectSeqScans(res.Plan)
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	for _, node := range seqScans {
+		rel, exists := getExplainRelation(node.Schema, node.Relation)
+		if !exists {
+			continue
+		}
+
+		// estimated record count from statistics, -1 if relation was never analyzed
+		var recordsEstimate int64
+		if err := tx.QueryRow(ctx, `
+			SELECT c.reltuples::BIGINT
+			FROM pg_catalog.pg_class AS c
+			JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			AND   c.relname = $2
+		`, node.Schema, node.Relation).Scan(&recordsEstimate); err != nil {
+			return res, err
+		}
+
+		// use actually scanned rows if statistics are not available
+		if recordsEstimate < 0 {
+			recordsEstimate = int64(node.RowsActual + node.RowsFiltered)
+		}
+		if recordsEstimate < explainSeqScanRecordsMin {
+			continue
+		}
+
+		res.Warnings = append(res.Warnings, types.DataExplainWarning{
+			RelationId:   rel.Id,
+			RowsEstimate: recordsEstimate,
+			Filter:       node.Filter,
+			AttributeIds: getExplainAttributeIdsNoIndex(rel, node.Filter),
+		})
+	}
+	return res, nil
+}
+
+func convertExplainNode(node explainPlanNode) types.DataExplainNode {
+	out := types.DataExplainNode{
+		NodeType:     node.NodeType,
+		Schema:       node.Schema,
+		Relation:     node.Relation,
+		Alias:        node.Alias,
+		Index:        node.Index,
+		Filter:       node.Filter,
+		CostTotal:    node.CostTotal,
+		RowsPlanned:  node.RowsPlanned,
+		RowsActual:   node.RowsActual,
+		RowsFiltered: node.RowsFiltered,
+		Loops:        node.Loops,
+		TimeTotal:    node.TimeTotal,
+		BlocksHit:    node.BlocksHit,
+		BlocksRead:   node.BlocksRead,
+		Plans:        make([]types.DataExplainNode, 0),
+	}
+
+	// nodes only have one of these conditions
+	for _, cond := range []string{node.CondHash, node.CondIndex, node.CondMerge, node.CondRecheck, node.FilterJoin} {
+		if cond != "" {
+			out.Condition = cond
+			break
+		}
+	}
+	for _, child := range node.Plans {
+		out.Plans = append(out.Plans, convertExplainNode(child))
+	}
+	return out
+}
+
+// returns app relation by DB schema (module name) and relation name
+func getExplainRelation(schemaName string, relationName string) (types.Relation, bool) {
+	for _, rel := range cache.RelationIdMap {
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if exists && mod.Name == schemaName && rel.Name == relationName {
+			return rel, true
+		}
+	}
+	return types.Relation{}, false
+}
+
+// returns attributes referenced in filter that are not the leading attribute of any relation index
+func getExplainAttributeIdsNoIndex(rel types.Relation, filter string) []uuid.UUID {
+	attributeIds := make([]uuid.UUID, 0)
+	if filter == "" {
+		return attributeIds
+	}
+
+	// attributes that are leading index attributes can be used for index scans
+	attributeIdsIndexed := make([]uuid.UUID, 0)
+	for _, ind := range rel.Indexes {
+		for _, indAtr := range ind.Attributes {
+			if indAtr.Position == 0 {
+				attributeIdsIndexed = append(attributeIdsIndexed, indAtr.AttributeId)
+			}
+		}
+	}
+
+	attributeNamesFilter := make([]string, 0)
+	for _, match := range explainFilterAttributeRx.FindAllStringSubmatch(filter, -1) {
+		attributeNamesFilter = append(attributeNamesFilter, match[1])
+	}
+
+	for _, atr := range rel.Attributes {
+		if atr.Id == rel.AttributeIdPk || slices.Contains(attributeIdsIndexed, atr.Id) {
+			continue
+		}
+		if slices.Contains(attributeNamesFilter, atr.Name) {
+			attributeIds = append(attributeIds, atr.Id)
+		}
+	}
+	return attributeIds
+}
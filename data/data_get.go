@@ -17,16 +17,19 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const (
 	exprRegconfigSimple   = "'simple'::REGCONFIG"
+	sqlAliasGrouping      = "_grp"
 	sqlAliasTotalRowCount = "_cnt"
 )
 
 var (
-	regexRelId = regexp.MustCompile(`^\_r(\d+)id`) // finds: _r3id
+	regexRelId      = regexp.MustCompile(`^\_r(\d+)id`)  // finds: _r3id
+	regexRelVersion = regexp.MustCompile(`^\_r(\d+)ver`) // finds: _r3ver
 )
 
 // get data
@@ -36,95 +39,114 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 	cache.Schema_mx.RLock()
 	defer cache.Schema_mx.RUnlock()
 
-	var err error
-	indexRelationIds := make(map[int]uuid.UUID) // map of accessed relation IDs, key: relation index
-	isDoingRowCount := data.Limit != 0
-	relationIndexesEnc := make([]int, 0) // indexes of relations from encrypted attributes within expressions
-	queryArgs := make([]interface{}, 0)  // SQL arguments for data query
-
-	// prepare SQL query for data GET request
-	*query, err = prepareQuery(data, indexRelationIds, &queryArgs, loginId, isDoingRowCount, 0)
+	relationIndexesEnc, err := getRelationIndexesEnc(data)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// execute SQL query
-	rows, err := tx.Query(ctx, *query, queryArgs...)
+	results := make([]types.DataGetResult, 0)
+	resultCountTotal, indexRelationIds, err := getRows_tx(ctx, tx, data, loginId, query,
+		func(result types.DataGetResult) error {
+			results = append(results, result)
+			return nil
+		})
+
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rowColumns := rows.FieldDescriptions()
-	results := make([]types.DataGetResult, 0)
-	var resultCountTotal int64
+	// get data keys for encrypted relation records
+	for _, relIndex := range relationIndexesEnc {
+		recordIds := make([]int64, 0)
 
-	for rows.Next() {
-		valuesAll, err := rows.Values()
-		if err != nil {
-			return nil, 0, err
-		}
+		// collect all non-null record IDs for given relation index
+		for _, result := range results {
+			if result.IndexRecordIds[relIndex] != nil {
 
-		if isDoingRowCount && len(results) == 0 && len(valuesAll) > 0 && rowColumns[len(rowColumns)-1].Name == sqlAliasTotalRowCount {
-			// get total count from last row value
-			var valid bool
-			resultCountTotal, valid = valuesAll[len(valuesAll)-1].(int64)
-			if !valid {
-				return nil, 0, fmt.Errorf("row count is invalid data type")
+				switch v := result.IndexRecordIds[relIndex].(type) {
+				case int32:
+					recordIds = append(recordIds, int64(v))
+				case int64:
+					recordIds = append(recordIds, v)
+				default:
+					return nil, 0, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecDataKeysNotAvailable)
+				}
 			}
 		}
 
-		indexRecordIds := make(map[int]interface{}) // ID for each relation tuple by index
-		indexRecordEncKeys := make(map[int]string)  // encrypted key for each relation tuple by index
-		values := make([]interface{}, 0)            // final values for selected attributes
+		encKeys, err := data_enc.GetKeys_tx(ctx, tx,
+			indexRelationIds[relIndex], recordIds, loginId)
 
-		// collect values for expressions
-		for i := 0; i < len(data.Expressions); i++ {
-			values = append(values, valuesAll[i])
+		if err != nil {
+			return nil, 0, err
 		}
 
-		// collect relation tuple IDs
-		// relation ID columns start after expressions
-		for i, j := len(data.Expressions), len(rowColumns); i < j; i++ {
+		if len(encKeys) != len(recordIds) {
+			return nil, 0, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecDataKeysNotAvailable)
+		}
 
-			matches := regexRelId.FindStringSubmatch(string(rowColumns[i].Name))
-			if len(matches) == 2 {
-				relIndex, err := strconv.Atoi(matches[1])
-				if err != nil {
-					return nil, 0, err
-				}
-				indexRecordIds[relIndex] = valuesAll[i]
+		// assign record keys in order
+		keyIndex := 0
+		for i, result := range results {
+			if result.IndexRecordIds[relIndex] != nil {
+				results[i].IndexRecordEncKeys[relIndex] = encKeys[keyIndex]
+				keyIndex++
 			}
 		}
+	}
+	return results, resultCountTotal, nil
+}
 
-		results = append(results, types.DataGetResult{
-			IndexRecordIds:     indexRecordIds,
-			IndexRecordEncKeys: indexRecordEncKeys,
-			IndexesPermNoDel:   make([]int, 0),
-			IndexesPermNoSet:   make([]int, 0),
-			Values:             values,
-		})
+// get data as stream, each row is passed to given function as soon as it is read from the database
+// rows are not collected, memory use does not depend on result size
+// the transaction cannot be used by the given function as it is busy until all rows are read
+// data keys for encrypted attributes require all record IDs, encrypted attributes are therefore not supported
+func GetIter_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, query *string,
+	fn func(types.DataGetResult) error) (int64, error) {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	relationIndexesEnc, err := getRelationIndexesEnc(data)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
+	if len(relationIndexesEnc) != 0 {
+		return 0, errors.New("data GET stream does not support encrypted attributes")
 	}
-	rows.Close()
 
-	if !isDoingRowCount {
-		resultCountTotal = int64(len(results))
+	resultCountTotal, _, err := getRows_tx(ctx, tx, data, loginId, query, fn)
+	return resultCountTotal, err
+}
+
+// executes data GET request and passes each result row to given function
+// returns total count and accessed relation IDs by index
+func getRows_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, query *string,
+	fn func(types.DataGetResult) error) (int64, map[int]uuid.UUID, error) {
+
+	var err error
+	indexRelationIds := make(map[int]uuid.UUID) // map of accessed relation IDs, key: relation index
+	isDoingRowCount := data.Limit != 0
+	queryArgs := make([]interface{}, 0) // SQL arguments for data query
+
+	// prepare SQL query for data GET request
+	*query, err = prepareQuery(data, indexRelationIds, &queryArgs, loginId, isDoingRowCount, 0)
+	if err != nil {
+		return 0, indexRelationIds, err
 	}
 
-	// resolve relation policy access permissions for retrieved result records
+	// resolve relation policy access permissions for result records
 	// DEL/SET actions only; records not allowed to GET are not retrieved as results
 	// purely to inform requestor as DEL/SET are checked again when executed
-	if data.GetPerm && len(results) != 0 {
-
-		indexMapDelBlacklist := make(map[int][]int64)  // record IDs not do delete
-		indexMapDelWhitelist := make(map[int][]int64)  // record IDs to delete
-		indexMapDelWhitelistUsed := make(map[int]bool) // whether whitelist was used
-		indexMapSetBlacklist := make(map[int][]int64)  // record IDs not do update
-		indexMapSetWhitelist := make(map[int][]int64)  // record IDs to update
-		indexMapSetWhitelistUsed := make(map[int]bool) // whether whitelist was used
-
+	// policy lists are retrieved before the data query, as the transaction is busy while rows are read
+	indexMapDelBlacklist := make(map[int][]int64)  // record IDs not do delete
+	indexMapDelWhitelist := make(map[int][]int64)  // record IDs to delete
+	indexMapDelWhitelistUsed := make(map[int]bool) // whether whitelist was used
+	indexMapSetBlacklist := make(map[int][]int64)  // record IDs not do update
+	indexMapSetWhitelist := make(map[int][]int64)  // record IDs to update
+	indexMapSetWhitelistUsed := make(map[int]bool) // whether whitelist was used
+
+	if data.GetPerm {
 		var getPolicyLists = func(relationId uuid.UUID, index int) error {
 
 			indexMapDelBlacklist[index],
@@ -149,14 +171,14 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 
 		// get record ID black-/whitelists for all relations (base & joined)
 		if err := getPolicyLists(data.RelationId, 0); err != nil {
-			return nil, 0, err
+			return 0, indexRelationIds, err
 		}
 
 		for _, j := range data.Joins {
 
 			atr, exists := cache.AttributeIdMap[j.AttributeId]
 			if !exists {
-				return nil, 0, handler.ErrSchemaUnknownAttribute(j.AttributeId)
+				return 0, indexRelationIds, handler.ErrSchemaUnknownAttribute(j.AttributeId)
 			}
 
 			// join attribute is from other relation, use relationship partner
@@ -166,109 +188,176 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 			}
 
 			if err := getPolicyLists(relId, j.Index); err != nil {
-				return nil, 0, err
+				return 0, indexRelationIds, err
 			}
 		}
+	}
 
-		for i, res := range results {
-			for index, recordIdIf := range res.IndexRecordIds {
-				if recordIdIf == nil {
-					continue
-				}
+	var applyPerms = func(result *types.DataGetResult) error {
+		for index, recordIdIf := range result.IndexRecordIds {
+			if recordIdIf == nil {
+				continue
+			}
 
-				var recordId int64
-				switch v := recordIdIf.(type) {
-				case int32:
-					recordId = int64(v)
-				case int64:
-					recordId = v
-				default:
-					return nil, 0, fmt.Errorf("record ID has invalid type")
-				}
+			var recordId int64
+			switch v := recordIdIf.(type) {
+			case int32:
+				recordId = int64(v)
+			case int64:
+				recordId = v
+			default:
+				return fmt.Errorf("record ID has invalid type")
+			}
 
-				if recordId == 0 {
-					continue
-				}
+			if recordId == 0 {
+				continue
+			}
 
-				// deny DEL if record ID is in blacklist or not in whitelist (if used)
-				if slices.Contains(indexMapDelBlacklist[index], recordId) ||
-					(indexMapDelWhitelistUsed[index] && !slices.Contains(indexMapDelWhitelist[index], recordId)) {
+			// deny DEL if record ID is in blacklist or not in whitelist (if used)
+			if slices.Contains(indexMapDelBlacklist[index], recordId) ||
+				(indexMapDelWhitelistUsed[index] && !slices.Contains(indexMapDelWhitelist[index], recordId)) {
 
-					results[i].IndexesPermNoDel = append(results[i].IndexesPermNoDel, index)
-				}
+				result.IndexesPermNoDel = append(result.IndexesPermNoDel, index)
+			}
 
-				// deny SET if record ID is in blacklist or not in whitelist (if used)
-				if slices.Contains(indexMapSetBlacklist[index], recordId) ||
-					(indexMapSetWhitelistUsed[index] && !slices.Contains(indexMapSetWhitelist[index], recordId)) {
+			// deny SET if record ID is in blacklist or not in whitelist (if used)
+			if slices.Contains(indexMapSetBlacklist[index], recordId) ||
+				(indexMapSetWhitelistUsed[index] && !slices.Contains(indexMapSetWhitelist[index], recordId)) {
 
-					results[i].IndexesPermNoSet = append(results[i].IndexesPermNoSet, index)
-				}
+				result.IndexesPermNoSet = append(result.IndexesPermNoSet, index)
 			}
 		}
+		return nil
 	}
 
-	// check for encrypted attributes in expressions
-	for _, expr := range data.Expressions {
+	// execute SQL query
+	rows, err := tx.Query(ctx, *query, queryArgs...)
+	if err != nil {
+		return 0, indexRelationIds, err
+	}
+	defer rows.Close()
 
-		// ignore non-attribute and sub query expressions
-		if !expr.AttributeId.Valid {
-			continue
-		}
-		if expr.Query.RelationId != uuid.Nil {
-			continue
+	rowColumns := rows.FieldDescriptions()
+	var resultCount int64
+	var resultCountTotal int64
+
+	// position of grouping column, if grouping is used
+	groupingPos := slices.IndexFunc(rowColumns, func(c pgconn.FieldDescription) bool {
+		return c.Name == sqlAliasGrouping
+	})
+
+	for rows.Next() {
+		valuesAll, err := rows.Values()
+		if err != nil {
+			return 0, indexRelationIds, err
 		}
 
-		atr, exists := cache.AttributeIdMap[expr.AttributeId.Bytes]
-		if !exists {
-			return nil, 0, handler.ErrSchemaUnknownAttribute(expr.AttributeId.Bytes)
+		if isDoingRowCount && resultCount == 0 && len(valuesAll) > 0 && rowColumns[len(rowColumns)-1].Name == sqlAliasTotalRowCount {
+			// get total count from last row value
+			var valid bool
+			resultCountTotal, valid = valuesAll[len(valuesAll)-1].(int64)
+			if !valid {
+				return 0, indexRelationIds, fmt.Errorf("row count is invalid data type")
+			}
 		}
 
-		if !atr.Encrypted || slices.Contains(relationIndexesEnc, expr.Index) {
-			continue
+		indexRecordIds := make(map[int]interface{})      // ID for each relation tuple by index
+		indexRecordEncKeys := make(map[int]string)       // encrypted key for each relation tuple by index
+		indexRecordVersions := make(map[int]interface{}) // version for each relation tuple by index
+		values := make([]interface{}, 0)                 // final values for selected attributes
+
+		// collect values for expressions
+		for i := 0; i < len(data.Expressions); i++ {
+			values = append(values, valuesAll[i])
 		}
-		relationIndexesEnc = append(relationIndexesEnc, expr.Index)
-	}
 
-	// get data keys for encrypted relation records
-	for _, relIndex := range relationIndexesEnc {
-		recordIds := make([]int64, 0)
+		// collect relation tuple IDs
+		// relation ID columns start after expressions
+		for i, j := len(data.Expressions), len(rowColumns); i < j; i++ {
 
-		// collect all non-null record IDs for given relation index
-		for _, result := range results {
-			if result.IndexRecordIds[relIndex] != nil {
+			matches := regexRelId.FindStringSubmatch(string(rowColumns[i].Name))
+			if len(matches) == 2 {
+				relIndex, err := strconv.Atoi(matches[1])
+				if err != nil {
+					return 0, indexRelationIds, err
+				}
+				indexRecordIds[relIndex] = valuesAll[i]
+				continue
+			}
 
-				switch v := result.IndexRecordIds[relIndex].(type) {
-				case int32:
-					recordIds = append(recordIds, int64(v))
-				case int64:
-					recordIds = append(recordIds, v)
-				default:
-					return nil, 0, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecDataKeysNotAvailable)
+			matches = regexRelVersion.FindStringSubmatch(string(rowColumns[i].Name))
+			if len(matches) == 2 {
+				relIndex, err := strconv.Atoi(matches[1])
+				if err != nil {
+					return 0, indexRelationIds, err
 				}
+				indexRecordVersions[relIndex] = valuesAll[i]
 			}
 		}
 
-		encKeys, err := data_enc.GetKeys_tx(ctx, tx,
-			indexRelationIds[relIndex], recordIds, loginId)
+		var grouping int32
+		if groupingPos != -1 {
+			var valid bool
+			grouping, valid = valuesAll[groupingPos].(int32)
+			if !valid {
+				return 0, indexRelationIds, fmt.Errorf("grouping is invalid data type")
+			}
+		}
 
-		if err != nil {
-			return nil, 0, err
+		result := types.DataGetResult{
+			IndexRecordIds:      indexRecordIds,
+			IndexRecordEncKeys:  indexRecordEncKeys,
+			IndexRecordVersions: indexRecordVersions,
+			IndexesPermNoDel:    make([]int, 0),
+			IndexesPermNoSet:    make([]int, 0),
+			Values:              values,
+			Grouping:            grouping,
+		}
+		if data.GetPerm {
+			if err := applyPerms(&result); err != nil {
+				return 0, indexRelationIds, err
+			}
+		}
+		if err := fn(result); err != nil {
+			return 0, indexRelationIds, err
 		}
+		resultCount++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, indexRelationIds, err
+	}
 
-		if len(encKeys) != len(recordIds) {
-			return nil, 0, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecDataKeysNotAvailable)
+	if !isDoingRowCount {
+		resultCountTotal = resultCount
+	}
+	return resultCountTotal, indexRelationIds, nil
+}
+
+// returns relation indexes of encrypted attributes within expressions
+func getRelationIndexesEnc(data types.DataGet) ([]int, error) {
+	relationIndexesEnc := make([]int, 0)
+
+	for _, expr := range data.Expressions {
+
+		// ignore non-attribute and sub query expressions
+		if !expr.AttributeId.Valid {
+			continue
+		}
+		if expr.Query.RelationId != uuid.Nil {
+			continue
 		}
 
-		// assign record keys in order
-		keyIndex := 0
-		for i, result := range results {
-			if result.IndexRecordIds[relIndex] != nil {
-				results[i].IndexRecordEncKeys[relIndex] = encKeys[keyIndex]
-				keyIndex++
-			}
+		atr, exists := cache.AttributeIdMap[expr.AttributeId.Bytes]
+		if !exists {
+			return relationIndexesEnc, handler.ErrSchemaUnknownAttribute(expr.AttributeId.Bytes)
 		}
+
+		if !atr.Encrypted || slices.Contains(relationIndexesEnc, expr.Index) {
+			continue
+		}
+		relationIndexesEnc = append(relationIndexesEnc, expr.Index)
 	}
-	return results, resultCountTotal, nil
+	return relationIndexesEnc, nil
 }
 
 // returns SQL query from data GET request (sub query if nesting level != 0)
@@ -335,9 +424,21 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		inWhere = append(inWhere, policyFilter)
 	}
 
+	// exclude soft deleted records of base relation
+	if softDeleteFilter := getSoftDeleteFilter(rel, getRelationCode(data.IndexSource, nestingLevel)); softDeleteFilter != "" {
+		inWhere = append(inWhere, softDeleteFilter)
+	}
+
 	// add filters to query, replacing first AND with WHERE
 	queryWhere := strings.Replace(strings.Join(inWhere, ""), "AND", "WHERE", 1)
 
+	// get codes of attribute expressions
+	// grouping sets & window functions cannot refer to expression aliases
+	exprCodes, err := getQueryExpressionCodes(data, nestingLevel)
+	if err != nil {
+		return "", err
+	}
+
 	// add expressions
 	mapIndex_agg := make(map[int]bool)        // map of indexes with aggregation
 	mapIndex_aggRecords := make(map[int]bool) // map of indexes with record aggregation
@@ -367,7 +468,12 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		}
 
 		// attribute expression
-		line, err := getQuerySelect(pos, expr, nestingLevel)
+		var line string
+		if expr.Window.Valid {
+			line, err = getQuerySelectWindow(data, pos, exprCodes, nestingLevel)
+		} else {
+			line, err = getQuerySelect(pos, expr, nestingLevel)
+		}
 		if err != nil {
 			return "", err
 		}
@@ -396,9 +502,32 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 				getRelationCode(index, nestingLevel),
 				schema.PkName,
 				getTupleIdCode(index, nestingLevel)))
+
+			// record versions are only available for non-aggregated records
+			// transaction ID of last change is used as version, it changes with each update
+			if data.GetVersions && len(mapIndex_agg) == 0 {
+				inSelect = append(inSelect, fmt.Sprintf(`"%s"."xmin"::TEXT::BIGINT AS %s`,
+					getRelationCode(index, nestingLevel),
+					getTupleVersionCode(index, nestingLevel)))
+			}
 		}
 	}
 
+	// build grouping line for grouping sets (ROLLUP, CUBE, GROUPING SETS)
+	queryGrouping, groupingCodes, err := getQueryGrouping(data, exprCodes)
+	if err != nil {
+		return "", err
+	}
+
+	// add expression for grouping bitmask, shows which GROUP BY expressions are aggregated over
+	// GROUPING() sets bits from right to left, expressions are reversed so that bit 0 is the first GROUP BY expression
+	if !isSubQuery && len(groupingCodes) != 0 {
+		groupingCodesReversed := slices.Clone(groupingCodes)
+		slices.Reverse(groupingCodesReversed)
+		inSelect = append(inSelect, fmt.Sprintf("GROUPING(%s) AS %s",
+			strings.Join(groupingCodesReversed, ", "), sqlAliasGrouping))
+	}
+
 	// add expression for total row count
 	if addRowCount {
 		inSelect = append(inSelect, fmt.Sprintf("COUNT(*) OVER() AS %s", sqlAliasTotalRowCount))
@@ -422,11 +551,14 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 			}
 		}
 
-		// group by requested attribute
-		if expr.GroupBy {
+		// group by requested attribute, grouping sets use their own line
+		if expr.GroupBy && queryGrouping == "" {
 			groupByItems = append(groupByItems, data_sql.GetExpressionAlias(i))
 		}
 	}
+	if queryGrouping != "" {
+		groupByItems = append(groupByItems, queryGrouping)
+	}
 	if len(groupByItems) != 0 {
 		queryGroup = fmt.Sprintf("\nGROUP BY %s", strings.Join(groupByItems, ", "))
 	}
@@ -507,7 +639,11 @@ func getQuerySelect(exprPos int, expr types.DataGetExpression, nestingLevel int)
 
 	if !expr.OutsideIn {
 		// attribute is from index relation
-		return data_sql.GetExpression(expr, getAttributeCode(relCode, atr.Name), alias), nil
+		code, err := getQueryAttributeCode(expr, atr, nestingLevel)
+		if err != nil {
+			return "", err
+		}
+		return data_sql.GetExpression(expr, code, alias), nil
 	}
 
 	// attribute comes via relationship from other relation (or self reference from same relation)
@@ -558,6 +694,178 @@ func getQuerySelect(exprPos int, expr types.DataGetExpression, nestingLevel int)
 		alias), nil
 }
 
+// returns code of attribute from index relation, truncated to date bucket if requested
+func getQueryAttributeCode(expr types.DataGetExpression, atr types.Attribute, nestingLevel int) (string, error) {
+	code := getAttributeCode(getRelationCode(expr.Index, nestingLevel), atr.Name)
+
+	if !expr.DateBucket.Valid {
+		return code, nil
+	}
+	if atr.Content != "integer" && atr.Content != "bigint" {
+		return "", fmt.Errorf("date bucket cannot be used for attribute '%s', date/datetime attribute required", atr.Name)
+	}
+	return data_sql.GetDateBucket(expr.DateBucket.String, code)
+}
+
+// returns codes of attribute expressions (incl. aggregation), key: expression position
+// only regular attributes from index relations are included (no sub queries, relationships from other relations or files)
+func getQueryExpressionCodes(data types.DataGet, nestingLevel int) (map[int]string, error) {
+	codes := make(map[int]string)
+
+	for pos, expr := range data.Expressions {
+		if expr.ReturnNull || !expr.AttributeId.Valid || expr.OutsideIn {
+			continue
+		}
+
+		atr, exists := cache.AttributeIdMap[expr.AttributeId.Bytes]
+		if !exists {
+			return codes, handler.ErrSchemaUnknownAttribute(expr.AttributeId.Bytes)
+		}
+		if schema.IsContentFiles(atr.Content) {
+			continue
+		}
+
+		code, err := getQueryAttributeCode(expr, atr, nestingLevel)
+		if err != nil {
+			return codes, err
+		}
+		codes[pos] = data_sql.GetExpressionCode(expr, code)
+	}
+	return codes, nil
+}
+
+// add SELECT for window function over attribute expression
+// window function is applied after aggregation (running sum of sums, rank of counts, ...)
+func getQuerySelectWindow(data types.DataGet, exprPos int, exprCodes map[int]string, nestingLevel int) (string, error) {
+
+	expr := data.Expressions[exprPos]
+	code, exists := exprCodes[exprPos]
+	if !exists {
+		return "", fmt.Errorf("window function of expression %d requires an attribute from its own relation", exprPos)
+	}
+	if expr.GroupBy {
+		return "", fmt.Errorf("window function of expression %d cannot be used with GROUP BY", exprPos)
+	}
+
+	partitionItems := make([]string, 0)
+	for _, pos := range expr.WindowPartition {
+		partitionCode, exists := exprCodes[pos]
+		if !exists || data.Expressions[pos].Window.Valid {
+			return "", fmt.Errorf("invalid window partition expression %d", pos)
+		}
+		partitionItems = append(partitionItems, partitionCode)
+	}
+
+	orderItems := make([]string, 0)
+	orders := expr.WindowOrders
+	if len(orders) == 0 && !slices.Contains(data_sql.WindowsRanking, expr.Window.String) {
+		orders = data.Orders
+	}
+	if len(orders) == 0 && slices.Contains(data_sql.WindowsRanking, expr.Window.String) {
+		// ranking by expression value, highest value first
+		orderItems = append(orderItems, fmt.Sprintf("%s DESC NULLS LAST", code))
+	}
+
+	for _, ord := range orders {
+		var orderCode string
+
+		if ord.ExpressionPos.Valid {
+			pos := int(ord.ExpressionPos.Int32)
+			if data.Expressions[pos].Window.Valid {
+				return "", fmt.Errorf("window cannot be ordered by window function of expression %d", pos)
+			}
+			if orderCode, exists = exprCodes[pos]; !exists {
+				return "", fmt.Errorf("window cannot be ordered by expression %d", pos)
+			}
+		} else if ord.AttributeId.Valid {
+
+			// use expression code if attribute is used as grouped/aggregated expression
+			for pos, e := range data.Expressions {
+				if e.AttributeId.Bytes == ord.AttributeId.Bytes && e.Index == int(ord.Index.Int32) &&
+					(e.Aggregator.Valid || e.GroupBy) && !e.Window.Valid {
+
+					orderCode = exprCodes[pos]
+					break
+				}
+			}
+			if orderCode == "" {
+				atr, exists := cache.AttributeIdMap[ord.AttributeId.Bytes]
+				if !exists {
+					return "", handler.ErrSchemaUnknownAttribute(ord.AttributeId.Bytes)
+				}
+				orderCode = getAttributeCode(getRelationCode(int(ord.Index.Int32), nestingLevel), atr.Name)
+			}
+		} else {
+			return "", errors.New("unknown data GET order parameter")
+		}
+
+		if ord.Ascending {
+			orderItems = append(orderItems, fmt.Sprintf("%s ASC", orderCode))
+		} else {
+			orderItems = append(orderItems, fmt.Sprintf("%s DESC NULLS LAST", orderCode))
+		}
+	}
+
+	window, err := data_sql.GetWindow(expr.Window.String, code,
+		expr.WindowOffset, partitionItems, orderItems)
+
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s AS %s", window, data_sql.GetExpressionAlias(exprPos)), nil
+}
+
+// returns GROUP BY item for grouping sets and codes of all GROUP BY expressions in order
+// returns empty item if regular grouping is used
+func getQueryGrouping(data types.DataGet, exprCodes map[int]string) (string, []string, error) {
+
+	if data.Grouping == "" {
+		return "", []string{}, nil
+	}
+
+	codes := make([]string, 0)
+	codeByPos := make(map[int]string)
+	for pos, expr := range data.Expressions {
+		if !expr.GroupBy {
+			continue
+		}
+		code, exists := exprCodes[pos]
+		if !exists {
+			return "", codes, fmt.Errorf("grouping requires GROUP BY expressions to be attributes from their own relation (expression %d)", pos)
+		}
+		codes = append(codes, code)
+		codeByPos[pos] = code
+	}
+	if len(codes) == 0 {
+		return "", codes, errors.New("grouping requires at least one GROUP BY expression")
+	}
+
+	switch data.Grouping {
+	case "cube":
+		return fmt.Sprintf("CUBE(%s)", strings.Join(codes, ", ")), codes, nil
+	case "rollup":
+		return fmt.Sprintf("ROLLUP(%s)", strings.Join(codes, ", ")), codes, nil
+	case "sets":
+		if len(data.GroupingSets) == 0 {
+			return "", codes, errors.New("grouping sets are empty")
+		}
+		sets := make([]string, 0)
+		for _, set := range data.GroupingSets {
+			setCodes := make([]string, 0)
+			for _, pos := range set {
+				code, exists := codeByPos[pos]
+				if !exists {
+					return "", codes, fmt.Errorf("grouping set refers to expression %d, which is not a GROUP BY expression", pos)
+				}
+				setCodes = append(setCodes, code)
+			}
+			sets = append(sets, fmt.Sprintf("(%s)", strings.Join(setCodes, ", ")))
+		}
+		return fmt.Sprintf("GROUPING SETS(%s)", strings.Join(sets, ", ")), codes, nil
+	}
+	return "", codes, fmt.Errorf("invalid grouping '%s'", data.Grouping)
+}
+
 func getQueryJoin(indexRelationIds map[int]uuid.UUID, join types.DataGetJoin, filters []types.DataGetFilter,
 	queryArgs *[]interface{}, loginId int64, nestingLevel int) (string, error) {
 
@@ -612,6 +920,9 @@ func getQueryJoin(indexRelationIds map[int]uuid.UUID, join types.DataGetJoin, fi
 		return "", err
 	}
 
+	// soft deleted records are not joined
+	policyFilter += getSoftDeleteFilter(relTarget, getRelationCode(join.Index, nestingLevel))
+
 	// parse join filters
 	inWhere := make([]string, 0)
 	for _, filter := range filters {
@@ -849,6 +1160,9 @@ func getRelationCode(relationIndex int, nestingLevel int) string {
 func getTupleIdCode(relationIndex int, nestingLevel int) string {
 	return fmt.Sprintf("%sid", getRelationCode(relationIndex, nestingLevel))
 }
+func getTupleVersionCode(relationIndex int, nestingLevel int) string {
+	return fmt.Sprintf("%sver", getRelationCode(relationIndex, nestingLevel))
+}
 
 // an attribute is referenced by the relation code + the attribute name
 // due to the relation code, this will always uniquely identify an attribute from a specific index
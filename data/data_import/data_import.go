@@ -215,6 +215,15 @@ func FromInterfaceValues_tx(ctx context.Context, tx pgx.Tx, loginId int64,
 		}
 	}
 
+	// values of generated attributes can be used for lookups but are computed by the database, they are ignored for writing
+	// (API clients often send back records as they were retrieved, including generated attributes)
+	for index, dataSet := range dataSetsByIndex {
+		dataSet.Attributes = slices.DeleteFunc(dataSet.Attributes, func(a types.DataSetAttribute) bool {
+			return cache.AttributeIdMap[a.AttributeId].Generated.Valid
+		})
+		dataSetsByIndex[index] = dataSet
+	}
+
 	// go through to be created/updated records after resolving unique indexes
 	for _, join := range joins {
 		dataSet := dataSetsByIndex[join.Index]
@@ -312,5 +321,5 @@ func FromInterfaceValues_tx(ctx context.Context, tx pgx.Tx, loginId int64,
 			}
 		}
 	}
-	return data.Set_tx(ctx, tx, dataSetsByIndex, loginId)
+	return data.Set_tx(ctx, tx, dataSetsByIndex, loginId, uuid.Nil)
 }
@@ -0,0 +1,242 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/config"
+	"r3/handler"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// record locks are tied to websocket clients and released when they disconnect
+// locks of clients connected to cluster nodes that are missing are ignored and removed
+// parameters: $3 = own node ID, $4 = check-in cut off date
+const dataLockStaleNodeCondition = `
+	SELECT id
+	FROM instance_cluster.node
+	WHERE id            <> $3
+	AND   date_check_in <  $4
+`
+
+// acquires lock for record of pessimistic lock relation for websocket client
+// if record is already locked by another client, the existing lock is returned
+// returns whether the lock was newly acquired
+func Lock_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64,
+	loginId int64, clientId uuid.UUID) (types.DataLock, bool, error) {
+
+	var lock types.DataLock
+
+	if clientId == uuid.Nil {
+		return lock, false, errors.New("record locks require a websocket client")
+	}
+
+	// check for authorized access, WRITE(2) as lock is used for editing
+	if !authorizedRelation(loginId, relationId, types.AccessWrite) {
+		return lock, false, errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return lock, false, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return lock, false, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+	if !relationUsesLockPessimistic(rel) {
+		return lock, false, fmt.Errorf("relation '%s' does not use record locks", rel.Name)
+	}
+
+	// locks block all other writes, only records that can be updated by the login can be locked
+	tableAlias := "t"
+	policyFilter, err := getPolicyFilter(loginId, "update", tableAlias, rel.Policies)
+	if err != nil {
+		return lock, false, err
+	}
+
+	var recordExists bool
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM "%s"."%s" AS "%s"
+			WHERE "%s"."%s" = $1
+			%s%s
+		)
+	`, mod.Name, rel.Name, tableAlias, tableAlias, schema.PkName, policyFilter,
+		getSoftDeleteFilter(rel, tableAlias)), recordId).Scan(&recordExists); err != nil {
+
+		return lock, false, err
+	}
+	if !recordExists {
+		return lock, false, errors.New(handler.ErrUnauthorized)
+	}
+
+	if err := delLockStale_tx(ctx, tx, relationId, recordId); err != nil {
+		return lock, false, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO instance.data_lock (relation_id, record_id,
+			login_id, client_id, node_id, date_lock)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT DO NOTHING
+	`, relationId, recordId, loginId, clientId, cache.GetNodeId(), tools.GetTimeUnix())
+	if err != nil {
+		return lock, false, err
+	}
+
+	var lockClientId uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		SELECT l.login_id, lg.name, l.client_id, l.date_lock
+		FROM instance.data_lock AS l
+		JOIN instance.login     AS lg ON lg.id = l.login_id
+		WHERE l.relation_id = $1
+		AND   l.record_id   = $2
+	`, relationId, recordId).Scan(&lock.LoginId, &lock.LoginName,
+		&lockClientId, &lock.DateLock); err != nil {
+
+		return lock, false, err
+	}
+	lock.RelationId = relationId
+	lock.RecordId = recordId
+	lock.Own = lockClientId == clientId
+	return lock, tag.RowsAffected() != 0, nil
+}
+
+// releases record lock held by websocket client
+// returns whether a lock was released
+func Unlock_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, clientId uuid.UUID) (bool, error) {
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM instance.data_lock
+		WHERE relation_id = $1
+		AND   record_id   = $2
+		AND   client_id   = $3
+	`, relationId, recordId, clientId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() != 0, nil
+}
+
+// releases all record locks held by websocket client, used when client disconnects
+// returns released locks
+func UnlockByClient_tx(ctx context.Context, tx pgx.Tx, clientId uuid.UUID) ([]types.DataLock, error) {
+
+	locks := make([]types.DataLock, 0)
+	rows, err := tx.Query(ctx, `
+		DELETE FROM instance.data_lock
+		WHERE client_id = $1
+		RETURNING relation_id, record_id, login_id, date_lock
+	`, clientId)
+	if err != nil {
+		return locks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l types.DataLock
+		if err := rows.Scan(&l.RelationId, &l.RecordId, &l.LoginId, &l.DateLock); err != nil {
+			return locks, err
+		}
+		locks = append(locks, l)
+	}
+	return locks, rows.Err()
+}
+
+// fails if record of pessimistic lock relation is locked by another websocket client
+// requests without client (API, imports) cannot hold locks and are blocked by any lock
+func checkLock_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	recordId int64, clientId uuid.UUID) error {
+
+	if !relationUsesLockPessimistic(rel) {
+		return nil
+	}
+
+	var lockClientId uuid.UUID
+	var loginName string
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT l.client_id, lg.name
+		FROM instance.data_lock AS l
+		JOIN instance.login     AS lg ON lg.id = l.login_id
+		WHERE l.relation_id = $1
+		AND   l.record_id   = $2
+		AND   l.node_id NOT IN (%s)
+	`, dataLockStaleNodeCondition), rel.Id, recordId, cache.GetNodeId(),
+		getLockStaleNodeCutOff()).Scan(&lockClientId, &loginName); err != nil {
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if lockClientId == clientId {
+		return nil
+	}
+	return handler.CreateErrCodeWithData(handler.ErrContextApp, handler.ErrCodeAppRecordLocked, struct {
+		LoginName string `json:"loginName"`
+	}{loginName})
+}
+
+// fails if record changed since it was loaded by the requestor (optimistic lock)
+// record is locked until the end of the transaction, so it cannot be changed between check and update
+func checkVersion_tx(ctx context.Context, tx pgx.Tx, mod types.Module,
+	rel types.Relation, recordId int64, version pgtype.Int8) error {
+
+	if !version.Valid {
+		return nil
+	}
+
+	var versionNow int64
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT "xmin"::TEXT::BIGINT
+		FROM "%s"."%s"
+		WHERE "%s" = $1
+		FOR UPDATE
+	`, mod.Name, rel.Name, schema.PkName), recordId).Scan(&versionNow); err != nil {
+
+		// record does not exist (anymore), update does not affect anything
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if versionNow != version.Int64 {
+		return handler.CreateErrCode(handler.ErrContextApp, handler.ErrCodeAppRecordChanged)
+	}
+	return nil
+}
+
+// removes lock of record if it was held by client of a missing cluster node
+func delLockStale_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64) error {
+	_, err := tx.Exec(ctx, fmt.Sprintf(`
+		DELETE FROM instance.data_lock
+		WHERE relation_id = $1
+		AND   record_id   = $2
+		AND   node_id IN (%s)
+	`, dataLockStaleNodeCondition), relationId, recordId, cache.GetNodeId(),
+		getLockStaleNodeCutOff())
+	return err
+}
+
+func getLockStaleNodeCutOff() int64 {
+	return tools.GetTimeUnix() - int64(config.GetUint64("clusterNodeMissingAfter"))
+}
+
+func relationUsesLockPessimistic(rel types.Relation) bool {
+	return rel.RecordLock.Valid && rel.RecordLock.String == "pessimistic"
+}
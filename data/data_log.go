@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"r3/cache"
 	"r3/db"
 	"r3/handler"
+	"r3/schema"
 	"r3/tools"
 	"r3/types"
 	"slices"
@@ -92,7 +94,7 @@ func GetLogs_tx(ctx context.Context, tx pgx.Tx, recordId int64,
 	}
 
 	rows, err := tx.Query(ctx, `
-		SELECT d.id, d.relation_id, d.date_change, l.name, lm.name_display
+		SELECT d.id, d.relation_id, d.action, d.date_change, l.name, lm.name_display
 		FROM instance.data_log as d
 		LEFT JOIN instance.login      AS l  ON l.id        = d.login_id_wofk
 		LEFT JOIN instance.login_meta AS lm ON lm.login_id = l.id
@@ -113,7 +115,7 @@ func GetLogs_tx(ctx context.Context, tx pgx.Tx, recordId int64,
 		var name pgtype.Text
 		var nameDisplay pgtype.Text
 
-		if err := rows.Scan(&l.Id, &l.RelationId, &l.DateChange, &name, &nameDisplay); err != nil {
+		if err := rows.Scan(&l.Id, &l.RelationId, &l.Action, &l.DateChange, &name, &nameDisplay); err != nil {
 			return logs, err
 		}
 		l.RecordId = recordId
@@ -161,6 +163,237 @@ func getLogValues_tx(ctx context.Context, tx pgx.Tx, logId uuid.UUID,
 	return attributes, nil
 }
 
+// get data change logs of deleted records of specified relation, with snapshot of their last values, latest deletions first
+// only records that have not been restored since their deletion are included
+// records deleted by foreign key cascades (hard delete of a referenced record) are not logged and therefore not included
+func GetLogsDeleted_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	loginId int64, limit int, offset int) ([]types.DataLog, int64, error) {
+
+	logs := make([]types.DataLog, 0)
+	var total int64
+
+	// check for authorized access, READ(1) for GET
+	if !authorizedRelation(loginId, relationId, types.AccessRead) {
+		return logs, 0, errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return logs, 0, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return logs, 0, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	// only show values of readable attributes
+	attributeIds := make([]uuid.UUID, 0)
+	for _, atr := range rel.Attributes {
+		if authorizedAttribute(loginId, atr.Id, types.AccessRead) {
+			attributeIds = append(attributeIds, atr.Id)
+		}
+	}
+
+	// last deletion/restore log per record, record is still deleted if its last log is a deletion
+	subQuery := fmt.Sprintf(`
+		SELECT DISTINCT ON (d.record_id_wofk) d.id, d.record_id_wofk,
+			d.action, d.date_change, d.login_id_wofk
+		FROM instance.data_log AS d
+		WHERE d.relation_id = $1
+		AND d.action IN ('delete','undelete')
+		AND NOT EXISTS (
+			SELECT 1
+			FROM "%s"."%s"
+			WHERE "%s" = d.record_id_wofk
+		)
+		ORDER BY d.record_id_wofk, d.date_change DESC
+	`, mod.Name, rel.Name, schema.PkName)
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT sub.id, sub.record_id_wofk, sub.action, sub.date_change, l.name, lm.name_display
+		FROM (%s) AS sub
+		LEFT JOIN instance.login      AS l  ON l.id        = sub.login_id_wofk
+		LEFT JOIN instance.login_meta AS lm ON lm.login_id = l.id
+		WHERE sub.action = 'delete'
+		ORDER BY sub.date_change DESC, sub.record_id_wofk DESC
+		LIMIT  $2
+		OFFSET $3
+	`, subQuery), relationId, limit, offset)
+	if err != nil {
+		return logs, 0, err
+	}
+
+	for rows.Next() {
+		var l types.DataLog
+		var name pgtype.Text
+		var nameDisplay pgtype.Text
+
+		if err := rows.Scan(&l.Id, &l.RecordId, &l.Action, &l.DateChange, &name, &nameDisplay); err != nil {
+			rows.Close()
+			return logs, 0, err
+		}
+		l.RelationId = relationId
+		l.LoginName = name.String
+		if nameDisplay.Valid && nameDisplay.String != "" {
+			l.LoginName = nameDisplay.String
+		}
+		logs = append(logs, l)
+	}
+	rows.Close()
+
+	for i, log := range logs {
+		log.Attributes, err = getLogValues_tx(ctx, tx, log.Id, attributeIds)
+		if err != nil {
+			return logs, 0, err
+		}
+		logs[i] = log
+	}
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM (%s) AS sub
+		WHERE sub.action = 'delete'
+	`, subQuery), relationId).Scan(&total); err != nil {
+		return logs, 0, err
+	}
+	return logs, total, nil
+}
+
+// get state of specified record at given point in time, reconstructed from data change logs
+// each attribute gets the last value logged at or before the given date
+// attributes without logged values up to this date are not included
+// file attributes are skipped as their logs only contain changes, not full states, generated attributes as they cannot be set
+func GetLogState_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, date int64, loginId int64) (types.DataLogState, error) {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	return getLogState_tx(ctx, tx, relationId, recordId, date, loginId)
+}
+func getLogState_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, date int64, loginId int64) (types.DataLogState, error) {
+
+	state := types.DataLogState{
+		RelationId: relationId,
+		RecordId:   recordId,
+		Date:       date,
+		Attributes: make([]types.DataSetAttribute, 0),
+	}
+
+	if _, exists := cache.RelationIdMap[relationId]; !exists {
+		return state, handler.ErrSchemaUnknownRelation(relationId)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (v.attribute_id, v.attribute_id_nm, v.outside_in)
+			v.attribute_id, v.attribute_id_nm, v.outside_in, v.value
+		FROM instance.data_log AS d
+		JOIN instance.data_log_value AS v ON v.data_log_id = d.id
+		WHERE d.relation_id    = $1
+		AND   d.record_id_wofk = $2
+		AND   d.date_change   <= $3
+		ORDER BY v.attribute_id, v.attribute_id_nm, v.outside_in, d.date_change DESC
+	`, relationId, recordId, date)
+	if err != nil {
+		return state, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a types.DataSetAttribute
+		var value pgtype.Text
+
+		if err := rows.Scan(&a.AttributeId, &a.AttributeIdNm, &a.OutsideIn, &value); err != nil {
+			return state, err
+		}
+
+		atr, exists := cache.AttributeIdMap[a.AttributeId]
+		if !exists || schema.IsContentFiles(atr.Content) || atr.Generated.Valid {
+			// attribute was deleted or is a files attribute, cannot be reconstructed
+			// generated attributes are computed by the database, older logs might still include them
+			continue
+		}
+
+		// check for authorized access, READ(1) for GET
+		// values the login cannot read are left out, instead of rejecting the whole state
+		if !authorizedAttribute(loginId, a.AttributeId, types.AccessRead) {
+			continue
+		}
+
+		if value.Valid {
+			if err := json.Unmarshal([]byte(value.String), &a.Value); err != nil {
+				return state, err
+			}
+		}
+		state.Attributes = append(state.Attributes, a)
+	}
+	return state, rows.Err()
+}
+
+// revert specified record to its state at given point in time, reconstructed from data change logs
+// if attribute IDs are given, only these attributes are reverted
+// changes are applied as regular data SET, so the revert itself is logged as well
+func RevertLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64,
+	date int64, attributeIds []uuid.UUID, loginId int64, clientId uuid.UUID) (types.DataLogState, error) {
+
+	state, err := func() (types.DataLogState, error) {
+		cache.Schema_mx.RLock()
+		defer cache.Schema_mx.RUnlock()
+
+		rel, exists := cache.RelationIdMap[relationId]
+		if !exists {
+			return types.DataLogState{}, handler.ErrSchemaUnknownRelation(relationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return types.DataLogState{}, handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		// deleted records cannot be reverted
+		var recordExists bool
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT EXISTS(
+				SELECT 1
+				FROM "%s"."%s"
+				WHERE "%s" = $1
+			)
+		`, mod.Name, rel.Name, schema.PkName), recordId).Scan(&recordExists); err != nil {
+			return types.DataLogState{}, err
+		}
+		if !recordExists {
+			return types.DataLogState{}, fmt.Errorf("record %d does not exist", recordId)
+		}
+		return getLogState_tx(ctx, tx, relationId, recordId, date, loginId)
+	}()
+	if err != nil {
+		return state, err
+	}
+
+	if len(attributeIds) != 0 {
+		state.Attributes = slices.DeleteFunc(state.Attributes, func(a types.DataSetAttribute) bool {
+			return !slices.Contains(attributeIds, a.AttributeId)
+		})
+	}
+	if len(state.Attributes) == 0 {
+		return state, nil
+	}
+
+	_, err = Set_tx(ctx, tx, map[int]types.DataSet{
+		0: {
+			RelationId: relationId,
+			RecordId:   recordId,
+			Attributes: state.Attributes,
+			EncKeysSet: make([]types.DataSetEncKeys, 0),
+		},
+	}, loginId, clientId)
+
+	return state, err
+}
+
 // set data change log for specific record that was either created or updated
 func setLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 	attributes []types.DataSetAttribute, fileAttributeIndexes []int,
@@ -169,7 +402,7 @@ func setLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 
 	// new record, apply logs for record and its attribute values
 	if wasCreated {
-		logId, err := setLogRecord_tx(ctx, tx, relationId, loginId, recordId)
+		logId, err := setLogRecord_tx(ctx, tx, relationId, "insert", loginId, recordId)
 		if err != nil {
 			return err
 		}
@@ -241,7 +474,7 @@ func setLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 		return nil
 	}
 
-	logId, err := setLogRecord_tx(ctx, tx, relationId, loginId, recordId)
+	logId, err := setLogRecord_tx(ctx, tx, relationId, "update", loginId, recordId)
 	if err != nil {
 		return err
 	}
@@ -254,8 +487,108 @@ func setLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 	}
 	return nil
 }
+
+// set data change logs with identical values for multiple records
+// used for deletions and for records whose relationship attribute values were changed from a partner relation
+// relationship changes happen when references are set via outside-in attributes (1:n and n:m)
+func setLogForRecords_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	recordIds []int64, action string, attributes []types.DataSetAttribute, loginId int64) error {
+
+	if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		return nil
+	}
+	for _, recordId := range recordIds {
+		logId, err := setLogRecord_tx(ctx, tx, rel.Id, action, loginId, recordId)
+		if err != nil {
+			return err
+		}
+		for _, atr := range attributes {
+			if err := setLogValue_tx(ctx, tx, logId, atr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// set data change log for deleted record, with snapshot of its last values
+// snapshot is the record row as JSON object, file attributes are not included as they are not stored in the record
+func setLogDelete_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	recordId int64, rowJson []byte, loginId int64) error {
+
+	attributes, err := getLogSnapshotAttributes(rel, rowJson)
+	if err != nil {
+		return err
+	}
+	return setLogForRecords_tx(ctx, tx, rel, []int64{recordId}, "delete", attributes, loginId)
+}
+
+// delete records with given query, deletions are logged if relation uses logging
+// query must use "t" as table alias
+func delWithLog_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	query string, loginId int64, args ...interface{}) error {
+
+	if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`%s RETURNING "t"."%s", ROW_TO_JSON("t".*)`,
+		query, schema.PkName), args...)
+	if err != nil {
+		return err
+	}
+
+	recordIds := make([]int64, 0)
+	rowsJson := make([][]byte, 0)
+	for rows.Next() {
+		var recordId int64
+		var rowJson []byte
+		if err := rows.Scan(&recordId, &rowJson); err != nil {
+			rows.Close()
+			return err
+		}
+		recordIds = append(recordIds, recordId)
+		rowsJson = append(rowsJson, rowJson)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, recordId := range recordIds {
+		if err := setLogDelete_tx(ctx, tx, rel, recordId, rowsJson[i], loginId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns attribute values from record row as JSON object
+func getLogSnapshotAttributes(rel types.Relation, rowJson []byte) ([]types.DataSetAttribute, error) {
+	attributes := make([]types.DataSetAttribute, 0)
+
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(rowJson, &row); err != nil {
+		return attributes, err
+	}
+	for _, atr := range rel.Attributes {
+		// generated attributes are computed by the database, they are neither logged nor restored
+		if atr.Id == rel.AttributeIdPk || atr.Generated.Valid || schema.IsContentFiles(atr.Content) {
+			continue
+		}
+		if value, exists := row[atr.Name]; exists {
+			attributes = append(attributes, types.DataSetAttribute{
+				AttributeId: atr.Id,
+				Value:       value,
+			})
+		}
+	}
+	return attributes, nil
+}
+
 func setLogRecord_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
-	loginId int64, recordId int64) (uuid.UUID, error) {
+	action string, loginId int64, recordId int64) (uuid.UUID, error) {
 
 	logId, err := uuid.NewV4()
 	if err != nil {
@@ -264,9 +597,9 @@ func setLogRecord_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 
 	if _, err := tx.Exec(ctx, `
 		INSERT INTO instance.data_log (id, relation_id,
-			login_id_wofk, record_id_wofk, date_change)
-		VALUES ($1,$2,$3,$4,$5)
-	`, logId, relationId, loginId, recordId, tools.GetTimeUnix()); err != nil {
+			login_id_wofk, record_id_wofk, date_change, action)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, logId, relationId, loginId, recordId, tools.GetTimeUnix(), action); err != nil {
 		return logId, err
 	}
 	return logId, nil
@@ -1,9 +1,11 @@
 package data_query
 
 import (
+	"fmt"
 	"r3/cache"
 	"r3/types"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -17,9 +19,23 @@ func ConvertColumnToExpression(column types.Column, loginId int64, languageCode
 		Index:       column.Index,
 		GroupBy:     column.GroupBy,
 		Aggregator:  pgtype.Text{}, // aggregation is done on the expression containing the sub query
+		DateBucket:  column.DateBucket,
 		Distincted:  column.Distincted,
+		Window:      column.Window,
 	}
 	if !column.SubQuery {
+		// column positions are used as expression positions, as each column is one expression
+		if column.Window.Valid {
+			expr.WindowOffset = column.WindowOffset
+			expr.WindowPartition = column.WindowPartition
+			expr.WindowOrders = make([]types.DataGetOrder, 0)
+			for _, o := range column.WindowOrders {
+				expr.WindowOrders = append(expr.WindowOrders, types.DataGetOrder{
+					ExpressionPos: pgtype.Int4{Int32: int32(o.ColumnPos), Valid: true},
+					Ascending:     o.Ascending,
+				})
+			}
+		}
 		return expr
 	}
 
@@ -157,6 +173,23 @@ func ConvertQueryToDataJoins(joins []types.QueryJoin) []types.DataGetJoin {
 	return joinsOut
 }
 
+// API queries are executed directly, their source relation (index 0) is not joined
+func ConvertApiQueryToDataJoins(joins []types.QueryJoin) []types.DataGetJoin {
+	joinsOut := make([]types.DataGetJoin, 0)
+	for _, join := range joins {
+		if join.Index == 0 {
+			continue
+		}
+		joinsOut = append(joinsOut, types.DataGetJoin{
+			AttributeId: join.AttributeId.Bytes,
+			Connector:   join.Connector,
+			Index:       join.Index,
+			IndexFrom:   join.IndexFrom,
+		})
+	}
+	return joinsOut
+}
+
 func ConvertQueryToDataOrders(orders []types.QueryOrder) []types.DataGetOrder {
 	ordersOut := make([]types.DataGetOrder, 0)
 	for _, order := range orders {
@@ -168,3 +201,37 @@ func ConvertQueryToDataOrders(orders []types.QueryOrder) []types.DataGetOrder {
 	}
 	return ordersOut
 }
+
+// verbose API output references, relation reference by index and column reference by column position
+// example: { "0(person)":{"firstname":"Hans", ...}, "1(department)":{"name":"IT"}...}
+func GetApiVerboseRefs(api types.Api, languageCode string) (map[int]string, []string) {
+	relIndexMapRef := make(map[int]string)
+	colRefByColumn := make([]string, len(api.Columns))
+	subQueryCtr := 0
+	for i, column := range api.Columns {
+		atr := cache.AttributeIdMap[column.AttributeId]
+		rel := cache.RelationIdMap[atr.RelationId]
+		colRef := ""
+
+		if ref, exists := column.Captions["columnTitle"][languageCode]; exists {
+			colRef = ref
+		} else {
+			if column.SubQuery {
+				colRef = fmt.Sprintf("sub_query%d", subQueryCtr)
+				subQueryCtr++
+			} else {
+				colRef = atr.Name
+			}
+
+			if column.Aggregator.Valid {
+				colRef = fmt.Sprintf("%s (%s)", strings.ToUpper(column.Aggregator.String), colRef)
+			}
+		}
+		colRefByColumn[i] = colRef
+
+		if _, exists := relIndexMapRef[column.Index]; !exists {
+			relIndexMapRef[column.Index] = fmt.Sprintf("%d(%s)", column.Index, rel.Name)
+		}
+	}
+	return relIndexMapRef, colRefByColumn
+}
@@ -26,8 +26,9 @@ import (
 // if tuple needs to exist for joined relation to refer to, it will be created
 // each index provides tuple ID (0 if new)
 // each index provides values for its relation attributes or partner relation attributes (relationship attributes from other relation)
+// client ID is used to check record locks, nil UUID if request does not come from websocket client
 func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSet,
-	loginId int64) (map[int]int64, error) {
+	loginId int64, clientId uuid.UUID) (map[int]int64, error) {
 
 	cache.Schema_mx.RLock()
 	defer cache.Schema_mx.RUnlock()
@@ -43,6 +44,29 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 	}
 	sort.Ints(indexes)
 
+	// check record locks & versions of updated records, before any index is changed
+	for _, index := range indexes {
+		dataSet := dataSetsByIndex[index]
+		if dataSet.RecordId == 0 || len(dataSet.Attributes) == 0 {
+			continue
+		}
+
+		rel, exists := cache.RelationIdMap[dataSet.RelationId]
+		if !exists {
+			return indexRecordIds, handler.ErrSchemaUnknownRelation(dataSet.RelationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return indexRecordIds, handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+		if err := checkLock_tx(ctx, tx, rel, dataSet.RecordId, clientId); err != nil {
+			return indexRecordIds, err
+		}
+		if err := checkVersion_tx(ctx, tx, mod, rel, dataSet.RecordId, dataSet.Version); err != nil {
+			return indexRecordIds, err
+		}
+	}
+
 	// set data for each index in ascending index order, important to resolve relationships
 	for _, index := range indexes {
 
@@ -146,7 +170,35 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 			}
 		}
 	}
-	return indexRecordIds, nil
+
+	// spool webhooks after all indexes are set, payloads can include joined relations
+	relationIdsChanged := make([]uuid.UUID, 0)
+	for _, index := range indexes {
+		dataSet := dataSetsByIndex[index]
+		action := "update"
+		if dataSet.RecordId == 0 {
+			action = "insert"
+		} else if len(dataSet.Attributes) == 0 {
+			continue
+		}
+
+		if !slices.Contains(relationIdsChanged, dataSet.RelationId) {
+			relationIdsChanged = append(relationIdsChanged, dataSet.RelationId)
+		}
+
+		calls, err := webhookGetCalls_tx(ctx, tx, dataSet.RelationId,
+			indexRecordIds[index], action, loginId)
+
+		if err != nil {
+			return indexRecordIds, err
+		}
+		if err := webhookSpool_tx(ctx, tx, calls); err != nil {
+			return indexRecordIds, fmt.Errorf("failed to spool webhooks, %v", err)
+		}
+	}
+
+	// inform clients watching changed relations, once the transaction is committed
+	return indexRecordIds, dataChanged_tx(ctx, tx, clientId, relationIdsChanged)
 }
 
 // set data values for specific relation index
@@ -196,6 +248,11 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 			return handler.ErrSchemaUnknownAttribute(attribute.AttributeId)
 		}
 
+		// generated attributes are computed by the database, they are read only
+		if atr.Generated.Valid {
+			return fmt.Errorf("cannot set value of generated attribute '%s'", atr.Name)
+		}
+
 		// process relationship values from other relation
 		// (1:n, 1:1 relationships referring to this tuple)
 		if attribute.OutsideIn && schema.IsContentRelationship(atr.Content) {
@@ -251,6 +308,9 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 			return err
 		}
 
+		// soft deleted records cannot be updated until restored
+		policyFilter += getSoftDeleteFilter(rel, tableAlias)
+
 		values = append(values, dataSet.RecordId)
 		if _, err := tx.Exec(ctx, fmt.Sprintf(`
 			UPDATE "%s"."%s" AS "%s" SET %s
@@ -397,20 +457,27 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 			// remove all references
 			if !shipValues.attributeIdNm.Valid {
 
-				if _, err := tx.Exec(ctx, fmt.Sprintf(`
+				recordIdsChanged, err := updateReturnIds_tx(ctx, tx, fmt.Sprintf(`
 					UPDATE "%s"."%s" SET "%s" = NULL
 					WHERE "%s" = $1
+					RETURNING "%s"
 				`, shipMod.Name, shipRel.Name, shipAtr.Name,
-					shipAtr.Name), indexRecordIds[index]); err != nil {
+					shipAtr.Name, schema.PkName), indexRecordIds[index])
+
+				if err != nil {
+					return err
+				}
+				if err := setLogForRecords_tx(ctx, tx, shipRel, recordIdsChanged, "update",
+					[]types.DataSetAttribute{{AttributeId: shipAtr.Id, Value: nil}}, loginId); err != nil {
 
 					return err
 				}
 			} else {
-				if _, err := tx.Exec(ctx, fmt.Sprintf(`
-					DELETE FROM "%s"."%s"
-					WHERE "%s" = $1
-				`, shipMod.Name, shipRel.Name,
-					shipAtr.Name), indexRecordIds[index]); err != nil {
+				if err := delWithLog_tx(ctx, tx, shipRel, fmt.Sprintf(`
+					DELETE FROM "%s"."%s" AS "t"
+					WHERE "t"."%s" = $1
+				`, shipMod.Name, shipRel.Name, shipAtr.Name),
+					loginId, indexRecordIds[index]); err != nil {
 
 					return err
 				}
@@ -421,23 +488,46 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 		if !shipValues.attributeIdNm.Valid {
 
 			// remove old references to this tuple
-			if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			recordIdsRemoved, err := updateReturnIds_tx(ctx, tx, fmt.Sprintf(`
 				UPDATE "%s"."%s" SET "%s" = NULL
 				WHERE "%s" = $1
 				AND "%s" <> ALL($2)
+				RETURNING "%s"
 			`, shipMod.Name, shipRel.Name, shipAtr.Name,
-				shipAtr.Name, schema.PkName), indexRecordIds[index],
-				shipValues.values); err != nil {
+				shipAtr.Name, schema.PkName, schema.PkName),
+				indexRecordIds[index], shipValues.values)
+
+			if err != nil {
+				return err
+			}
+			if err := setLogForRecords_tx(ctx, tx, shipRel, recordIdsRemoved, "update",
+				[]types.DataSetAttribute{{AttributeId: shipAtr.Id, Value: nil}}, loginId); err != nil {
 
 				return err
 			}
 
 			// add new references to this tuple
-			if _, err := tx.Exec(ctx, fmt.Sprintf(`
-				UPDATE "%s"."%s" SET "%s" = $1
-				WHERE "%s" = ANY($2)
-			`, shipMod.Name, shipRel.Name, shipAtr.Name, schema.PkName),
-				indexRecordIds[index], shipValues.values); err != nil {
+			// records that already referenced this tuple are updated but not logged, as their value did not change
+			recordIdsAdded, err := updateReturnIds_tx(ctx, tx, fmt.Sprintf(`
+				WITH changed AS (
+					SELECT "%s" AS id
+					FROM "%s"."%s"
+					WHERE "%s" = ANY($2)
+					AND "%s" IS DISTINCT FROM $1
+				), updated AS (
+					UPDATE "%s"."%s" SET "%s" = $1
+					WHERE "%s" = ANY($2)
+				)
+				SELECT id FROM changed
+			`, schema.PkName, shipMod.Name, shipRel.Name, schema.PkName, shipAtr.Name,
+				shipMod.Name, shipRel.Name, shipAtr.Name, schema.PkName),
+				indexRecordIds[index], shipValues.values)
+
+			if err != nil {
+				return err
+			}
+			if err := setLogForRecords_tx(ctx, tx, shipRel, recordIdsAdded, "update",
+				[]types.DataSetAttribute{{AttributeId: shipAtr.Id, Value: indexRecordIds[index]}}, loginId); err != nil {
 
 				return err
 			}
@@ -466,12 +556,12 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 					continue
 				}
 
-				if _, err := tx.Exec(ctx, fmt.Sprintf(`
-					DELETE FROM "%s"."%s"
-					WHERE "%s" = $1
-					AND "%s" = $2
+				if err := delWithLog_tx(ctx, tx, shipRel, fmt.Sprintf(`
+					DELETE FROM "%s"."%s" AS "t"
+					WHERE "t"."%s" = $1
+					AND   "t"."%s" = $2
 				`, shipMod.Name, shipRel.Name, shipAtr.Name, shipAtrNm.Name),
-					indexRecordIds[index], value); err != nil {
+					loginId, indexRecordIds[index], value); err != nil {
 
 					return err
 				}
@@ -483,11 +573,21 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 					continue
 				}
 
-				if _, err := tx.Exec(ctx, fmt.Sprintf(`
+				var recordIdNm int64
+				if err := tx.QueryRow(ctx, fmt.Sprintf(`
 					INSERT INTO "%s"."%s" ("%s","%s")
 					VALUES ($1,$2)
-				`, shipMod.Name, shipRel.Name, shipAtr.Name, shipAtrNm.Name),
-					indexRecordIds[index], value); err != nil {
+					RETURNING "%s"
+				`, shipMod.Name, shipRel.Name, shipAtr.Name, shipAtrNm.Name, schema.PkName),
+					indexRecordIds[index], value).Scan(&recordIdNm); err != nil {
+
+					return err
+				}
+				if err := setLogForRecords_tx(ctx, tx, shipRel, []int64{recordIdNm}, "insert",
+					[]types.DataSetAttribute{
+						{AttributeId: shipAtr.Id, Value: indexRecordIds[index]},
+						{AttributeId: shipAtrNm.Id, Value: value},
+					}, loginId); err != nil {
 
 					return err
 				}
@@ -497,6 +597,26 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 	return nil
 }
 
+// executes query returning record IDs of changed records
+func updateReturnIds_tx(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) ([]int64, error) {
+	recordIds := make([]int64, 0)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return recordIds, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return recordIds, err
+		}
+		recordIds = append(recordIds, id)
+	}
+	return recordIds, rows.Err()
+}
+
 func collectCurrentValuesForLog_tx(ctx context.Context, tx pgx.Tx,
 	relationId uuid.UUID, attributes []types.DataSetAttribute,
 	fileAttributeIndexes []int, recordId int64, loginId int64) (types.DataGetResult, error) {
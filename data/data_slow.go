@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"r3/types"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// transaction that records its executed statements
+// writes (set, del) consist of multiple statements, recorded statements are logged if the write was slow
+// statements are only recorded if slow query logging is enabled, large writes keep the first & last statements
+// savepoints (sub transactions via Begin) record into the same list
+type TxRecorder struct {
+	pgx.Tx
+	rec *txRecording
+}
+type txRecording struct {
+	active bool
+	count  int      // number of executed statements
+	first  []string // first statements, up to txRecorderKeep
+	last   []string // ring buffer of last statements, up to txRecorderKeep
+}
+
+const txRecorderKeep = 50
+
+func NewTxRecorder(tx pgx.Tx) *TxRecorder {
+	return &TxRecorder{Tx: tx, rec: &txRecording{
+		active: config.GetUint64("slowQueryThresholdMs") != 0,
+	}}
+}
+
+func (t *TxRecorder) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &TxRecorder{Tx: tx, rec: t.rec}, nil
+}
+func (t *TxRecorder) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.rec.add(sql)
+	return t.Tx.Exec(ctx, sql, args...)
+}
+func (t *TxRecorder) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	t.rec.add(sql)
+	return t.Tx.Query(ctx, sql, args...)
+}
+func (t *TxRecorder) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	t.rec.add(sql)
+	return t.Tx.QueryRow(ctx, sql, args...)
+}
+func (t *TxRecorder) QueriesJoined() string {
+	r := t.rec
+	if r.count <= len(r.first)+len(r.last) {
+		return strings.Join(append(r.first, r.last...), ";\n")
+	}
+
+	// ring buffer starts with oldest statement at next write position
+	pos := (r.count - len(r.first)) % txRecorderKeep
+	last := append(r.last[pos:], r.last[:pos]...)
+
+	return fmt.Sprintf("%s;\n-- %d statements omitted\n%s",
+		strings.Join(r.first, ";\n"), r.count-len(r.first)-len(last), strings.Join(last, ";\n"))
+}
+
+func (r *txRecording) add(sql string) {
+	if !r.active {
+		return
+	}
+	if len(r.first) < txRecorderKeep {
+		r.first = append(r.first, sql)
+	} else if len(r.last) < txRecorderKeep {
+		r.last = append(r.last, sql)
+	} else {
+		r.last[(r.count-len(r.first))%txRecorderKeep] = sql
+	}
+	r.count++
+}
+
+// logs data request (get, set, del) if its duration exceeded the configured threshold
+// origin is either the query (field, form, collection) or the API the request was made from
+// relations are all relations the request read from or wrote to, query is the executed SQL
+// entries are written outside of the request transaction, so that failed (timed out) requests are also kept
+func LogSlow(action string, dateStart time.Time, loginId int64, relationIds []uuid.UUID,
+	queryId pgtype.UUID, apiId pgtype.UUID, query string) {
+
+	threshold := config.GetUint64("slowQueryThresholdMs")
+	if threshold == 0 {
+		return
+	}
+
+	duration := time.Since(dateStart).Milliseconds()
+	if duration < int64(threshold) {
+		return
+	}
+
+	loginIdNull := pgtype.Int4{Int32: int32(loginId), Valid: loginId != 0}
+	queryNull := pgtype.Text{String: query, Valid: query != ""}
+	nodeId := cache.GetNodeId()
+
+	go func() {
+		ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutLogWrite)
+		defer ctxCanc()
+
+		if _, err := db.Pool.Exec(ctx, `
+			INSERT INTO instance.log_slow_query (action, login_id, relation_ids,
+				query_id, api_id, duration_ms, date_milli, node_id, sql)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		`, action, loginIdNull, relationIds, queryId, apiId, duration,
+			tools.GetTimeUnixMilli(), nodeId, queryNull); err != nil {
+
+			log.Error(log.ContextServer, "failed to write slow query log", err)
+		}
+	}()
+}
+
+// returns slow query log entries, ordered by date or duration (descending)
+// origin of queries is resolved to field/form/collection/API, if the query still exists
+func GetLogSlow_tx(ctx context.Context, tx pgx.Tx, orderByDuration bool,
+	limit int, offset int) ([]types.LogSlowQuery, int64, error) {
+
+	entries := make([]types.LogSlowQuery, 0)
+	var total int64
+
+	orderBy := "l.date_milli DESC"
+	if orderByDuration {
+		orderBy = "l.duration_ms DESC, l.date_milli DESC"
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT l.action, lg.name, l.relation_ids, COALESCE(l.api_id, q.api_id),
+			q.collection_id, q.field_id, COALESCE(q.form_id, f.form_id),
+			n.name, l.duration_ms, l.date_milli, l.sql
+		FROM      instance.log_slow_query AS l
+		LEFT JOIN instance.login          AS lg ON lg.id = l.login_id
+		LEFT JOIN app.query               AS q  ON q.id  = l.query_id
+		LEFT JOIN app.field               AS f  ON f.id  = q.field_id
+		LEFT JOIN instance_cluster.node   AS n  ON n.id  = l.node_id
+		ORDER BY %s
+		LIMIT  $1
+		OFFSET $2
+	`, orderBy), limit, offset)
+	if err != nil {
+		return entries, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e types.LogSlowQuery
+		if err := rows.Scan(&e.Action, &e.LoginName, &e.RelationIds, &e.ApiId,
+			&e.CollectionId, &e.FieldId, &e.FormId, &e.NodeName, &e.DurationMs,
+			&e.Date, &e.Sql); err != nil {
+
+			return entries, 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.log_slow_query
+	`).Scan(&total); err != nil {
+		return entries, 0, err
+	}
+	return entries, total, nil
+}
@@ -3,6 +3,18 @@ package data_sql
 import (
 	"fmt"
 	"r3/types"
+	"slices"
+	"strings"
+)
+
+var (
+	// date buckets, truncate date/datetime values (unix seconds) to start of period
+	DateBuckets = []string{"hour", "day", "week", "month", "quarter", "year"}
+
+	// window functions
+	// ranking functions order by expression value if no window order is defined
+	WindowsRanking = []string{"denseRank", "percentRank", "rank"}
+	WindowsRunning = []string{"runningAvg", "runningCount", "runningMax", "runningMin", "runningSum"}
 )
 
 // alias for SELECT expression
@@ -33,27 +45,8 @@ func GetExpression(expr types.DataGetExpression, code string, alias string) stri
 			code = GetExpressionAlias(0)
 		}
 
-		switch expr.Aggregator.String {
-		case "array":
-			return fmt.Sprintf("%sARRAY_AGG(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "avg":
-			return fmt.Sprintf("%sAVG(%s%s)::NUMERIC(20,2)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "count":
-			return fmt.Sprintf("%sCOUNT(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "json":
-			return fmt.Sprintf("%sJSON_AGG(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "list":
-			return fmt.Sprintf("%sSTRING_AGG(%s%s::TEXT, ', ')%s AS %s", prefix, distinct, code, postfix, alias)
-		case "max":
-			return fmt.Sprintf("%sMAX(%s)%s AS %s", prefix, code, postfix, alias)
-		case "min":
-			return fmt.Sprintf("%sMIN(%s)%s AS %s", prefix, code, postfix, alias)
-		case "sum":
-			return fmt.Sprintf("%sSUM(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "record":
-			// returns first result from set
-			// special use case: record IDs are still usable for record selection while other aggregations are active
-			return fmt.Sprintf("%sFIRST(%s)%s AS %s", prefix, code, postfix, alias)
+		if agg := getAggregation(expr, code); agg != "" {
+			return fmt.Sprintf("%s%s%s AS %s", prefix, agg, postfix, alias)
 		}
 	}
 
@@ -63,3 +56,104 @@ func GetExpression(expr types.DataGetExpression, code string, alias string) stri
 	}
 	return fmt.Sprintf("%s%s AS %s", distinct, code, alias)
 }
+
+// returns attribute expression code without alias, aggregated if aggregator is set
+// used where expression aliases cannot be referred to (grouping sets, window functions)
+func GetExpressionCode(expr types.DataGetExpression, code string) string {
+	if expr.Aggregator.Valid {
+		if agg := getAggregation(expr, code); agg != "" {
+			return agg
+		}
+	}
+	return code
+}
+
+// returns date bucket expression, start of period as unix seconds (UTC)
+func GetDateBucket(bucket string, code string) (string, error) {
+	if !slices.Contains(DateBuckets, bucket) {
+		return "", fmt.Errorf("invalid date bucket '%s'", bucket)
+	}
+	return fmt.Sprintf(`EXTRACT(EPOCH FROM DATE_TRUNC('%s', TO_TIMESTAMP(%s) AT TIME ZONE 'UTC'))::BIGINT`,
+		bucket, code), nil
+}
+
+// returns window function expression for given expression code
+// partition and order items are expression codes, order items include sort direction
+func GetWindow(window string, code string, offset int, partitionItems []string, orderItems []string) (string, error) {
+
+	var function string
+	var frame string
+
+	switch window {
+	case "denseRank":
+		function = "DENSE_RANK()"
+	case "percentRank":
+		function = "PERCENT_RANK()"
+	case "rank":
+		function = "RANK()"
+	case "rowNumber":
+		function = "ROW_NUMBER()"
+	case "lag", "lead":
+		if offset < 1 {
+			offset = 1
+		}
+		function = fmt.Sprintf("%s(%s, %d)", strings.ToUpper(window), code, offset)
+	case "runningAvg":
+		function = fmt.Sprintf("AVG(%s)", code)
+	case "runningCount":
+		function = fmt.Sprintf("COUNT(%s)", code)
+	case "runningMax":
+		function = fmt.Sprintf("MAX(%s)", code)
+	case "runningMin":
+		function = fmt.Sprintf("MIN(%s)", code)
+	case "runningSum":
+		function = fmt.Sprintf("SUM(%s)", code)
+	default:
+		return "", fmt.Errorf("invalid window function '%s'", window)
+	}
+
+	// running aggregates include all rows up to the current one, peers with identical order values are not included
+	if slices.Contains(WindowsRunning, window) {
+		frame = " ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"
+	}
+
+	over := make([]string, 0)
+	if len(partitionItems) != 0 {
+		over = append(over, fmt.Sprintf("PARTITION BY %s", strings.Join(partitionItems, ", ")))
+	}
+	if len(orderItems) != 0 {
+		over = append(over, fmt.Sprintf("ORDER BY %s%s", strings.Join(orderItems, ", "), frame))
+	}
+	return fmt.Sprintf("%s OVER (%s)", function, strings.Join(over, " ")), nil
+}
+
+func getAggregation(expr types.DataGetExpression, code string) string {
+	var distinct = ""
+	if expr.Distincted {
+		distinct = "DISTINCT "
+	}
+
+	switch expr.Aggregator.String {
+	case "array":
+		return fmt.Sprintf("ARRAY_AGG(%s%s)", distinct, code)
+	case "avg":
+		return fmt.Sprintf("AVG(%s%s)::NUMERIC(20,2)", distinct, code)
+	case "count":
+		return fmt.Sprintf("COUNT(%s%s)", distinct, code)
+	case "json":
+		return fmt.Sprintf("JSON_AGG(%s%s)", distinct, code)
+	case "list":
+		return fmt.Sprintf("STRING_AGG(%s%s::TEXT, ', ')", distinct, code)
+	case "max":
+		return fmt.Sprintf("MAX(%s)", code)
+	case "min":
+		return fmt.Sprintf("MIN(%s)", code)
+	case "sum":
+		return fmt.Sprintf("SUM(%s%s)", distinct, code)
+	case "record":
+		// returns first result from set
+		// special use case: record IDs are still usable for record selection while other aggregations are active
+		return fmt.Sprintf("FIRST(%s)", code)
+	}
+	return ""
+}
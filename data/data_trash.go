@@ -0,0 +1,374 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// marks record of soft delete relation as deleted, record is kept in trash bin until restored or purged
+// files of record are marked as deleted as well, so that they are restored together with the record
+// deletion is logged (incl. snapshot) if relation uses logging
+// references from other relations follow their 'on delete' action, as the record still exists in the database
+// RESTRICT/NO ACTION blocks deletion, CASCADE soft deletes referencing records (with the same deletion date)
+//
+// returns false if record was not deleted (does not exist, already deleted or blocked by policy)
+func softDel_tx(ctx context.Context, tx pgx.Tx, mod types.Module, rel types.Relation,
+	tableAlias string, policyFilter string, recordId int64, dateDeleted int64, loginId int64,
+	clientId uuid.UUID) (bool, error) {
+
+	query := fmt.Sprintf(`
+		UPDATE "%s"."%s" AS "%s"
+		SET "%s" = $1
+		WHERE "%s"."%s" = $2
+		%s%s
+	`, mod.Name, rel.Name, tableAlias, schema.SoftDeleteName, tableAlias,
+		schema.PkName, policyFilter, getSoftDeleteFilter(rel, tableAlias))
+
+	if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		tag, err := tx.Exec(ctx, query, dateDeleted, recordId)
+		if err != nil || tag.RowsAffected() == 0 {
+			return false, err
+		}
+	} else {
+		var rowJson []byte
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`%s RETURNING ROW_TO_JSON("%s".*)`,
+			query, tableAlias), dateDeleted, recordId).Scan(&rowJson); err != nil {
+
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		if err := setLogDelete_tx(ctx, tx, rel, recordId, rowJson, loginId); err != nil {
+			return false, fmt.Errorf("failed to set data log, %v", err)
+		}
+	}
+
+	if err := softDelReferences_tx(ctx, tx, rel, recordId, dateDeleted, loginId, clientId); err != nil {
+		return false, err
+	}
+
+	for _, atr := range rel.Attributes {
+		if !schema.IsContentFiles(atr.Content) {
+			continue
+		}
+
+		fileIds := make([]uuid.UUID, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT ARRAY_AGG(file_id)
+			FROM instance_file."%s"
+			WHERE record_id   = $1
+			AND   date_delete IS NULL
+		`, schema.GetFilesTableName(atr.Id)), recordId).Scan(&fileIds); err != nil {
+			return false, err
+		}
+
+		if len(fileIds) != 0 {
+			if err := FilesSetDeletedForRecord_tx(ctx, tx, atr.Id, fileIds, recordId); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// applies 'on delete' actions of relationship attributes referring to soft deleted record
+// referencing records in trash bins are ignored, as they are deleted already
+func softDelReferences_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	recordId int64, dateDeleted int64, loginId int64, clientId uuid.UUID) error {
+
+	for _, atr := range getReferencingAttributes(rel.Id) {
+		if atr.OnDelete != "RESTRICT" && atr.OnDelete != "NO ACTION" && atr.OnDelete != "CASCADE" {
+			continue
+		}
+
+		atrRel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			return handler.ErrSchemaUnknownRelation(atr.RelationId)
+		}
+		atrMod, exists := cache.ModuleIdMap[atrRel.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(atrRel.ModuleId)
+		}
+
+		recordIds := make([]int64, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT ARRAY(
+				SELECT "t"."%s"
+				FROM "%s"."%s" AS "t"
+				WHERE "t"."%s" = $1
+				%s
+			)
+		`, schema.PkName, atrMod.Name, atrRel.Name, atr.Name,
+			getSoftDeleteFilter(atrRel, "t")), recordId).Scan(&recordIds); err != nil {
+
+			return err
+		}
+		if len(recordIds) == 0 {
+			continue
+		}
+
+		// referencing records without trash bin cannot be kept, same error as on permanent deletion
+		if atr.OnDelete != "CASCADE" || !atrRel.SoftDelete {
+			return handler.CreateErrCodeWithData(handler.ErrContextDbs, handler.ErrCodeDbsConstraintFk, struct {
+				AttributeId string `json:"attributeId"`
+			}{atr.Id.String()})
+		}
+
+		// cascaded deletion does not apply policies, like the foreign key it replaces
+		for _, id := range recordIds {
+			if _, err := softDel_tx(ctx, tx, atrMod, atrRel, "t", "", id, dateDeleted, loginId, clientId); err != nil {
+				return err
+			}
+		}
+		if err := dataChanged_tx(ctx, tx, clientId, []uuid.UUID{atrRel.Id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns relationship attributes of all relations, that refer to given relation
+func getReferencingAttributes(relationId uuid.UUID) []types.Attribute {
+	attributes := make([]types.Attribute, 0)
+	for _, rel := range cache.RelationIdMap {
+		for _, atr := range rel.Attributes {
+			if schema.IsContentRelationship(atr.Content) &&
+				atr.RelationshipId.Valid && atr.RelationshipId.Bytes == relationId {
+
+				attributes = append(attributes, atr)
+			}
+		}
+	}
+	return attributes
+}
+
+// returns soft deleted records of relation from its trash bin, latest deletions first
+// requires DELETE access, as only records that could be deleted by the login can be restored
+func GetTrash_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, loginId int64,
+	limit int, offset int) ([]types.DataTrashRecord, int64, error) {
+
+	records := make([]types.DataTrashRecord, 0)
+	var total int64
+
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return records, 0, errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, mod, policyFilter, err := getTrashRelation(relationId, loginId)
+	if err != nil {
+		return records, 0, err
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT "t"."%s", "t"."%s", ROW_TO_JSON("t".*)
+		FROM "%s"."%s" AS "t"
+		WHERE "t"."%s" IS NOT NULL
+		%s
+		ORDER BY "t"."%s" DESC, "t"."%s" DESC
+		LIMIT  $1
+		OFFSET $2
+	`, schema.PkName, schema.SoftDeleteName, mod.Name, rel.Name, schema.SoftDeleteName,
+		policyFilter, schema.SoftDeleteName, schema.PkName), limit, offset)
+	if err != nil {
+		return records, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r types.DataTrashRecord
+		var rowJson []byte
+		if err := rows.Scan(&r.RecordId, &r.DateDeleted, &rowJson); err != nil {
+			return records, 0, err
+		}
+
+		attributes, err := getLogSnapshotAttributes(rel, rowJson)
+		if err != nil {
+			return records, 0, err
+		}
+
+		// only show values of readable, unencrypted attributes
+		r.Attributes = make([]types.DataSetAttribute, 0)
+		for _, a := range attributes {
+			atr, exists := cache.AttributeIdMap[a.AttributeId]
+			if exists && !atr.Encrypted && authorizedAttribute(loginId, atr.Id, types.AccessRead) {
+				r.Attributes = append(r.Attributes, a)
+			}
+		}
+		records = append(records, r)
+	}
+	rows.Close()
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM "%s"."%s" AS "t"
+		WHERE "t"."%s" IS NOT NULL
+		%s
+	`, mod.Name, rel.Name, schema.SoftDeleteName, policyFilter)).Scan(&total); err != nil {
+		return records, 0, err
+	}
+	return records, total, nil
+}
+
+// restores soft deleted record from trash bin, including its files and referencing records that were deleted together with it
+func Restore_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, loginId int64) error {
+
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, mod, policyFilter, err := getTrashRelation(relationId, loginId)
+	if err != nil {
+		return err
+	}
+
+	var dateDeleted int64
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT "t"."%s"
+		FROM "%s"."%s" AS "t"
+		WHERE "t"."%s" = $1
+		AND   "t"."%s" IS NOT NULL
+		%s
+	`, schema.SoftDeleteName, mod.Name, rel.Name, schema.PkName,
+		schema.SoftDeleteName, policyFilter), recordId).Scan(&dateDeleted); err != nil {
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("record %d is not in trash bin", recordId)
+		}
+		return err
+	}
+	return restore_tx(ctx, tx, mod, rel, recordId, dateDeleted, loginId)
+}
+
+// restores soft deleted record, restoration is logged (incl. snapshot) if relation uses logging
+// records deleted by cascade (same deletion date) are restored as well
+func restore_tx(ctx context.Context, tx pgx.Tx, mod types.Module, rel types.Relation,
+	recordId int64, dateDeleted int64, loginId int64) error {
+
+	var rowJson []byte
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		UPDATE "%s"."%s" AS "t"
+		SET "%s" = NULL
+		WHERE "t"."%s" = $1
+		RETURNING ROW_TO_JSON("t".*)
+	`, mod.Name, rel.Name, schema.SoftDeleteName, schema.PkName), recordId).Scan(&rowJson); err != nil {
+		return err
+	}
+
+	attributes, err := getLogSnapshotAttributes(rel, rowJson)
+	if err != nil {
+		return err
+	}
+	if err := setLogForRecords_tx(ctx, tx, rel, []int64{recordId}, "undelete", attributes, loginId); err != nil {
+		return fmt.Errorf("failed to set data log, %v", err)
+	}
+
+	// files deleted before the record was moved to the trash bin stay deleted
+	for _, atr := range rel.Attributes {
+		if !schema.IsContentFiles(atr.Content) {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			UPDATE instance_file."%s"
+			SET date_delete = NULL
+			WHERE record_id   =  $1
+			AND   date_delete >= $2
+		`, schema.GetFilesTableName(atr.Id)), recordId, dateDeleted); err != nil {
+			return err
+		}
+	}
+
+	for _, atr := range getReferencingAttributes(rel.Id) {
+		atrRel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			return handler.ErrSchemaUnknownRelation(atr.RelationId)
+		}
+		if atr.OnDelete != "CASCADE" || !atrRel.SoftDelete {
+			continue
+		}
+		atrMod, exists := cache.ModuleIdMap[atrRel.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(atrRel.ModuleId)
+		}
+
+		recordIds := make([]int64, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT ARRAY(
+				SELECT "%s"
+				FROM "%s"."%s"
+				WHERE "%s" = $1
+				AND   "%s" = $2
+			)
+		`, schema.PkName, atrMod.Name, atrRel.Name, atr.Name, schema.SoftDeleteName),
+			recordId, dateDeleted).Scan(&recordIds); err != nil {
+
+			return err
+		}
+		for _, id := range recordIds {
+			if err := restore_tx(ctx, tx, atrMod, atrRel, id, dateDeleted, loginId); err != nil {
+				return err
+			}
+		}
+	}
+	return dataChanged_tx(ctx, tx, uuid.Nil, []uuid.UUID{rel.Id})
+}
+
+// permanently deletes records from trash bin that were soft deleted before the given date
+// deletions are logged (incl. snapshot) if relation uses logging
+func DelTrash_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, dateDeletedBefore int64) error {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+	if !rel.SoftDelete {
+		return nil
+	}
+
+	return delWithLog_tx(ctx, tx, rel, fmt.Sprintf(`
+		DELETE FROM "%s"."%s" AS "t"
+		WHERE "t"."%s" < $1
+	`, mod.Name, rel.Name, schema.SoftDeleteName), 0, dateDeletedBefore)
+}
+
+// returns soft delete relation, its module and applicable policy filter for trash bin access
+// policy for deletion is applied, as restoring a record reverses its deletion
+func getTrashRelation(relationId uuid.UUID, loginId int64) (types.Relation, types.Module, string, error) {
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return rel, types.Module{}, "", handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return rel, mod, "", handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+	if !rel.SoftDelete {
+		return rel, mod, "", fmt.Errorf("relation '%s' does not use soft delete", rel.Name)
+	}
+
+	policyFilter, err := getPolicyFilter(loginId, "delete", "t", rel.Policies)
+	return rel, mod, policyFilter, err
+}
@@ -0,0 +1,191 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"r3/cache"
+	"r3/data/data_query"
+	"r3/handler"
+	"r3/types"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// webhook call, spooled for delivery by REST spooler
+type webhookCall struct {
+	webhook types.Webhook
+	payload []byte
+}
+
+type webhookPayload struct {
+	Action   string      `json:"action"` // insert, update, delete
+	Date     int64       `json:"date"`
+	Module   string      `json:"module"`
+	Relation string      `json:"relation"`
+	RecordId int64       `json:"recordId"`
+	Webhook  string      `json:"webhook"`
+	Data     interface{} `json:"data"` // record data as defined by payload API, NULL if no API is set or login has no access
+}
+
+// returns calls of webhooks triggered by record action
+// payload data is retrieved with the access rights of the acting login
+// if payload API is set, webhook is skipped if record does not match API filters
+// must be called before deletion, as record data is gone afterwards
+func webhookGetCalls_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, action string, loginId int64) ([]webhookCall, error) {
+
+	calls := make([]webhookCall, 0)
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return calls, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	modRel, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return calls, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	for _, mod := range cache.ModuleIdMap {
+		for _, w := range mod.Webhooks {
+			if w.RelationId != relationId || !slices.Contains(webhookGetActions(w), action) {
+				continue
+			}
+
+			payload := webhookPayload{
+				Action:   action,
+				Date:     time.Now().Unix(),
+				Module:   modRel.Name,
+				Relation: rel.Name,
+				RecordId: recordId,
+				Webhook:  w.Name,
+				Data:     nil,
+			}
+
+			if w.ApiId.Valid {
+				data, matched, err := webhookGetData_tx(ctx, tx, w.ApiId.Bytes, recordId, loginId)
+				if err != nil {
+					return calls, err
+				}
+				if !matched {
+					continue
+				}
+				payload.Data = data
+			}
+
+			payloadJson, err := json.Marshal(payload)
+			if err != nil {
+				return calls, err
+			}
+			calls = append(calls, webhookCall{webhook: w, payload: payloadJson})
+		}
+	}
+	return calls, nil
+}
+
+// puts webhook calls into REST spooler
+// calls are only executed if transaction is committed
+func webhookSpool_tx(ctx context.Context, tx pgx.Tx, calls []webhookCall) error {
+	for _, c := range calls {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.rest_spool (webhook_id, method, headers,
+				url, body, date_added, skip_verify)
+			VALUES ($1, 'POST', $2, $3, $4, EXTRACT(EPOCH FROM NOW()), $5)
+		`, c.webhook.Id, map[string]string{"Content-Type": "application/json"},
+			c.webhook.Url, string(c.payload), c.webhook.SkipVerify); err != nil {
+
+			return err
+		}
+	}
+	return nil
+}
+
+// returns record data as verbose API row, false if record is not part of API result
+// example: { "0(person)":{"firstname":"Hans", ...}, "1(department)":{"name":"IT"}...}
+func webhookGetData_tx(ctx context.Context, tx pgx.Tx, apiId uuid.UUID,
+	recordId int64, loginId int64) (interface{}, bool, error) {
+
+	api, exists := cache.ApiIdMap[apiId]
+	if !exists {
+		return nil, false, handler.ErrSchemaUnknownApi(apiId)
+	}
+	rel, exists := cache.RelationIdMap[api.Query.RelationId.Bytes]
+	if !exists {
+		return nil, false, handler.ErrSchemaUnknownRelation(api.Query.RelationId.Bytes)
+	}
+	mod, exists := cache.ModuleIdMap[api.ModuleId]
+	if !exists {
+		return nil, false, handler.ErrSchemaUnknownModule(api.ModuleId)
+	}
+
+	dataGet := types.DataGet{
+		RelationId:  rel.Id,
+		IndexSource: 0,
+		Joins:       data_query.ConvertApiQueryToDataJoins(api.Query.Joins),
+		Filters: data_query.ConvertQueryToDataFilter(
+			api.Query.Filters, loginId, mod.LanguageMain, nil),
+	}
+	for _, column := range api.Columns {
+		dataGet.Expressions = append(dataGet.Expressions, data_query.ConvertColumnToExpression(
+			column, loginId, mod.LanguageMain, nil))
+	}
+
+	// API filters are put into brackets, to not be affected by the record filter
+	if len(dataGet.Filters) != 0 {
+		dataGet.Filters[0].Side0.Brackets++
+		dataGet.Filters[len(dataGet.Filters)-1].Side1.Brackets++
+	}
+	dataGet.Filters = append(dataGet.Filters, types.DataGetFilter{
+		Connector: "AND",
+		Index:     0,
+		Operator:  "=",
+		Side0: types.DataGetFilterSide{
+			AttributeId: pgtype.UUID{Bytes: rel.AttributeIdPk, Valid: true},
+		},
+		Side1: types.DataGetFilterSide{Value: recordId},
+	})
+
+	var query string
+	results, _, err := Get_tx(ctx, tx, dataGet, loginId, &query)
+	if err != nil {
+		if err.Error() == handler.ErrUnauthorized {
+			// webhook is still sent, but without data the login is not allowed to see
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	if len(results) == 0 {
+		return nil, false, nil
+	}
+
+	relIndexMapRef, colRefByColumn := data_query.GetApiVerboseRefs(api, mod.LanguageMain)
+	row := make(map[string]map[string]interface{})
+	for i, value := range results[0].Values {
+		if i >= len(api.Columns) {
+			break
+		}
+		relRef := relIndexMapRef[api.Columns[i].Index]
+		if _, exists := row[relRef]; !exists {
+			row[relRef] = make(map[string]interface{})
+		}
+		row[relRef][colRefByColumn[i]] = value
+	}
+	return row, true, nil
+}
+
+func webhookGetActions(w types.Webhook) []string {
+	actions := make([]string, 0)
+	if w.OnInsert {
+		actions = append(actions, "insert")
+	}
+	if w.OnUpdate {
+		actions = append(actions, "update")
+	}
+	if w.OnDelete {
+		actions = append(actions, "delete")
+	}
+	return actions
+}
@@ -8,10 +8,12 @@ import (
 	"r3/tools"
 	"r3/types"
 	"strconv"
+	"sync"
 	"time"
 
 	pgxuuid "github.com/jackc/pgx-gofrs-uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,6 +28,10 @@ var (
 	CtxDefTimeoutSysTask  = 30 * time.Second  // executing system tasks
 	CtxDefTimeoutSysStart = 300 * time.Second // executing system startup tasks
 	CtxDefTimeoutTransfer = 600 * time.Second // executing module transfers, to be replaced by config option
+
+	// notices (RAISE NOTICE, WARNING, ...) captured for connections, by backend process ID
+	notices_mx    = &sync.Mutex{}
+	noticesPidMap = make(map[uint32]*[]string)
 )
 
 // attempts to open a database connection
@@ -75,6 +81,15 @@ func Open(config types.FileTypeDb) error {
 		}
 	}
 
+	poolConfig.ConnConfig.OnNotice = func(con *pgconn.PgConn, n *pgconn.Notice) {
+		notices_mx.Lock()
+		defer notices_mx.Unlock()
+
+		if notices, exists := noticesPidMap[con.PID()]; exists {
+			*notices = append(*notices, fmt.Sprintf("%s: %s", n.Severity, n.Message))
+		}
+	}
+
 	poolConfig.AfterConnect = func(ctx context.Context, con *pgx.Conn) error {
 		pgxuuid.Register(con.TypeMap())
 		return err
@@ -94,6 +109,25 @@ func SetSessionConfig_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 	return err
 }
 
+// captures notices raised on connection, until returned function is called
+// returned function stops capture and returns captured notices
+func CaptureNotices(con *pgx.Conn) func() []string {
+	pid := con.PgConn().PID()
+	notices := make([]string, 0)
+
+	notices_mx.Lock()
+	noticesPidMap[pid] = &notices
+	notices_mx.Unlock()
+
+	return func() []string {
+		notices_mx.Lock()
+		defer notices_mx.Unlock()
+
+		delete(noticesPidMap, pid)
+		return notices
+	}
+}
+
 func Close() {
 	Pool.Close()
 }
@@ -101,6 +101,575 @@ var upgradeFunctions = map[string]func(ctx context.Context, tx pgx.Tx) (string,
 			TYPE app.field_flag[] USING flags::CHARACTER VARYING(12)[]::app.field_flag[];
 	*/
 
+	"4.0": func(ctx context.Context, tx pgx.Tx) (string, error) {
+		_, err := tx.Exec(ctx, `
+			-- REST API bulk calls, max. number of rows per call
+			INSERT INTO instance.config (name, value) VALUES ('apiBulkRowsMax', '1000');
+
+			-- webhooks
+			CREATE TABLE IF NOT EXISTS app.webhook (
+				id uuid NOT NULL,
+				module_id uuid NOT NULL,
+				relation_id uuid NOT NULL,
+				api_id uuid,
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				comment text COLLATE pg_catalog."default",
+				url text COLLATE pg_catalog."default" NOT NULL,
+				on_delete boolean NOT NULL,
+				on_insert boolean NOT NULL,
+				on_update boolean NOT NULL,
+				skip_verify boolean NOT NULL DEFAULT false,
+				CONSTRAINT webhook_pkey PRIMARY KEY (id),
+				CONSTRAINT webhook_module_id_fkey FOREIGN KEY (module_id)
+					REFERENCES app.module (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT webhook_relation_id_fkey FOREIGN KEY (relation_id)
+					REFERENCES app.relation (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT webhook_api_id_fkey FOREIGN KEY (api_id)
+					REFERENCES app.api (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			CREATE INDEX IF NOT EXISTS fki_webhook_module_id_fkey   ON app.webhook USING btree (module_id   ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_webhook_relation_id_fkey ON app.webhook USING btree (relation_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_webhook_api_id_fkey      ON app.webhook USING btree (api_id      ASC NULLS LAST);
+
+			-- webhook calls are delivered via REST spooler
+			ALTER TABLE instance.rest_spool ADD COLUMN webhook_id uuid;
+			ALTER TABLE instance.rest_spool ADD CONSTRAINT rest_spool_webhook_id_fkey FOREIGN KEY (webhook_id)
+				REFERENCES app.webhook (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE
+				DEFERRABLE INITIALLY DEFERRED;
+
+			CREATE INDEX IF NOT EXISTS fki_rest_spool_webhook_id_fkey
+				ON instance.rest_spool USING btree (webhook_id ASC NULLS LAST);
+
+			-- REST spooler, per call retry options with exponential backoff, success status codes and failure details
+			ALTER TABLE instance.rest_spool ADD COLUMN attempt_max integer NOT NULL DEFAULT 5;
+			ALTER TABLE instance.rest_spool ADD COLUMN backoff_base integer NOT NULL DEFAULT 60;
+			ALTER TABLE instance.rest_spool ADD COLUMN status_success text NOT NULL DEFAULT '200-299';
+			ALTER TABLE instance.rest_spool ADD COLUMN date_attempt bigint;
+			ALTER TABLE instance.rest_spool ADD COLUMN date_next bigint NOT NULL DEFAULT 0;
+			ALTER TABLE instance.rest_spool ADD COLUMN last_error text;
+			ALTER TABLE instance.rest_spool ADD COLUMN last_status integer;
+			ALTER TABLE instance.rest_spool ADD COLUMN last_response text;
+
+			DROP FUNCTION instance.rest_call(TEXT, TEXT, TEXT, JSONB, BOOLEAN, UUID, TEXT);
+			CREATE OR REPLACE FUNCTION instance.rest_call(http_method TEXT, url TEXT, body TEXT, headers JSONB DEFAULT NULL, tls_skip_verify BOOLEAN DEFAULT FALSE, callback_function_id UUID DEFAULT NULL, callback_value TEXT DEFAULT NULL, attempts INTEGER DEFAULT 5, backoff_seconds INTEGER DEFAULT 60, success_codes TEXT DEFAULT '200-299')
+				RETURNS integer
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+				DECLARE
+				BEGIN
+					INSERT INTO instance.rest_spool(pg_function_id_callback, method, headers, url, body, date_added,
+						skip_verify, callback_value, attempt_max, backoff_base, status_success)
+					VALUES (callback_function_id, http_method::instance.rest_method, headers, url, body, EXTRACT(EPOCH FROM NOW()),
+						tls_skip_verify, callback_value, attempts, backoff_seconds, success_codes);
+					
+					RETURN 0;
+				END;
+			$BODY$;
+
+			-- webhook calls that exceeded their allowed attempts
+			CREATE OR REPLACE VIEW instance.webhook_dead_letter AS
+				SELECT s.id, s.webhook_id, w.module_id, w.relation_id, w.name,
+					s.url, s.body, s.date_added, s.date_attempt, s.attempt_count,
+					s.last_error, s.last_status, s.last_response
+				FROM instance.rest_spool AS s
+				JOIN app.webhook         AS w ON w.id = s.webhook_id
+				WHERE s.attempt_count >= s.attempt_max;
+
+			-- secret for signing webhook calls
+			INSERT INTO instance.config (name, value)
+			VALUES ('webhookSecret', REPLACE(gen_random_uuid()::TEXT || gen_random_uuid()::TEXT, '-', ''));
+
+			-- idempotency keys for REST API POST calls
+			CREATE TABLE IF NOT EXISTS instance.api_idempotency (
+				login_id integer NOT NULL,
+				api_id uuid NOT NULL,
+				key character varying(255) COLLATE pg_catalog."default" NOT NULL,
+				request_hash text COLLATE pg_catalog."default" NOT NULL,
+				response_code integer,
+				response_body text COLLATE pg_catalog."default",
+				date_created bigint NOT NULL,
+				CONSTRAINT api_idempotency_pkey PRIMARY KEY (login_id, api_id, key),
+				CONSTRAINT api_idempotency_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT api_idempotency_api_id_fkey FOREIGN KEY (api_id)
+					REFERENCES app.api (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			CREATE INDEX IF NOT EXISTS fki_api_idempotency_api_id_fkey
+				ON instance.api_idempotency USING btree (api_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_api_idempotency_date_created
+				ON instance.api_idempotency USING btree (date_created ASC NULLS LAST);
+
+			INSERT INTO instance.config (name, value) VALUES ('apiIdempotencyHours', '24');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupApiIdempotency',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupApiIdempotency',0,0);
+
+			-- API keys, bound to logins with explicit scopes
+			CREATE TABLE IF NOT EXISTS instance.login_api_key (
+				id uuid NOT NULL,
+				login_id integer NOT NULL,
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				prefix character varying(12) COLLATE pg_catalog."default" NOT NULL,
+				hash character(64) COLLATE pg_catalog."default" NOT NULL,
+				active boolean NOT NULL,
+				read_only boolean NOT NULL,
+				ip_allow text[] NOT NULL,
+				date_create bigint NOT NULL,
+				date_expiry bigint,
+				date_used bigint,
+				ip_used text COLLATE pg_catalog."default",
+				CONSTRAINT login_api_key_pkey PRIMARY KEY (id),
+				CONSTRAINT login_api_key_hash_key UNIQUE (hash),
+				CONSTRAINT login_api_key_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_login_api_key_login_id_fkey
+				ON instance.login_api_key USING btree (login_id ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.login_api_key_api (
+				login_api_key_id uuid NOT NULL,
+				api_id uuid NOT NULL,
+				CONSTRAINT login_api_key_api_pkey PRIMARY KEY (login_api_key_id, api_id),
+				CONSTRAINT login_api_key_api_login_api_key_id_fkey FOREIGN KEY (login_api_key_id)
+					REFERENCES instance.login_api_key (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT login_api_key_api_api_id_fkey FOREIGN KEY (api_id)
+					REFERENCES app.api (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_login_api_key_api_api_id_fkey
+				ON instance.login_api_key_api USING btree (api_id ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.login_api_key_module (
+				login_api_key_id uuid NOT NULL,
+				module_id uuid NOT NULL,
+				CONSTRAINT login_api_key_module_pkey PRIMARY KEY (login_api_key_id, module_id),
+				CONSTRAINT login_api_key_module_login_api_key_id_fkey FOREIGN KEY (login_api_key_id)
+					REFERENCES instance.login_api_key (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT login_api_key_module_module_id_fkey FOREIGN KEY (module_id)
+					REFERENCES app.module (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_login_api_key_module_module_id_fkey
+				ON instance.login_api_key_module USING btree (module_id ASC NULLS LAST);
+
+			-- REST API rate limits, token buckets shared by cluster nodes
+			CREATE TABLE IF NOT EXISTS instance_cluster.api_rate (
+				kind character varying(8) COLLATE pg_catalog."default" NOT NULL,
+				entity text COLLATE pg_catalog."default" NOT NULL,
+				tokens double precision NOT NULL,
+				allowed boolean NOT NULL,
+				count_allowed bigint NOT NULL,
+				count_denied bigint NOT NULL,
+				date_start bigint NOT NULL,
+				date_update bigint NOT NULL,
+				CONSTRAINT api_rate_pkey PRIMARY KEY (kind, entity)
+			);
+			CREATE INDEX IF NOT EXISTS ind_api_rate_date_update
+				ON instance_cluster.api_rate USING btree (date_update ASC NULLS LAST);
+
+			INSERT INTO instance.config (name, value) VALUES
+				('apiRateKeepDays', '7'),
+				('apiRateLimitApi', '0'),
+				('apiRateLimitLogin', '0'),
+				('apiRateLimitNode', '0'),
+				('apiRateLimitsCustom', '{}');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupApiRates',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupApiRates',0,0);
+
+			-- data log actions, deletions are logged with snapshot of last values
+			CREATE TYPE instance.data_log_action AS ENUM ('insert','update','delete','undelete');
+			ALTER TABLE instance.data_log ADD COLUMN action instance.data_log_action NOT NULL DEFAULT 'update';
+			ALTER TABLE instance.data_log ALTER COLUMN action DROP DEFAULT;
+			CREATE INDEX IF NOT EXISTS ind_data_log_action
+				ON instance.data_log USING btree (action ASC NULLS LAST);
+
+			-- column date buckets & window functions
+			CREATE TYPE app.column_date_bucket AS ENUM ('hour','day','week','month','quarter','year');
+			CREATE TYPE app.column_window AS ENUM ('denseRank','lag','lead','percentRank','rank','rowNumber',
+				'runningAvg','runningCount','runningMax','runningMin','runningSum');
+			ALTER TABLE app.column ADD COLUMN date_bucket app.column_date_bucket;
+			ALTER TABLE app.column ADD COLUMN window_function app.column_window;
+			ALTER TABLE app.column ADD COLUMN window_offset integer NOT NULL DEFAULT 0;
+			ALTER TABLE app.column ALTER COLUMN window_offset DROP DEFAULT;
+			ALTER TABLE app.column ADD COLUMN window_partition smallint[] NOT NULL DEFAULT '{}';
+			ALTER TABLE app.column ALTER COLUMN window_partition DROP DEFAULT;
+
+			CREATE TABLE IF NOT EXISTS app.column_window_order (
+				column_id uuid NOT NULL,
+				"position" smallint NOT NULL,
+				column_pos smallint NOT NULL,
+				ascending boolean NOT NULL,
+				CONSTRAINT column_window_order_pkey PRIMARY KEY (column_id, "position"),
+				CONSTRAINT column_window_order_column_id_fkey FOREIGN KEY (column_id)
+					REFERENCES app."column" (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- query grouping sets
+			CREATE TYPE app.query_grouping AS ENUM ('cube','rollup','sets');
+			ALTER TABLE app.query ADD COLUMN "grouping" app.query_grouping;
+
+			CREATE TABLE IF NOT EXISTS app.query_grouping_set (
+				query_id uuid NOT NULL,
+				"position" smallint NOT NULL,
+				column_positions smallint[] NOT NULL,
+				CONSTRAINT query_grouping_set_pkey PRIMARY KEY (query_id, "position"),
+				CONSTRAINT query_grouping_set_query_id_fkey FOREIGN KEY (query_id)
+					REFERENCES app.query (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- slow query log, data requests exceeding configured duration
+			CREATE TYPE instance.log_slow_query_action AS ENUM ('get','set','del');
+			CREATE TABLE IF NOT EXISTS instance.log_slow_query (
+				id uuid NOT NULL DEFAULT gen_random_uuid(),
+				action instance.log_slow_query_action NOT NULL,
+				login_id integer,
+				relation_ids uuid[] NOT NULL,
+				query_id uuid,
+				api_id uuid,
+				duration_ms integer NOT NULL,
+				date_milli bigint NOT NULL,
+				node_id uuid,
+				sql text COLLATE pg_catalog."default",
+				CONSTRAINT log_slow_query_pkey PRIMARY KEY (id),
+				CONSTRAINT log_slow_query_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE SET NULL
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_log_slow_query_login_id_fkey
+				ON instance.log_slow_query USING btree (login_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_log_slow_query_date_milli
+				ON instance.log_slow_query USING btree (date_milli DESC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_log_slow_query_duration_ms
+				ON instance.log_slow_query USING btree (duration_ms DESC NULLS LAST);
+
+			INSERT INTO instance.config (name, value) VALUES
+				('slowQueryKeepDays', '30'),
+				('slowQueryThresholdMs', '0');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupSlowQueries',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupSlowQueries',0,0);
+
+			-- soft delete (trash bin) for relations
+			ALTER TABLE app.relation ADD COLUMN soft_delete boolean NOT NULL DEFAULT false;
+			ALTER TABLE app.relation ALTER COLUMN soft_delete DROP DEFAULT;
+			ALTER TABLE app.relation ADD COLUMN soft_delete_days integer;
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupTrash',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupTrash',0,0);
+
+			-- record locking for concurrent editing
+			CREATE TYPE app.relation_record_lock AS ENUM ('optimistic','pessimistic');
+			ALTER TABLE app.relation ADD COLUMN record_lock app.relation_record_lock;
+
+			CREATE TABLE IF NOT EXISTS instance.data_lock (
+				relation_id uuid NOT NULL,
+				record_id bigint NOT NULL,
+				login_id integer NOT NULL,
+				client_id uuid NOT NULL,
+				node_id uuid NOT NULL,
+				date_lock bigint NOT NULL,
+				CONSTRAINT data_lock_pkey PRIMARY KEY (relation_id, record_id),
+				CONSTRAINT data_lock_relation_id_fkey FOREIGN KEY (relation_id)
+					REFERENCES app.relation (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_lock_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_lock_node_id_fkey FOREIGN KEY (node_id)
+					REFERENCES instance_cluster.node (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS ind_data_lock_client_id
+				ON instance.data_lock USING btree (client_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_data_lock_login_id_fkey
+				ON instance.data_lock USING btree (login_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_data_lock_node_id_fkey
+				ON instance.data_lock USING btree (node_id ASC NULLS LAST);
+
+			ALTER TYPE instance_cluster.node_event_content ADD VALUE 'recordLockChanged';
+
+			-- live data change notifications
+			ALTER TYPE instance_cluster.node_event_content ADD VALUE 'dataChanged';
+
+			-- generated attributes
+			ALTER TABLE app.attribute ADD COLUMN generated TEXT;
+
+			-- task run history, alerts for failing or long running tasks
+			ALTER TABLE instance.schedule ADD COLUMN alert_fail_count integer NOT NULL DEFAULT 0;
+			ALTER TABLE instance.schedule ADD COLUMN alert_duration integer NOT NULL DEFAULT 0;
+
+			CREATE TABLE IF NOT EXISTS instance.schedule_run (
+				id bigserial NOT NULL,
+				schedule_id integer NOT NULL,
+				node_id uuid,
+				date_start bigint NOT NULL,
+				date_end bigint,
+				duration_ms integer,
+				success boolean,
+				error text COLLATE pg_catalog."default",
+				output text COLLATE pg_catalog."default",
+				CONSTRAINT schedule_run_pkey PRIMARY KEY (id),
+				CONSTRAINT schedule_run_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT schedule_run_node_id_fkey FOREIGN KEY (node_id)
+					REFERENCES instance_cluster.node (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_schedule_run_schedule_id_fkey
+				ON instance.schedule_run USING btree (schedule_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_schedule_run_node_id_fkey
+				ON instance.schedule_run USING btree (node_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_schedule_run_date_start
+				ON instance.schedule_run USING btree (date_start DESC NULLS LAST);
+
+			INSERT INTO instance.config (name, value) VALUES
+				('taskAlertWebhookUrl', ''),
+				('taskRunsKeepDays', '30');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupTaskRuns',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupTaskRuns',0,0);
+
+			-- PG function schedules with cron expressions, time zones and blackout windows
+			ALTER TYPE app.pg_function_schedule_interval ADD VALUE 'cron';
+			ALTER TABLE app.pg_function_schedule
+				ADD COLUMN cron TEXT,
+				ADD COLUMN time_zone TEXT;
+
+			CREATE TABLE IF NOT EXISTS app.pg_function_schedule_blackout (
+				pg_function_schedule_id uuid NOT NULL,
+				position smallint NOT NULL,
+				date_from bigint NOT NULL,
+				date_to bigint NOT NULL,
+				yearly boolean NOT NULL,
+				CONSTRAINT pg_function_schedule_blackout_pkey PRIMARY KEY (pg_function_schedule_id, position),
+				CONSTRAINT pg_function_schedule_blackout_pg_function_schedule_id_fkey FOREIGN KEY (pg_function_schedule_id)
+					REFERENCES app.pg_function_schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- task dependencies, schedules triggered by successful completion of upstream schedules
+			CREATE TABLE IF NOT EXISTS instance.schedule_dependency (
+				schedule_id integer NOT NULL,
+				schedule_id_upstream integer NOT NULL,
+				CONSTRAINT schedule_dependency_pkey PRIMARY KEY (schedule_id, schedule_id_upstream),
+				CONSTRAINT schedule_dependency_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT schedule_dependency_schedule_id_upstream_fkey FOREIGN KEY (schedule_id_upstream)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_schedule_dependency_schedule_id_upstream_fkey
+				ON instance.schedule_dependency USING btree (schedule_id_upstream ASC NULLS LAST);
+
+			ALTER TABLE instance.schedule_run ADD COLUMN skipped BOOLEAN NOT NULL DEFAULT FALSE;
+			
+			-- manual task runs
+			CREATE TABLE IF NOT EXISTS instance.schedule_role (
+				schedule_id integer NOT NULL,
+				role_id uuid NOT NULL,
+				CONSTRAINT schedule_role_pkey PRIMARY KEY (schedule_id, role_id),
+				CONSTRAINT schedule_role_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT schedule_role_role_id_fkey FOREIGN KEY (role_id)
+					REFERENCES app.role (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_schedule_role_role_id_fkey
+				ON instance.schedule_role USING btree (role_id ASC NULLS LAST);
+			
+			ALTER TABLE instance.schedule_run ADD COLUMN login_id integer;
+			ALTER TABLE instance.schedule_run ADD COLUMN args TEXT;
+			ALTER TABLE instance.schedule_run ADD CONSTRAINT schedule_run_login_id_fkey FOREIGN KEY (login_id)
+				REFERENCES instance.login (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE SET NULL
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX IF NOT EXISTS fki_schedule_run_login_id_fkey
+				ON instance.schedule_run USING btree (login_id ASC NULLS LAST);
+
+			-- job queue, jobs are claimed and executed by all cluster nodes
+			CREATE TABLE IF NOT EXISTS instance.job_queue (
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				concurrency_max integer NOT NULL DEFAULT 2,
+				attempt_max integer NOT NULL DEFAULT 5,
+				backoff_base integer NOT NULL DEFAULT 30,
+				timeout integer NOT NULL DEFAULT 240,
+				CONSTRAINT job_queue_pkey PRIMARY KEY (name)
+			);
+			INSERT INTO instance.job_queue (name) VALUES ('default');
+
+			CREATE TYPE instance.job_state AS ENUM ('waiting','running','done','failed');
+			CREATE TABLE IF NOT EXISTS instance.job (
+				id bigserial NOT NULL,
+				queue_name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				pg_function_id uuid NOT NULL,
+				args jsonb NOT NULL DEFAULT '[]',
+				state instance.job_state NOT NULL DEFAULT 'waiting',
+				attempt_count integer NOT NULL DEFAULT 0,
+				date_added bigint NOT NULL,
+				date_next bigint NOT NULL,
+				date_start bigint,
+				date_end bigint,
+				node_id uuid,
+				last_error text COLLATE pg_catalog."default",
+				CONSTRAINT job_pkey PRIMARY KEY (id),
+				CONSTRAINT job_queue_name_fkey FOREIGN KEY (queue_name)
+					REFERENCES instance.job_queue (name) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT job_pg_function_id_fkey FOREIGN KEY (pg_function_id)
+					REFERENCES app.pg_function (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT job_node_id_fkey FOREIGN KEY (node_id)
+					REFERENCES instance_cluster.node (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_job_queue_name_fkey
+				ON instance.job USING btree (queue_name ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_job_pg_function_id_fkey
+				ON instance.job USING btree (pg_function_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_job_node_id_fkey
+				ON instance.job USING btree (node_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_job_claim
+				ON instance.job USING btree (queue_name ASC NULLS LAST, state ASC NULLS LAST, date_next ASC NULLS LAST);
+
+			CREATE OR REPLACE FUNCTION instance.job_enqueue(function_id UUID, args JSONB DEFAULT '[]', run_at BIGINT DEFAULT NULL, queue TEXT DEFAULT 'default')
+				RETURNS bigint
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+				DECLARE
+					job_id BIGINT;
+				BEGIN
+					IF JSONB_TYPEOF(args) IS DISTINCT FROM 'array' THEN
+						RAISE EXCEPTION 'job arguments must be a JSON array';
+					END IF;
+					IF NOT EXISTS (SELECT id FROM app.pg_function WHERE id = function_id AND is_trigger = FALSE) THEN
+						RAISE EXCEPTION 'unknown job function "%"', function_id;
+					END IF;
+					IF NOT EXISTS (SELECT name FROM instance.job_queue WHERE name = queue) THEN
+						RAISE EXCEPTION 'unknown job queue "%"', queue;
+					END IF;
+					
+					INSERT INTO instance.job (queue_name, pg_function_id, args, date_added, date_next)
+					VALUES (queue, function_id, args, EXTRACT(EPOCH FROM NOW()), COALESCE(run_at, EXTRACT(EPOCH FROM NOW())))
+					RETURNING id INTO job_id;
+					
+					RETURN job_id;
+				END;
+			$BODY$;
+
+			INSERT INTO instance.config (name,value) VALUES ('jobsKeepDays', '7');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES
+				('cleanupJobs',86400,true,false,false,true),
+				('jobRun',5,false,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupJobs',0,0), ('jobRun',0,0);
+		`)
+		return "4.1", err
+	},
 	"3.11": func(ctx context.Context, tx pgx.Tx) (string, error) {
 		// No database changes needed for 3.11 -> 4.0 upgrade
 		// This upgrade function exists to provide a path from 3.11 to 4.0
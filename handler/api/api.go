@@ -1,10 +1,12 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"r3/bruteforce"
 	"r3/cache"
@@ -15,6 +17,7 @@ import (
 	"r3/db"
 	"r3/handler"
 	"r3/log"
+	"r3/login/login_apiKey"
 	"r3/login/login_auth"
 	"r3/schema"
 	"r3/types"
@@ -24,11 +27,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const (
+	apiGroupingKey     = "grouping" // key of grouping bit mask in verbose rows, if API query uses grouping
+	apiStreamFlushRows = 500        // rows written before response is flushed in streaming mode
+)
+
 var (
-	defaultGetters = []string{"limit", "offset", "verbose"}
+	defaultGetters = []string{"bestEffort", "envelope", "limit", "offset", "verbose"}
+	rxRelIndexName = regexp.MustCompile(`\(.+\)`)
 )
 
 func Handler(w http.ResponseWriter, r *http.Request) {
@@ -55,45 +66,90 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	defer ctxCanc()
 
-	// authenticate via token
-	login, err := login_auth.Token(ctx, token)
+	// authenticate via API key or token
+	// API keys are limited to their scopes, checked once the API is known
+	var apiKey *types.LoginApiKey
+	var login types.LoginAuthResult
+	var err error
+	if strings.HasPrefix(token, login_apiKey.Prefix) {
+		var key types.LoginApiKey
+		host, _ := bruteforce.GetHost(r)
+		login, key, err = login_auth.ApiKey(ctx, token, host)
+		apiKey = &key
+	} else {
+		login, err = login_auth.Token(ctx, token)
+	}
 	if err != nil {
 		abort(http.StatusUnauthorized, err, handler.ErrUnauthorized)
 		bruteforce.BadAttempt(r)
 		return
 	}
 
-	var isDelete, isGet, isPost bool
+	var isDelete, isGet, isPatch, isPost, isPut bool
 	switch r.Method {
 	case "DELETE":
 		isDelete = true
 	case "GET":
 		isGet = true
+	case "PATCH":
+		isPatch = true
 	case "POST":
 		isPost = true
+	case "PUT":
+		isPut = true
 	default:
 		abort(http.StatusBadRequest, nil, "invalid HTTP method")
 		return
 	}
 
+	// GET results can be streamed as newline delimited JSON, one row per line
+	isStream := isGet && strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+
 	/*
 		Parse URL, such as:
 		GET    /api/lsw_invoices/contracts/v1?limit=10
+		GET    /api/lsw_invoices/contracts/v1?limit=10&cursor=eyJhIjoi...
 		GET    /api/lsw_invoices/contracts/v1/45
 		DELETE /api/lsw_invoices/contracts/v1/45
+		PATCH  /api/lsw_invoices/contracts/v1/45
+		POST   /api/lsw_invoices/contracts/v1/bulk?bestEffort=1
+		DELETE /api/lsw_invoices/contracts/v1/bulk
+		GET    /api/lsw_invoices/contracts/v1/openapi.json
+		GET    /api/openapi.json
 
 		Rules:
 		Path must contain 5-6 elements (see examples above, split by '/')
-		6th element is the record ID, required by DELETE
+		6th element is the record ID, required by DELETE, PATCH and PUT
 		GET can also have record ID (single record lookup)
+		POST and DELETE can process multiple rows/record IDs at once (6th element: 'bulk')
+		GET can also request the OpenAPI specification of a single API or of all accessible APIs (3 elements)
 	*/
 	elements := strings.Split(r.URL.Path, "/")
-	recordIdProvided := len(elements) == 6
+	isOpenApi := len(elements) == 6 && elements[5] == "openapi.json"
+	isOpenApiIndex := len(elements) == 3 && elements[2] == "openapi.json"
+	isBulk := len(elements) == 6 && elements[5] == "bulk"
+	recordIdProvided := len(elements) == 6 && !isOpenApi && !isBulk
+	recordIdRequired := (isDelete && !isBulk) || isPatch || isPut
+
+	if (isOpenApi || isOpenApiIndex) && !isGet {
+		abort(http.StatusBadRequest, nil, "OpenAPI specification is only available via GET")
+		return
+	}
+	if isBulk && !isDelete && !isPost {
+		abort(http.StatusBadRequest, nil, "bulk mode is only available via POST and DELETE")
+		return
+	}
+	if isOpenApiIndex {
+		if err := writeOpenApiIndex(w, login.Id, login.LanguageCode, apiKey); err != nil {
+			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		}
+		return
+	}
 
-	if len(elements) < 5 || len(elements) > 6 || (isDelete && !recordIdProvided) {
+	if len(elements) < 5 || len(elements) > 6 || (recordIdRequired && !recordIdProvided) {
 
 		examplePostfix := ""
-		if isDelete {
+		if recordIdRequired {
 			examplePostfix = "/RECORD_ID"
 		}
 		abort(http.StatusBadRequest, nil, fmt.Sprintf("invalid URL, expected: /api/APP_NAME/API_NAME/VERSION%s", examplePostfix))
@@ -111,7 +167,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var recordId int64
-	if len(elements) == 6 {
+	if recordIdProvided {
 		recordId, err = strconv.ParseInt(elements[5], 10, 64)
 		if err != nil {
 			abort(http.StatusBadRequest, err, fmt.Sprintf("invalid API record ID '%s', integer expected", elements[5]))
@@ -135,9 +191,10 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	api := cache.ApiIdMap[apiId]
 
 	// check supported API methods
+	// PATCH/PUT update existing records, available if POST is enabled (updates are limited by join settings)
 	if (isDelete && !api.HasDelete) ||
 		(isGet && !api.HasGet) ||
-		(isPost && !api.HasPost) {
+		((isPatch || isPost || isPut) && !api.HasPost) {
 		abort(http.StatusBadRequest, nil, fmt.Sprintf("HTTP method '%s' is not supported by this API", r.Method))
 		return
 	}
@@ -158,13 +215,53 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// check API key scope and mode
+	if apiKey != nil {
+		if !login_apiKey.IsInScope(*apiKey, api) {
+			abort(http.StatusForbidden, nil, "API key is not valid for this API")
+			return
+		}
+		if apiKey.ReadOnly && !isGet {
+			abort(http.StatusForbidden, nil, "API key is read-only")
+			return
+		}
+	}
+
+	// check rate limits
+	retryAfter, err := rateCheck(ctx, login.Id, api.Id)
+	if err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	if retryAfter != 0 {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+		abort(http.StatusTooManyRequests, nil, "rate limit exceeded")
+		return
+	}
+
+	if isOpenApi {
+		doc := getOpenApiDoc([]uuid.UUID{api.Id}, fmt.Sprintf("%s.%s (v%d)", modName, apiName, version),
+			fmt.Sprintf("%d", version), login.LanguageCode)
+
+		if err := writeOpenApiDoc(w, doc); err != nil {
+			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		}
+		return
+	}
+
 	// parse URL getters
 	var getters struct {
-		limit   int
-		offset  int
-		verbose bool
-
-		filters map[string]string
+		bestEffort bool
+		cursor     string
+		cursorUsed bool
+		envelope   bool
+		limit      int
+		offset     int
+		verbose    bool
+
+		fieldFilters []fieldFilter // filters on API columns, defined by consumer
+		filters      map[string]string
+		sort         string // sorting by API columns, defined by consumer
 	}
 	getters.filters = make(map[string]string)
 	getters.limit = api.LimitDef
@@ -176,7 +273,11 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		if slices.Contains(defaultGetters, getter) {
+		if getter == "cursor" {
+			// cursor paging, empty cursor starts at first row
+			getters.cursor = values[0]
+			getters.cursorUsed = true
+		} else if slices.Contains(defaultGetters, getter) {
 			// default getters
 			n, err := strconv.Atoi(values[0])
 			if err != nil {
@@ -184,6 +285,10 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			switch getter {
+			case "bestEffort":
+				getters.bestEffort = n == 1
+			case "envelope":
+				getters.envelope = n == 1
 			case "limit":
 				getters.limit = n
 			case "offset":
@@ -191,6 +296,14 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			case "verbose":
 				getters.verbose = n == 1
 			}
+		} else if getter == "sort" {
+			getters.sort = values[0]
+		} else if f, isFieldFilter, err := parseFieldFilterGetter(getter, values[0]); isFieldFilter {
+			if err != nil {
+				abort(http.StatusBadRequest, err, err.Error())
+				return
+			}
+			getters.fieldFilters = append(getters.fieldFilters, f)
 		} else {
 			if isGet {
 				// filter getters, only relevant for GET calls
@@ -207,95 +320,97 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// execute request
-	tx, err := db.Pool.Begin(ctx)
+	txDb, err := db.Pool.Begin(ctx)
 	if err != nil {
 		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 		return
 	}
-	defer tx.Rollback(ctx)
+	defer txDb.Rollback(ctx)
+
+	// statements of write calls are recorded for the slow query log
+	tx := data.NewTxRecorder(txDb)
 
 	if err := db.SetSessionConfig_tx(ctx, tx, login.Id); err != nil {
 		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 		return
 	}
 
-	if isDelete {
-		if recordId < 1 {
-			abort(http.StatusBadRequest, nil, "record ID must be > 0")
+	// idempotent POST calls, replay stored response if key was already used
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	idempotencyUsed := isPost && idempotencyKey != "" && config.GetUint64("apiIdempotencyHours") != 0
+	if idempotencyUsed {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			abort(http.StatusBadRequest, err, "failed to read request body")
 			return
 		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
 
-		// look up all records from joined relations
-		// continue even if some joins do not have DELETE enabled, as its necessary for later joins that might require a DELETE
-		// joins are ordered smaller indexes first, later joined relations always have higher indexes than their partners
-		relationIndexMapRecordIds := make(map[int][]int64)
-		for _, join := range api.Query.Joins {
-			if join.Index == 0 {
-				relationIndexMapRecordIds[0] = []int64{recordId}
-				continue
-			}
+		stored, httpCode, err := idempotencyReserve_tx(ctx, tx, login.Id, api.Id,
+			idempotencyKey, getIdempotencyHash(r.URL.RequestURI(), body))
 
-			if _, exists := relationIndexMapRecordIds[join.IndexFrom]; !exists {
-				// no record on the partner relation, skip
-				continue
-			}
-
-			ids := make([]int64, 0)
-			joinAtr, exists := cache.AttributeIdMap[join.AttributeId.Bytes]
-			if !exists {
-				abort(http.StatusServiceUnavailable, nil,
-					handler.ErrSchemaUnknownAttribute(join.AttributeId.Bytes).Error())
+		if err != nil {
+			abort(httpCode, err, err.Error())
+			return
+		}
+		if stored != nil {
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.code)
+			w.Write(stored.body)
+			return
+		}
+	}
 
-				return
+	// log slow data requests, with all relations of the API query
+	var queryGet string
+	dateStart := time.Now()
+	defer func() {
+		action := "set"
+		query := tx.QueriesJoined()
+		if isGet {
+			action = "get"
+			query = queryGet
+		} else if isDelete {
+			action = "del"
+		}
+		relationIds := []uuid.UUID{api.Query.RelationId.Bytes}
+		for _, join := range api.Query.Joins {
+			if !slices.Contains(relationIds, join.RelationId) {
+				relationIds = append(relationIds, join.RelationId)
 			}
+		}
+		data.LogSlow(action, dateStart, login.Id, relationIds,
+			pgtype.UUID{}, pgtype.UUID{Bytes: api.Id, Valid: true}, query)
+	}()
 
-			var atrNameLookup, atrNameFilter string
-			var rel types.Relation
-
-			if joinAtr.RelationId == join.RelationId {
-				atrNameLookup = schema.PkName
-				atrNameFilter = joinAtr.Name
-				rel = cache.RelationIdMap[join.RelationId]
-			} else {
-				// join from other relation
-				atrNameLookup = joinAtr.Name
-				atrNameFilter = schema.PkName
-				rel = cache.RelationIdMap[joinAtr.RelationId]
-			}
-			mod := cache.ModuleIdMap[rel.ModuleId]
-
-			if err := tx.QueryRow(ctx, fmt.Sprintf(`
-				SELECT ARRAY(
-					SELECT "%s"
-					FROM "%s"."%s"
-					WHERE "%s" = ANY($1)
-					AND   "%s" IS NOT NULL -- ignore empty references
-				)
-			`, atrNameLookup, mod.Name, rel.Name, atrNameFilter, atrNameLookup),
-				relationIndexMapRecordIds[join.IndexFrom]).Scan(&ids); err != nil {
+	if isBulk {
+		results, httpCode, err := bulk_tx(ctx, tx, api, r.Body, isDelete,
+			getters.verbose, getters.bestEffort, login.Id, languageCodeModule)
 
+		if err != nil {
+			abort(httpCode, nil, err.Error())
+			return
+		}
+
+		payloadJson, err := json.Marshal(results)
+		if err != nil {
+			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+			return
+		}
+		if idempotencyUsed {
+			if err := idempotencyStore_tx(ctx, tx, login.Id, api.Id, idempotencyKey, http.StatusOK, payloadJson); err != nil {
 				abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 				return
 			}
-			relationIndexMapRecordIds[join.Index] = ids
 		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(payloadJson)
+	}
 
-		// execute delete
-		for _, join := range api.Query.Joins {
-
-			if _, exists := relationIndexMapRecordIds[join.Index]; !exists {
-				continue
-			}
-			if !join.ApplyDelete || len(relationIndexMapRecordIds[join.Index]) == 0 {
-				continue
-			}
-
-			for _, id := range relationIndexMapRecordIds[join.Index] {
-				if err := data.Del_tx(ctx, tx, join.RelationId, id, login.Id); err != nil {
-					abort(http.StatusConflict, nil, err.Error())
-					return
-				}
-			}
+	if isDelete && !isBulk {
+		if httpCode, err := delete_tx(ctx, tx, api, recordId, login.Id); err != nil {
+			abort(httpCode, nil, err.Error())
+			return
 		}
 	}
 
@@ -319,17 +434,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// resolve relation joins
-		for _, join := range api.Query.Joins {
-			if join.Index == 0 {
-				continue
-			}
-			dataGet.Joins = append(dataGet.Joins, types.DataGetJoin{
-				AttributeId: join.AttributeId.Bytes,
-				Index:       join.Index,
-				IndexFrom:   join.IndexFrom,
-				Connector:   join.Connector,
-			})
-		}
+		dataGet.Joins = data_query.ConvertApiQueryToDataJoins(api.Query.Joins)
 
 		// build expressions from columns
 		for _, column := range api.Columns {
@@ -357,156 +462,213 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			})
 		}
 
-		// apply query sorting
+		// apply query sorting & grouping
 		dataGet.Orders = data_query.ConvertQueryToDataOrders(api.Query.Orders)
+		dataGet.Grouping = api.Query.Grouping.String
+		dataGet.GroupingSets = api.Query.GroupingSets
 
-		// get data
-		var query string
-		results, _, err := data.Get_tx(ctx, tx, dataGet, login.Id, &query)
-		if err != nil {
-			if err.Error() == handler.ErrUnauthorized {
-				abort(http.StatusUnauthorized, err, handler.ErrUnauthorized)
-				return
-			}
-			abort(http.StatusServiceUnavailable, nil, err.Error())
+		// apply field filters and sorting, limited to API columns
+		if err := applyFieldFilters(api, &dataGet, getters.fieldFilters, getters.sort); err != nil {
+			abort(http.StatusBadRequest, err, err.Error())
 			return
 		}
 
-		// parse output
-		rows := make([]interface{}, 0)
-		if !getters.verbose {
-			for _, result := range results {
-				rows = append(rows, result.Values)
+		// apply cursor paging, continues after last row of previous call
+		var cursorKeys []cursorKey
+		var cursorSkipped int64
+		if getters.cursorUsed {
+			if recordId != 0 || getters.offset != 0 {
+				abort(http.StatusBadRequest, nil, "cursor cannot be combined with offset or record ID")
+				return
 			}
-		} else {
-			// prepare keys for row template object: { "0(person)":{"firstname":"Hans", ...}, "1(department)":{"name":"IT"}...}
-			relIndexMapNames := make(map[int]string)
-			colRefByColumn := make([]string, len(api.Columns))
-			subQueryCtr := 0
-			for i, column := range api.Columns {
-				atr := cache.AttributeIdMap[column.AttributeId]
-				rel := cache.RelationIdMap[atr.RelationId]
-				colRef := ""
-
-				if ref, exists := column.Captions["columnTitle"][languageCodeModule]; exists {
-					colRef = ref
-				} else {
-					if column.SubQuery {
-						colRef = fmt.Sprintf("sub_query%d", subQueryCtr)
-						subQueryCtr++
-					} else {
-						colRef = atr.Name
-					}
+			cursorKeys, cursorSkipped, err = applyCursor(api, &dataGet, getters.cursor)
+			if err != nil {
+				abort(http.StatusBadRequest, err, err.Error())
+				return
+			}
+		}
 
-					if column.Aggregator.Valid {
-						colRef = fmt.Sprintf("%s (%s)", strings.ToUpper(column.Aggregator.String), colRef)
-					}
+		// parse output row, either as value list or as verbose object
+		// verbose: { "0(person)":{"firstname":"Hans", ...}, "1(department)":{"name":"IT"}...}
+		// with grouping, the grouping bit mask is added as last value or as verbose key
+		relIndexMapRef, colRefByColumn := data_query.GetApiVerboseRefs(api, languageCodeModule)
+		var getRow = func(result types.DataGetResult) interface{} {
+			if !getters.verbose {
+				if api.Query.Grouping.Valid {
+					return append(result.Values, result.Grouping)
 				}
-				colRefByColumn[i] = colRef
+				return result.Values
+			}
+			row := make(map[string]interface{})
+			for i, value := range result.Values {
 
-				if _, exists := relIndexMapNames[column.Index]; !exists {
-					relIndexMapNames[column.Index] = rel.Name
+				relRef := relIndexMapRef[api.Columns[i].Index]
+
+				if _, exists := row[relRef]; !exists {
+					row[relRef] = make(map[string]interface{})
 				}
+
+				row[relRef].(map[string]interface{})[colRefByColumn[i]] = value
 			}
+			if api.Query.Grouping.Valid {
+				row[apiGroupingKey] = result.Grouping
+			}
+			return row
+		}
 
-			for _, result := range results {
-				row := make(map[string]map[string]interface{})
-				for i, value := range result.Values {
+		// streaming mode, rows are written as newline delimited JSON while being read from the database
+		// total count is only known after all rows are written, it is not available as header
+		if isStream {
+			if getters.cursorUsed || getters.envelope || recordId != 0 {
+				abort(http.StatusBadRequest, nil, "streaming cannot be combined with cursor, envelope or record ID")
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-ndjson")
 
-					relIndex := api.Columns[i].Index
-					relRef := fmt.Sprintf("%d(%s)", relIndex, relIndexMapNames[relIndex])
+			rc := http.NewResponseController(w)
+			enc := json.NewEncoder(w)
+			rowsWritten := 0
 
-					if _, exists := row[relRef]; !exists {
-						row[relRef] = make(map[string]interface{})
+			_, err := data.GetIter_tx(ctx, tx, dataGet, login.Id, &queryGet,
+				func(result types.DataGetResult) error {
+					if rowsWritten == 0 {
+						w.WriteHeader(http.StatusOK)
+					}
+					if err := enc.Encode(getRow(result)); err != nil {
+						return err
 					}
+					rowsWritten++
+					if rowsWritten%apiStreamFlushRows == 0 {
+						return rc.Flush()
+					}
+					return nil
+				})
 
-					row[relRef][colRefByColumn[i]] = value
+			if err != nil {
+				if rowsWritten != 0 {
+					// response already started, status cannot be changed anymore
+					log.Error(log.ContextApi, "failed to stream data", err)
+					return
+				}
+				if err.Error() == handler.ErrUnauthorized {
+					abort(http.StatusUnauthorized, err, handler.ErrUnauthorized)
+					return
 				}
-				rows = append(rows, row)
+				abort(http.StatusServiceUnavailable, nil, err.Error())
+				return
+			}
+			if rowsWritten == 0 {
+				w.WriteHeader(http.StatusOK)
 			}
+			return
 		}
 
-		payloadJson, err := json.Marshal(rows)
+		// get data
+		results, count, err := data.Get_tx(ctx, tx, dataGet, login.Id, &queryGet)
 		if err != nil {
-			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+			if err.Error() == handler.ErrUnauthorized {
+				abort(http.StatusUnauthorized, err, handler.ErrUnauthorized)
+				return
+			}
+			abort(http.StatusServiceUnavailable, nil, err.Error())
 			return
 		}
-		w.WriteHeader(http.StatusOK)
-		w.Write(payloadJson)
-	}
 
-	if isPost {
-		// check for invalid POST inputs
-		for _, column := range api.Columns {
-			if column.SubQuery {
-				abort(http.StatusBadRequest, nil, "POST does not support sub queries")
+		// total count, with cursor paging the count only includes rows after the cursor
+		countTotal := cursorSkipped + count
+
+		// offer next cursor if more rows are available
+		next := pgtype.Text{}
+		if getters.cursorUsed && len(results) != 0 && count > int64(len(results)) {
+			next.String, err = getCursorNext(api, cursorKeys, results[len(results)-1], cursorSkipped+int64(len(results)))
+			if err != nil {
+				abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 				return
 			}
+			next.Valid = true
 		}
 
-		values := make([]interface{}, len(api.Columns))
-		if !getters.verbose {
-			// non-verbose mode: values are following columns (equal count and order)
-			// [123,"Fritz","Hans"]
-			if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
-				abort(http.StatusBadRequest, err, "invalid JSON object")
-				return
+		// remove values from expressions that were only added for cursor paging
+		for i, result := range results {
+			if len(result.Values) > len(api.Columns) {
+				results[i].Values = result.Values[:len(api.Columns)]
 			}
-		} else {
-			// verbose mode structure: relation index + relation name (only for readability, optional) -> attribute name -> value
-			// convert verbose to non-verbose input (to process both inputs the same way)
-			/*{
-				"0(employee)":{ "firstname":"Hans", "age":47 },
-				"1(department)":{ "name":"IT" }
-			]*/
-			var jsonObj map[string]map[string]interface{}
-			if err := json.NewDecoder(r.Body).Decode(&jsonObj); err != nil {
-				abort(http.StatusBadRequest, err, "invalid JSON object")
+		}
+
+		// single record lookup, offer ETag for optimistic concurrency with PATCH/PUT
+		if recordId != 0 && len(results) == 1 {
+			etag, err := getEtag(api, results[0])
+			if err != nil {
+				abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 				return
 			}
+			w.Header().Set("ETag", etag)
+		}
 
-			// pre-populate values with nil, in case required attribute values are not given
-			for i, _ := range api.Columns {
-				values[i] = nil
-			}
+		// parse output
+		rows := make([]interface{}, 0)
+		for _, result := range results {
+			rows = append(rows, getRow(result))
+		}
 
-			for relStr, columnNameMapValues := range jsonObj {
+		var payload interface{} = rows
+		if getters.envelope {
+			// envelope mode, rows with total count and next cursor
+			payload = struct {
+				Count int64         `json:"count"`
+				Next  pgtype.Text   `json:"next"`
+				Rows  []interface{} `json:"rows"`
+			}{countTotal, next, rows}
+		}
+		if recordId == 0 {
+			w.Header().Set("X-Total-Count", fmt.Sprintf("%d", countTotal))
+		}
+		if next.Valid {
+			w.Header().Set("X-Next-Cursor", next.String)
+		}
 
-				// remove optional relation name and whitespace
-				relStr = strings.TrimSpace(
-					regexp.MustCompile(`\(.+\)`).ReplaceAllString(relStr, ""))
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(payloadJson)
+	}
 
-				// only the mandatory relation index number should be left
-				relIndex, err := strconv.Atoi(relStr)
-				if err != nil {
-					abort(http.StatusBadRequest, nil, fmt.Sprintf("invalid relation index '%s', integer expected", relStr))
-					return
-				}
-				for i, column := range api.Columns {
-					if column.Index != relIndex {
-						continue
-					}
+	if isPost && !isBulk {
+		indexRecordIds, httpCode, err := post_tx(ctx, tx, api, r.Body,
+			getters.verbose, login.Id, languageCodeModule)
 
-					var colRef string
-					if ref, exists := column.Captions["columnTitle"][languageCodeModule]; exists {
-						colRef = ref
-					} else {
-						colRef = cache.AttributeIdMap[column.AttributeId].Name
-					}
+		if err != nil {
+			abort(httpCode, nil, err.Error())
+			return
+		}
 
-					if value, exists := columnNameMapValues[colRef]; exists {
-						values[i] = value
-					}
-				}
+		payloadJson, err := json.Marshal(indexRecordIds)
+		if err != nil {
+			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+			return
+		}
+		if idempotencyUsed {
+			if err := idempotencyStore_tx(ctx, tx, login.Id, api.Id, idempotencyKey, http.StatusOK, payloadJson); err != nil {
+				abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+				return
 			}
 		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(payloadJson)
+	}
 
-		indexRecordIds, err := data_import.FromInterfaceValues_tx(ctx, tx,
-			login.Id, values, api.Columns, api.Query.Joins, api.Query.Lookups,
-			data_import.ResolveQueryLookups(api.Query.Joins, api.Query.Lookups))
+	if isPatch || isPut {
+		indexRecordIds, etag, httpCode, err := patch_tx(ctx, tx, api, recordId, r.Body,
+			getters.verbose, isPut, r.Header.Get("If-Match"), login.Id, languageCodeModule)
 
 		if err != nil {
-			abort(http.StatusConflict, nil, err.Error())
+			if etag != "" {
+				w.Header().Set("ETag", etag)
+			}
+			abort(httpCode, nil, err.Error())
 			return
 		}
 
@@ -515,7 +677,9 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 			return
 		}
-
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write(payloadJson)
 	}
@@ -526,3 +690,171 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// deletes record of base relation and records of joined relations (if join allows deletion)
+// returns HTTP status code in case of error
+func delete_tx(ctx context.Context, tx pgx.Tx, api types.Api, recordId int64, loginId int64) (int, error) {
+
+	if recordId < 1 {
+		return http.StatusBadRequest, errors.New("record ID must be > 0")
+	}
+
+	// look up all records from joined relations
+	// continue even if some joins do not have DELETE enabled, as its necessary for later joins that might require a DELETE
+	// joins are ordered smaller indexes first, later joined relations always have higher indexes than their partners
+	relationIndexMapRecordIds := make(map[int][]int64)
+	for _, join := range api.Query.Joins {
+		if join.Index == 0 {
+			relationIndexMapRecordIds[0] = []int64{recordId}
+			continue
+		}
+
+		if _, exists := relationIndexMapRecordIds[join.IndexFrom]; !exists {
+			// no record on the partner relation, skip
+			continue
+		}
+
+		ids := make([]int64, 0)
+		joinAtr, exists := cache.AttributeIdMap[join.AttributeId.Bytes]
+		if !exists {
+			return http.StatusServiceUnavailable, handler.ErrSchemaUnknownAttribute(join.AttributeId.Bytes)
+		}
+
+		var atrNameLookup, atrNameFilter string
+		var rel types.Relation
+
+		if joinAtr.RelationId == join.RelationId {
+			atrNameLookup = schema.PkName
+			atrNameFilter = joinAtr.Name
+			rel = cache.RelationIdMap[join.RelationId]
+		} else {
+			// join from other relation
+			atrNameLookup = joinAtr.Name
+			atrNameFilter = schema.PkName
+			rel = cache.RelationIdMap[joinAtr.RelationId]
+		}
+		mod := cache.ModuleIdMap[rel.ModuleId]
+
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT ARRAY(
+				SELECT "%s"
+				FROM "%s"."%s"
+				WHERE "%s" = ANY($1)
+				AND   "%s" IS NOT NULL -- ignore empty references
+			)
+		`, atrNameLookup, mod.Name, rel.Name, atrNameFilter, atrNameLookup),
+			relationIndexMapRecordIds[join.IndexFrom]).Scan(&ids); err != nil {
+
+			return http.StatusServiceUnavailable, err
+		}
+		relationIndexMapRecordIds[join.Index] = ids
+	}
+
+	// execute delete
+	for _, join := range api.Query.Joins {
+
+		if _, exists := relationIndexMapRecordIds[join.Index]; !exists {
+			continue
+		}
+		if !join.ApplyDelete || len(relationIndexMapRecordIds[join.Index]) == 0 {
+			continue
+		}
+
+		for _, id := range relationIndexMapRecordIds[join.Index] {
+			if err := data.Del_tx(ctx, tx, join.RelationId, id, loginId, uuid.Nil); err != nil {
+				return http.StatusConflict, err
+			}
+		}
+	}
+	return 0, nil
+}
+
+// creates or updates records of base relation and joined relations from a single row
+// returns record IDs by relation index and HTTP status code in case of error
+func post_tx(ctx context.Context, tx pgx.Tx, api types.Api, body io.Reader,
+	verbose bool, loginId int64, languageCode string) (map[int]int64, int, error) {
+
+	// check for invalid POST inputs
+	for _, column := range api.Columns {
+		if column.SubQuery {
+			return nil, http.StatusBadRequest, errors.New("POST does not support sub queries")
+		}
+	}
+
+	values := make([]interface{}, len(api.Columns))
+	if !verbose {
+		// non-verbose mode: values are following columns (equal count and order)
+		// [123,"Fritz","Hans"]
+		if err := json.NewDecoder(body).Decode(&values); err != nil {
+			return nil, http.StatusBadRequest, errors.New("invalid JSON object")
+		}
+	} else {
+		// verbose mode structure: relation index + relation name (only for readability, optional) -> attribute name -> value
+		// convert verbose to non-verbose input (to process both inputs the same way)
+		/*{
+			"0(employee)":{ "firstname":"Hans", "age":47 },
+			"1(department)":{ "name":"IT" }
+		]*/
+		var jsonObj map[string]map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&jsonObj); err != nil {
+			return nil, http.StatusBadRequest, errors.New("invalid JSON object")
+		}
+
+		// pre-populate values with nil, in case required attribute values are not given
+		for i, _ := range api.Columns {
+			values[i] = nil
+		}
+
+		for relStr, columnNameMapValues := range jsonObj {
+
+			// remove optional relation name and whitespace
+			relStr = strings.TrimSpace(rxRelIndexName.ReplaceAllString(relStr, ""))
+
+			// only the mandatory relation index number should be left
+			relIndex, err := strconv.Atoi(relStr)
+			if err != nil {
+				return nil, http.StatusBadRequest, fmt.Errorf("invalid relation index '%s', integer expected", relStr)
+			}
+			for i, column := range api.Columns {
+				if column.Index != relIndex {
+					continue
+				}
+
+				if value, exists := columnNameMapValues[getColumnRef(column, languageCode)]; exists {
+					values[i] = value
+				}
+			}
+		}
+	}
+
+	indexRecordIds, err := data_import.FromInterfaceValues_tx(ctx, tx,
+		loginId, values, api.Columns, api.Query.Joins, api.Query.Lookups,
+		data_import.ResolveQueryLookups(api.Query.Joins, api.Query.Lookups))
+
+	if err != nil {
+		return nil, http.StatusConflict, err
+	}
+	return indexRecordIds, 0, nil
+}
+
+// column reference used in verbose mode, column title if available, otherwise attribute name
+func getColumnRef(column types.Column, languageCode string) string {
+	if ref, exists := column.Captions["columnTitle"][languageCode]; exists {
+		return ref
+	}
+	return cache.AttributeIdMap[column.AttributeId].Name
+}
+
+func getRecordIdFromResult(result types.DataGetResult, index int) (int64, error) {
+	v, exists := result.IndexRecordIds[index]
+	if !exists || v == nil {
+		return 0, nil
+	}
+	switch id := v.(type) {
+	case int32:
+		return int64(id), nil
+	case int64:
+		return id, nil
+	}
+	return 0, fmt.Errorf("record ID has invalid type")
+}
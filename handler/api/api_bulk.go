@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"r3/config"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type bulkResult struct {
+	Index          int           `json:"index"`                    // position of row/record ID in request
+	IndexRecordIds map[int]int64 `json:"indexRecordIds,omitempty"` // POST: affected record IDs by relation index
+	RecordId       int64         `json:"recordId,omitempty"`       // DELETE: deleted record ID
+	Error          string        `json:"error,omitempty"`          // error message if row/record ID could not be processed
+}
+
+// processes multiple POST rows or DELETE record IDs in a single transaction
+// all-or-nothing: first failure aborts the whole request
+// best-effort: each row is applied within its own savepoint, failed rows are rolled back individually
+// row count is limited, as all rows are applied within the same transaction
+// returns results in request order and HTTP status code in case of error
+func bulk_tx(ctx context.Context, tx pgx.Tx, api types.Api, body io.Reader, isDelete bool,
+	verbose bool, bestEffort bool, loginId int64, languageCode string) ([]bulkResult, int, error) {
+
+	results := make([]bulkResult, 0)
+
+	var rows []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&rows); err != nil {
+		return results, http.StatusBadRequest, errors.New("invalid JSON array")
+	}
+	if rowsMax := config.GetUint64("apiBulkRowsMax"); rowsMax != 0 && uint64(len(rows)) > rowsMax {
+		return results, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("bulk call exceeds maximum of %d rows", rowsMax)
+	}
+
+	for i, row := range rows {
+		result := bulkResult{Index: i}
+
+		var apply = func(tx pgx.Tx) (int, error) {
+			if isDelete {
+				if err := json.Unmarshal(row, &result.RecordId); err != nil {
+					return http.StatusBadRequest, errors.New("invalid record ID, integer expected")
+				}
+				return delete_tx(ctx, tx, api, result.RecordId, loginId)
+			}
+			var httpCode int
+			var err error
+			result.IndexRecordIds, httpCode, err = post_tx(ctx, tx, api,
+				bytes.NewReader(row), verbose, loginId, languageCode)
+
+			return httpCode, err
+		}
+
+		if !bestEffort {
+			if httpCode, err := apply(tx); err != nil {
+				return results, httpCode, fmt.Errorf("row %d: %s", i, err.Error())
+			}
+			results = append(results, result)
+			continue
+		}
+
+		// savepoint, to only roll back changes of failed row
+		txRow, err := tx.Begin(ctx)
+		if err != nil {
+			return results, http.StatusServiceUnavailable, err
+		}
+		if _, err := apply(txRow); err != nil {
+			if errRb := txRow.Rollback(ctx); errRb != nil {
+				return results, http.StatusServiceUnavailable, errRb
+			}
+			result.IndexRecordIds = nil
+			result.Error = err.Error()
+		} else {
+			if err := txRow.Commit(ctx); err != nil {
+				return results, http.StatusServiceUnavailable, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, 0, nil
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cursor paging (keyset pagination)
+// results are ordered by query orders and record IDs of all relation indexes (as unique tie breaker)
+// a cursor contains the order values of the last returned row, the next call continues after these values
+// unlike offsets, cursors do not skip or duplicate rows if records are added or removed between calls
+
+// opaque cursor, base64 encoded JSON
+type cursor struct {
+	ApiId   uuid.UUID     `json:"a"` // API the cursor was created for
+	Skipped int64         `json:"s"` // number of rows returned before cursor (to calculate total count)
+	Values  []interface{} `json:"v"` // order values of last returned row, same order as cursor keys
+}
+
+// value to order by, either attribute value or record ID of relation index
+type cursorKey struct {
+	attributeId   uuid.UUID
+	ascending     bool
+	expressionPos int // position of expression to read value from (-1 if record ID)
+	index         int // relation index
+}
+
+// prepares data GET for cursor paging: adds orders, expressions required for cursor values and filters from cursor
+// empty cursor value starts at the first row
+// returns cursor keys and number of rows already returned before cursor
+func applyCursor(api types.Api, dataGet *types.DataGet, cursorValue string) ([]cursorKey, int64, error) {
+	keys := make([]cursorKey, 0)
+
+	for _, column := range api.Columns {
+		if column.Aggregator.Valid || column.GroupBy {
+			return keys, 0, errors.New("cursor paging is not supported for APIs with aggregated columns")
+		}
+	}
+
+	// query orders, use column expression if available, otherwise add expression to retrieve order value
+	for _, ord := range dataGet.Orders {
+		atr, exists := cache.AttributeIdMap[ord.AttributeId.Bytes]
+		if !exists {
+			return keys, 0, handler.ErrSchemaUnknownAttribute(ord.AttributeId.Bytes)
+		}
+		if atr.Encrypted || atr.Content == "files" || atr.Content == "regconfig" {
+			return keys, 0, fmt.Errorf("cursor paging is not supported for API sorted by attribute '%s'", atr.Name)
+		}
+
+		expressionPos := -1
+		for i, column := range api.Columns {
+			if !column.SubQuery && column.AttributeId == atr.Id && column.Index == int(ord.Index.Int32) {
+				expressionPos = i
+				break
+			}
+		}
+		if expressionPos == -1 {
+			expressionPos = len(dataGet.Expressions)
+			dataGet.Expressions = append(dataGet.Expressions, types.DataGetExpression{
+				AttributeId: pgtype.UUID{Bytes: atr.Id, Valid: true},
+				Index:       int(ord.Index.Int32),
+			})
+		}
+		keys = append(keys, cursorKey{
+			attributeId:   atr.Id,
+			ascending:     ord.Ascending,
+			expressionPos: expressionPos,
+			index:         int(ord.Index.Int32),
+		})
+	}
+
+	// record IDs of all relation indexes as tie breaker, base relation first
+	for _, join := range api.Query.Joins {
+		rel, exists := cache.RelationIdMap[join.RelationId]
+		if !exists {
+			return keys, 0, handler.ErrSchemaUnknownRelation(join.RelationId)
+		}
+		dataGet.Orders = append(dataGet.Orders, types.DataGetOrder{
+			AttributeId: pgtype.UUID{Bytes: rel.AttributeIdPk, Valid: true},
+			Index:       pgtype.Int4{Int32: int32(join.Index), Valid: true},
+			Ascending:   true,
+		})
+		keys = append(keys, cursorKey{
+			attributeId:   rel.AttributeIdPk,
+			ascending:     true,
+			expressionPos: -1,
+			index:         join.Index,
+		})
+	}
+
+	if cursorValue == "" {
+		return keys, 0, nil
+	}
+
+	// parse cursor
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(cursorValue)
+	if err != nil {
+		return keys, 0, errors.New("invalid cursor")
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || c.ApiId != api.Id || len(c.Values) != len(keys) {
+		return keys, 0, errors.New("invalid cursor")
+	}
+
+	// numbers are passed as text, to be cast by the database to the attribute type
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			c.Values[i] = n.String()
+		}
+	}
+
+	// keyset filter: (k0 after v0) OR (k0 = v0 AND k1 after v1) OR ...
+	terms := make([][]types.DataGetFilter, 0)
+	for i, key := range keys {
+		if c.Values[i] == nil {
+			// NULL values are sorted last, nothing comes after NULL for this key
+			continue
+		}
+
+		term := make([][]types.DataGetFilter, 0)
+		for j := 0; j < i; j++ {
+			if c.Values[j] == nil {
+				term = append(term, []types.DataGetFilter{getCursorFilter(keys[j], "IS NULL", nil)})
+			} else {
+				term = append(term, []types.DataGetFilter{getCursorFilter(keys[j], "=", c.Values[j])})
+			}
+		}
+
+		operator := ">"
+		if !key.ascending {
+			operator = "<"
+		}
+		term = append(term, getFilterGroup([][]types.DataGetFilter{
+			{getCursorFilter(key, operator, c.Values[i])},
+			{getCursorFilter(key, "IS NULL", nil)},
+		}, "OR"))
+
+		terms = append(terms, getFilterGroup(term, "AND"))
+	}
+	if len(terms) == 0 {
+		return keys, 0, errors.New("invalid cursor")
+	}
+
+	// separate existing filters from cursor filters
+	filters := make([]types.DataGetFilter, 0)
+	for _, f := range dataGet.Filters {
+		if f.Index == 0 {
+			filters = append(filters, f)
+		}
+	}
+	if len(filters) != 0 {
+		dataGet.Filters = slices.DeleteFunc(dataGet.Filters, func(f types.DataGetFilter) bool { return f.Index == 0 })
+		dataGet.Filters = append(dataGet.Filters, getFilterGroup([][]types.DataGetFilter{filters}, "AND")...)
+	}
+	dataGet.Filters = append(dataGet.Filters, getFilterGroup([][]types.DataGetFilter{getFilterGroup(terms, "OR")}, "AND")...)
+
+	return keys, c.Skipped, nil
+}
+
+// returns cursor pointing after given result row
+func getCursorNext(api types.Api, keys []cursorKey, result types.DataGetResult, skipped int64) (string, error) {
+	c := cursor{
+		ApiId:   api.Id,
+		Skipped: skipped,
+		Values:  make([]interface{}, 0),
+	}
+	for _, key := range keys {
+		var value interface{}
+		if key.expressionPos == -1 {
+			value = result.IndexRecordIds[key.index]
+		} else if key.expressionPos < len(result.Values) {
+			value = result.Values[key.expressionPos]
+		}
+
+		// use text representation for values that are not native JSON types
+		switch v := value.(type) {
+		case [16]uint8:
+			value = uuid.FromBytesOrNil(v[:]).String()
+		case pgtype.Numeric:
+			n, err := v.Value()
+			if err != nil {
+				return "", err
+			}
+			value = n
+		}
+		c.Values = append(c.Values, value)
+	}
+
+	j, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(j), nil
+}
+
+func getCursorFilter(key cursorKey, operator string, value interface{}) types.DataGetFilter {
+	return types.DataGetFilter{
+		Connector: "AND",
+		Index:     0,
+		Operator:  operator,
+		Side0: types.DataGetFilterSide{
+			AttributeId:    pgtype.UUID{Bytes: key.attributeId, Valid: true},
+			AttributeIndex: key.index,
+		},
+		Side1: types.DataGetFilterSide{Value: value},
+	}
+}
+
+// combines filter groups with connector, each group is put into brackets
+func getFilterGroup(groups [][]types.DataGetFilter, connector string) []types.DataGetFilter {
+	out := make([]types.DataGetFilter, 0)
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		group = slices.Clone(group)
+		group[0].Side0.Brackets++
+		group[len(group)-1].Side1.Brackets++
+
+		if len(out) == 0 {
+			group[0].Connector = "AND"
+		} else {
+			group[0].Connector = connector
+		}
+		out = append(out, group...)
+	}
+	return out
+}
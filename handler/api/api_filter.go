@@ -0,0 +1,260 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/types"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// field filters and sorting for GET calls, defined by the API consumer
+// syntax: ?filter[amount][gte]=100&filter[1.name][like]=smith&sort=-date,amount
+// columns are referred to by attribute name, optionally prefixed with relation index ('1.name')
+// only plain attribute columns of the API can be used, field filters are added to the API query filters
+
+var (
+	rxFieldFilter = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([a-z]+)\])?$`)
+
+	// field filter operators, mapped to data filter operators
+	fieldFilterOperators = map[string]string{
+		"eq":      "=",
+		"ne":      "<>",
+		"gt":      ">",
+		"gte":     ">=",
+		"lt":      "<",
+		"lte":     "<=",
+		"like":    "ILIKE",
+		"in":      "= ANY",
+		"nin":     "<> ALL",
+		"between": "",
+		"null":    "",
+	}
+)
+
+type fieldFilter struct {
+	column   string // column reference (attribute name, optionally with relation index)
+	operator string // field filter operator (eq, gte, ...)
+	value    string // filter value, as in getter
+}
+
+// returns column references that can be used for field filters and sorting, mapped to API column positions
+// references with relation index are always available, plain attribute names if they are unique or from index 0
+func getFieldColumnRefs(api types.Api) map[string]int {
+	refs := make(map[string]int)
+	namesByCount := make(map[string]int)
+
+	for i, column := range api.Columns {
+		if column.SubQuery || column.Aggregator.Valid || column.DateBucket.Valid || column.Window.Valid {
+			continue
+		}
+		atr, exists := cache.AttributeIdMap[column.AttributeId]
+		if !exists || atr.Encrypted || atr.Content == "files" {
+			continue
+		}
+		refs[fmt.Sprintf("%d.%s", column.Index, atr.Name)] = i
+		namesByCount[atr.Name]++
+	}
+	for i, column := range api.Columns {
+		atr := cache.AttributeIdMap[column.AttributeId]
+		if _, exists := refs[fmt.Sprintf("%d.%s", column.Index, atr.Name)]; !exists {
+			continue
+		}
+		if namesByCount[atr.Name] == 1 || column.Index == 0 {
+			refs[atr.Name] = i
+		}
+	}
+	return refs
+}
+
+// adds field filters and sorting to data GET request
+// field filters are added to existing filters (AND), sorting takes precedence over query sorting
+func applyFieldFilters(api types.Api, dataGet *types.DataGet, filters []fieldFilter, sort string) error {
+
+	if len(filters) == 0 && sort == "" {
+		return nil
+	}
+	refs := getFieldColumnRefs(api)
+
+	var getColumn = func(ref string) (types.Column, types.Attribute, error) {
+		pos, exists := refs[ref]
+		if !exists {
+			return types.Column{}, types.Attribute{}, fmt.Errorf("column '%s' does not exist or cannot be used for filtering/sorting", ref)
+		}
+		column := api.Columns[pos]
+		return column, cache.AttributeIdMap[column.AttributeId], nil
+	}
+
+	// filters
+	if len(filters) != 0 && len(dataGet.Filters) != 0 {
+		// keep existing filters together, in case they include OR connectors
+		dataGet.Filters[0].Side0.Brackets++
+		dataGet.Filters[len(dataGet.Filters)-1].Side1.Brackets++
+	}
+	for _, f := range filters {
+		column, atr, err := getColumn(f.column)
+		if err != nil {
+			return err
+		}
+
+		var addFilter = func(operator string, value interface{}) {
+			dataGet.Filters = append(dataGet.Filters, types.DataGetFilter{
+				Connector: "AND",
+				Index:     0,
+				Operator:  operator,
+				Side0: types.DataGetFilterSide{
+					AttributeId:    pgtype.UUID{Bytes: atr.Id, Valid: true},
+					AttributeIndex: column.Index,
+				},
+				Side1: types.DataGetFilterSide{Value: value},
+			})
+		}
+
+		switch f.operator {
+		case "between":
+			values := strings.Split(f.value, ",")
+			if len(values) != 2 {
+				return fmt.Errorf("filter operator 'between' requires 2 comma separated values for column '%s'", f.column)
+			}
+			for i, operator := range []string{">=", "<="} {
+				value, err := getFieldFilterValue(atr, values[i])
+				if err != nil {
+					return err
+				}
+				addFilter(operator, value)
+			}
+		case "in", "nin":
+			values, err := getFieldFilterValues(atr, strings.Split(f.value, ","))
+			if err != nil {
+				return err
+			}
+			addFilter(fieldFilterOperators[f.operator], values)
+		case "null":
+			switch f.value {
+			case "1", "true":
+				addFilter("IS NULL", nil)
+			case "0", "false":
+				addFilter("IS NOT NULL", nil)
+			default:
+				return fmt.Errorf("filter operator 'null' requires value 1 or 0 for column '%s'", f.column)
+			}
+		case "like":
+			addFilter(fieldFilterOperators[f.operator], f.value)
+		default:
+			value, err := getFieldFilterValue(atr, f.value)
+			if err != nil {
+				return err
+			}
+			addFilter(fieldFilterOperators[f.operator], value)
+		}
+	}
+
+	// sorting
+	if sort != "" {
+		orders := make([]types.DataGetOrder, 0)
+		for _, ref := range strings.Split(sort, ",") {
+			ascending := !strings.HasPrefix(ref, "-")
+
+			column, atr, err := getColumn(strings.TrimPrefix(ref, "-"))
+			if err != nil {
+				return err
+			}
+			orders = append(orders, types.DataGetOrder{
+				AttributeId: pgtype.UUID{Bytes: atr.Id, Valid: true},
+				Index:       pgtype.Int4{Int32: int32(column.Index), Valid: true},
+				Ascending:   ascending,
+			})
+		}
+		dataGet.Orders = append(orders, dataGet.Orders...)
+	}
+	return nil
+}
+
+// parses filter value from getter based on attribute content
+func getFieldFilterValue(atr types.Attribute, value string) (interface{}, error) {
+	var err error
+	var v interface{}
+
+	switch atr.Content {
+	case "integer", "bigint":
+		v, err = strconv.ParseInt(value, 10, 64)
+	case "numeric", "real", "double precision":
+		v, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		v, err = strconv.ParseBool(value)
+	default:
+		v = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter value '%s' for column '%s'", value, atr.Name)
+	}
+	return v, nil
+}
+
+// parses multiple filter values, returns typed slice as required for array operators
+func getFieldFilterValues(atr types.Attribute, values []string) (interface{}, error) {
+	var err error
+	var intValues []int64
+	var floatValues []float64
+	var boolValues []bool
+
+	for _, value := range values {
+		var v interface{}
+		if v, err = getFieldFilterValue(atr, value); err != nil {
+			return nil, err
+		}
+		switch t := v.(type) {
+		case int64:
+			intValues = append(intValues, t)
+		case float64:
+			floatValues = append(floatValues, t)
+		case bool:
+			boolValues = append(boolValues, t)
+		}
+	}
+
+	switch atr.Content {
+	case "integer", "bigint":
+		return intValues, nil
+	case "numeric", "real", "double precision":
+		return floatValues, nil
+	case "boolean":
+		return boolValues, nil
+	}
+	return values, nil
+}
+
+// parses field filter getter, returns false if getter is not a field filter
+func parseFieldFilterGetter(getter string, value string) (fieldFilter, bool, error) {
+	matches := rxFieldFilter.FindStringSubmatch(getter)
+	if len(matches) != 3 {
+		return fieldFilter{}, false, nil
+	}
+
+	operator := matches[2]
+	if operator == "" {
+		operator = "eq"
+	}
+	if _, exists := fieldFilterOperators[operator]; !exists {
+		return fieldFilter{}, true, fmt.Errorf("invalid filter operator '%s', valid: %s",
+			operator, strings.Join(getFieldFilterOperatorNames(), ", "))
+	}
+	if value == "" && operator != "eq" && operator != "ne" && operator != "like" {
+		return fieldFilter{}, true, errors.New("empty filter value")
+	}
+	return fieldFilter{matches[1], operator, value}, true, nil
+}
+
+func getFieldFilterOperatorNames() []string {
+	names := make([]string, 0)
+	for name := range fieldFilterOperators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
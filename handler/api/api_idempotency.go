@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"r3/config"
+	"r3/tools"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// idempotency keys for POST calls
+// the first successful response is stored per login, API and key for a configurable time window
+// repeated calls with the same key return the stored response instead of executing the request again
+// keys are reserved within the request transaction, concurrent calls with the same key wait for the first one
+// failed calls roll back their reservation, the key can then be used again
+
+const idempotencyKeyLengthMax = 255
+
+// stored response of a previous call
+type idempotencyResponse struct {
+	code int
+	body []byte
+}
+
+// returns hash over request URI and body to detect key reuse for different requests
+func getIdempotencyHash(requestUri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(requestUri))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// reserves idempotency key for current request
+// returns stored response if key was already used for the same request (replay)
+// returns HTTP status code in case of error
+func idempotencyReserve_tx(ctx context.Context, tx pgx.Tx, loginId int64, apiId uuid.UUID,
+	key string, requestHash string) (*idempotencyResponse, int, error) {
+
+	if len(key) > idempotencyKeyLengthMax {
+		return nil, http.StatusBadRequest, errors.New("idempotency key must not exceed 255 characters")
+	}
+
+	now := tools.GetTimeUnix()
+	dateExpired := now - (int64(config.GetUint64("apiIdempotencyHours")) * 3600)
+
+	// remove expired key, so that it can be used again
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.api_idempotency
+		WHERE login_id     = $1
+		AND   api_id       = $2
+		AND   key          = $3
+		AND   date_created < $4
+	`, loginId, apiId, key, dateExpired); err != nil {
+		return nil, http.StatusServiceUnavailable, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO instance.api_idempotency (login_id, api_id, key, request_hash, date_created)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT DO NOTHING
+	`, loginId, apiId, key, requestHash, now)
+	if err != nil {
+		return nil, http.StatusServiceUnavailable, err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, http.StatusOK, nil
+	}
+
+	// key was already used
+	var hashStored string
+	var code pgtype.Int4
+	var body pgtype.Text
+	if err := tx.QueryRow(ctx, `
+		SELECT request_hash, response_code, response_body
+		FROM instance.api_idempotency
+		WHERE login_id = $1
+		AND   api_id   = $2
+		AND   key      = $3
+	`, loginId, apiId, key).Scan(&hashStored, &code, &body); err != nil {
+		return nil, http.StatusServiceUnavailable, err
+	}
+
+	if hashStored != requestHash {
+		return nil, http.StatusUnprocessableEntity, errors.New("idempotency key was already used for a different request")
+	}
+	if !code.Valid {
+		return nil, http.StatusConflict, errors.New("request with this idempotency key is still being processed")
+	}
+	return &idempotencyResponse{int(code.Int32), []byte(body.String)}, http.StatusOK, nil
+}
+
+// stores response for reserved idempotency key
+func idempotencyStore_tx(ctx context.Context, tx pgx.Tx, loginId int64, apiId uuid.UUID,
+	key string, code int, body []byte) error {
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.api_idempotency
+		SET response_code = $4, response_body = $5
+		WHERE login_id = $1
+		AND   api_id   = $2
+		AND   key      = $3
+	`, loginId, apiId, key, code, string(body))
+	return err
+}
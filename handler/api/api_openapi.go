@@ -0,0 +1,650 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"r3/cache"
+	"r3/config"
+	"r3/data/data_query"
+	"r3/login/login_apiKey"
+	"r3/schema"
+	"r3/types"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// OpenAPI 3 specification, generated from API definitions in schema cache
+// only elements used by this generator are defined
+type openApiDoc struct {
+	OpenApi    string                          `json:"openapi"`
+	Info       openApiInfo                     `json:"info"`
+	Servers    []openApiServer                 `json:"servers"`
+	Paths      map[string]map[string]openApiOp `json:"paths"`
+	Components openApiComponents               `json:"components"`
+	Security   []map[string][]string           `json:"security"`
+}
+type openApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+type openApiServer struct {
+	Url string `json:"url"`
+}
+type openApiComponents struct {
+	Schemas         map[string]*openApiSchema        `json:"schemas"`
+	SecuritySchemes map[string]openApiSecurityScheme `json:"securitySchemes"`
+}
+type openApiSecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+type openApiOp struct {
+	Summary     string                     `json:"summary"`
+	OperationId string                     `json:"operationId"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openApiParameter         `json:"parameters,omitempty"`
+	RequestBody *openApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openApiResponse `json:"responses"`
+	Security    *[]map[string][]string     `json:"security,omitempty"` // overwrites global security if set
+}
+type openApiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"` // path, query, header
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required"`
+	Style       string         `json:"style,omitempty"` // deepObject for field filters
+	Explode     bool           `json:"explode,omitempty"`
+	Schema      *openApiSchema `json:"schema"`
+}
+type openApiRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openApiMediaType `json:"content"`
+}
+type openApiResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]openApiHeader    `json:"headers,omitempty"`
+	Content     map[string]openApiMediaType `json:"content,omitempty"`
+}
+type openApiHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *openApiSchema `json:"schema"`
+}
+type openApiMediaType struct {
+	Schema *openApiSchema `json:"schema"`
+}
+type openApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	MaxLength            int                       `json:"maxLength,omitempty"`
+	MinItems             int                       `json:"minItems,omitempty"`
+	MaxItems             int                       `json:"maxItems,omitempty"`
+	Items                *openApiSchema            `json:"items,omitempty"`
+	Properties           map[string]*openApiSchema `json:"properties,omitempty"`
+	AdditionalProperties *openApiSchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*openApiSchema          `json:"oneOf,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+}
+
+// writes OpenAPI document for all APIs the login has access to
+// API key is optional, if used only APIs within its scopes are included
+func writeOpenApiIndex(w http.ResponseWriter, loginId int64, languageCode string, apiKey *types.LoginApiKey) error {
+
+	access, err := cache.GetAccessById(loginId)
+	if err != nil {
+		return err
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	apiIds := make([]uuid.UUID, 0)
+	for apiId := range access.Api {
+		api, exists := cache.ApiIdMap[apiId]
+		if !exists || (apiKey != nil && !login_apiKey.IsInScope(*apiKey, api)) {
+			continue
+		}
+		apiIds = append(apiIds, apiId)
+	}
+
+	// stable order: module name, API name, API version
+	sort.Slice(apiIds, func(i, j int) bool {
+		a0, a1 := cache.ApiIdMap[apiIds[i]], cache.ApiIdMap[apiIds[j]]
+		m0, m1 := cache.ModuleIdMap[a0.ModuleId].Name, cache.ModuleIdMap[a1.ModuleId].Name
+		if m0 != m1 {
+			return m0 < m1
+		}
+		if a0.Name != a1.Name {
+			return a0.Name < a1.Name
+		}
+		return a0.Version < a1.Version
+	})
+
+	appName, _ := config.GetAppName()
+	return writeOpenApiDoc(w, getOpenApiDoc(apiIds, fmt.Sprintf("%s APIs", appName),
+		config.GetAppVersion().Full, languageCode))
+}
+
+func writeOpenApiDoc(w http.ResponseWriter, doc openApiDoc) error {
+	payloadJson, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(payloadJson)
+	return nil
+}
+
+// creates OpenAPI document for all given APIs
+// schema cache must be read locked
+func getOpenApiDoc(apiIds []uuid.UUID, title string, version string, languageCode string) openApiDoc {
+	appName, _ := config.GetAppName()
+
+	doc := openApiDoc{
+		OpenApi: "3.0.3",
+		Info: openApiInfo{
+			Title:       title,
+			Description: fmt.Sprintf("REST APIs served by %s", appName),
+			Version:     version,
+		},
+		Servers: []openApiServer{{Url: fmt.Sprintf("https://%s", config.GetString("publicHostName"))}},
+		Paths: map[string]map[string]openApiOp{
+			"/api/auth": {
+				"post": {
+					Summary:     "Authenticate with username and password to receive an access token",
+					OperationId: "auth",
+					RequestBody: &openApiRequestBody{
+						Required: true,
+						Content: map[string]openApiMediaType{"application/json": {Schema: &openApiSchema{
+							Type: "object",
+							Properties: map[string]*openApiSchema{
+								"username": {Type: "string"},
+								"password": {Type: "string"},
+							},
+						}}},
+					},
+					Responses: map[string]openApiResponse{
+						"200": {Description: "Access token", Content: map[string]openApiMediaType{"application/json": {Schema: &openApiSchema{
+							Type:       "object",
+							Properties: map[string]*openApiSchema{"token": {Type: "string"}},
+						}}}},
+						"401": getOpenApiResponseError("Authentication failed"),
+					},
+					Security: &[]map[string][]string{},
+				},
+			},
+		},
+		Components: openApiComponents{
+			Schemas: map[string]*openApiSchema{
+				"bulkResult": {
+					Type:        "object",
+					Description: "Result of a single row (POST) or record ID (DELETE) in bulk mode",
+					Properties: map[string]*openApiSchema{
+						"index":          {Type: "integer", Description: "Position in request"},
+						"indexRecordIds": {Ref: getOpenApiRef("indexRecordIds")},
+						"recordId":       {Type: "integer", Format: "int64", Description: "Deleted record ID"},
+						"error":          {Type: "string", Description: "Error message, if row or record ID could not be applied (best-effort mode)"},
+					},
+				},
+				"error": {
+					Type:       "object",
+					Properties: map[string]*openApiSchema{"error": {Type: "string"}},
+				},
+				"indexRecordIds": {
+					Type:                 "object",
+					Description:          "Affected record IDs by relation index",
+					AdditionalProperties: &openApiSchema{Type: "integer", Format: "int64"},
+				},
+			},
+			SecuritySchemes: map[string]openApiSecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+
+	for _, apiId := range apiIds {
+		api, exists := cache.ApiIdMap[apiId]
+		if !exists || !api.Query.RelationId.Valid {
+			continue
+		}
+		mod, exists := cache.ModuleIdMap[api.ModuleId]
+		if !exists {
+			continue
+		}
+		addOpenApiPaths(&doc, api, mod, getOpenApiLanguageCode(mod, languageCode))
+	}
+	return doc
+}
+
+// adds paths and component schemas of a single API to the given OpenAPI document
+func addOpenApiPaths(doc *openApiDoc, api types.Api, mod types.Module, languageCode string) {
+
+	ref := fmt.Sprintf("%s.%s.v%d", mod.Name, api.Name, api.Version)
+	pathBase := fmt.Sprintf("/api/%s/%s/v%d", mod.Name, api.Name, api.Version)
+	pathRecord := fmt.Sprintf("%s/{recordId}", pathBase)
+	pathBulk := fmt.Sprintf("%s/bulk", pathBase)
+	tags := []string{fmt.Sprintf("%s.%s", mod.Name, api.Name)}
+
+	// component schemas
+	refRowVerbose := fmt.Sprintf("%s.rowVerbose", ref)
+	refRowNonVerbose := fmt.Sprintf("%s.rowNonVerbose", ref)
+	refSetVerbose := fmt.Sprintf("%s.setVerbose", ref)
+	refSetNonVerbose := fmt.Sprintf("%s.setNonVerbose", ref)
+	refPatchNonVerbose := fmt.Sprintf("%s.patchNonVerbose", ref)
+
+	relIndexMapRef, colRefByColumn := data_query.GetApiVerboseRefs(api, languageCode)
+	rowVerbose := &openApiSchema{Type: "object", Properties: make(map[string]*openApiSchema)}
+	rowNonVerbose := &openApiSchema{Type: "array", MinItems: len(api.Columns), MaxItems: len(api.Columns),
+		Description: "Column values in column order", Items: &openApiSchema{OneOf: make([]*openApiSchema, 0)}}
+
+	setVerbose := &openApiSchema{Type: "object", Properties: make(map[string]*openApiSchema)}
+	setNonVerbose := &openApiSchema{Type: "array", MinItems: len(api.Columns), MaxItems: len(api.Columns),
+		Description: "Column values in column order", Items: &openApiSchema{OneOf: make([]*openApiSchema, 0)}}
+	patchNonVerbose := &openApiSchema{Type: "object", Description: "Column values by column position",
+		Properties: make(map[string]*openApiSchema)}
+
+	for i, column := range api.Columns {
+		relRef := relIndexMapRef[column.Index]
+		valueSchema := getOpenApiColumnSchema(api, column, languageCode)
+
+		if _, exists := rowVerbose.Properties[relRef]; !exists {
+			rowVerbose.Properties[relRef] = &openApiSchema{Type: "object", Properties: make(map[string]*openApiSchema)}
+		}
+		rowVerbose.Properties[relRef].Properties[colRefByColumn[i]] = valueSchema
+		rowNonVerbose.Items.OneOf = append(rowNonVerbose.Items.OneOf, valueSchema)
+
+		// sub queries can only be retrieved
+		if column.SubQuery {
+			continue
+		}
+		if _, exists := setVerbose.Properties[relRef]; !exists {
+			setVerbose.Properties[relRef] = &openApiSchema{Type: "object", Properties: make(map[string]*openApiSchema)}
+		}
+		setVerbose.Properties[relRef].Properties[getColumnRef(column, languageCode)] = valueSchema
+		setNonVerbose.Items.OneOf = append(setNonVerbose.Items.OneOf, valueSchema)
+		patchNonVerbose.Properties[fmt.Sprintf("%d", i)] = valueSchema
+	}
+	if api.Query.Grouping.Valid {
+		groupingSchema := &openApiSchema{Type: "integer", Format: "int32",
+			Description: "Grouping bit mask, bit is set for each GROUP BY column that is aggregated over (bit 0: first GROUP BY column)"}
+
+		rowVerbose.Properties[apiGroupingKey] = groupingSchema
+		rowNonVerbose.Items.OneOf = append(rowNonVerbose.Items.OneOf, groupingSchema)
+		rowNonVerbose.MinItems++
+		rowNonVerbose.MaxItems++
+		rowNonVerbose.Description = "Column values in column order, followed by grouping bit mask"
+	}
+	doc.Components.Schemas[refRowVerbose] = rowVerbose
+	doc.Components.Schemas[refRowNonVerbose] = rowNonVerbose
+	doc.Components.Schemas[refSetVerbose] = setVerbose
+	doc.Components.Schemas[refSetNonVerbose] = setNonVerbose
+	doc.Components.Schemas[refPatchNonVerbose] = patchNonVerbose
+
+	// parameters
+	paramVerbose := openApiParameter{
+		Name:        "verbose",
+		In:          "query",
+		Description: "Verbose mode (1) uses relation and column names, non-verbose mode (0) uses column positions",
+		Schema:      &openApiSchema{Type: "integer", Default: getOpenApiBoolInt(api.VerboseDef)},
+	}
+	paramRecordId := openApiParameter{
+		Name:     "recordId",
+		In:       "path",
+		Required: true,
+		Schema:   &openApiSchema{Type: "integer", Format: "int64"},
+	}
+	paramsGet := []openApiParameter{
+		{Name: "limit", In: "query", Schema: &openApiSchema{Type: "integer", Default: api.LimitDef},
+			Description: fmt.Sprintf("Max. number of results, up to %d", api.LimitMax)},
+		{Name: "offset", In: "query", Schema: &openApiSchema{Type: "integer", Default: 0}},
+		{Name: "envelope", In: "query", Schema: &openApiSchema{Type: "integer", Default: 0},
+			Description: "Envelope mode (1) returns an object with total count, next cursor and result rows"},
+		paramVerbose,
+	}
+	if refs := getFieldColumnRefs(api); len(refs) != 0 {
+		columns := slices.Sorted(maps.Keys(refs))
+		paramsGet = append(paramsGet, openApiParameter{
+			Name:  "filter",
+			In:    "query",
+			Style: "deepObject",
+			Description: fmt.Sprintf("Field filters, as filter[COLUMN][OPERATOR]=VALUE (e. g. filter[%s][gte]=100); operators: %s; columns: %s",
+				columns[0], strings.Join(getFieldFilterOperatorNames(), ", "), strings.Join(columns, ", ")),
+			Explode: true,
+			Schema:  &openApiSchema{Type: "object"},
+		}, openApiParameter{
+			Name:        "sort",
+			In:          "query",
+			Description: fmt.Sprintf("Comma separated columns to sort by, prefixed with '-' for descending order; columns: %s", strings.Join(columns, ", ")),
+			Schema:      &openApiSchema{Type: "string"},
+		})
+	}
+	for _, getter := range getApiFilterGetters(api) {
+		paramsGet = append(paramsGet, openApiParameter{
+			Name:        getter,
+			In:          "query",
+			Description: "Filter value",
+			Schema:      &openApiSchema{Type: "string"},
+		})
+	}
+
+	rowsSchema := &openApiSchema{Type: "array", Items: &openApiSchema{OneOf: []*openApiSchema{
+		{Ref: getOpenApiRef(refRowNonVerbose)},
+		{Ref: getOpenApiRef(refRowVerbose)},
+	}}}
+	rowsOrEnvelopeSchema := &openApiSchema{OneOf: []*openApiSchema{rowsSchema, {
+		Type: "object",
+		Properties: map[string]*openApiSchema{
+			"count": {Type: "integer", Format: "int64", Description: "Total number of results"},
+			"next":  {Type: "string", Nullable: true, Description: "Cursor for the next page, if more results are available"},
+			"rows":  rowsSchema,
+		},
+	}}}
+	responsesSet := map[string]openApiResponse{
+		"200": {Description: "Affected record IDs", Content: map[string]openApiMediaType{"application/json": {
+			Schema: &openApiSchema{Ref: getOpenApiRef("indexRecordIds")}}}},
+		"400": getOpenApiResponseError("Invalid request"),
+		"401": getOpenApiResponseError("Unauthorized"),
+		"403": getOpenApiResponseError("No access to API"),
+		"409": getOpenApiResponseError("Request could not be applied"),
+	}
+
+	paramBestEffort := openApiParameter{
+		Name:        "bestEffort",
+		In:          "query",
+		Description: "Best-effort mode (1) applies all valid rows, all-or-nothing mode (0) aborts on the first invalid row",
+		Schema:      &openApiSchema{Type: "integer", Default: 0},
+	}
+	responsesBulk := map[string]openApiResponse{
+		"200": {Description: "Results in request order", Content: map[string]openApiMediaType{"application/json": {
+			Schema: &openApiSchema{Type: "array", Items: &openApiSchema{Ref: getOpenApiRef("bulkResult")}}}}},
+		"400": getOpenApiResponseError("Invalid request"),
+		"401": getOpenApiResponseError("Unauthorized"),
+		"403": getOpenApiResponseError("No access to API"),
+		"409": getOpenApiResponseError("Row could not be applied (all-or-nothing mode)"),
+	}
+
+	opsBase := make(map[string]openApiOp)
+	opsBulk := make(map[string]openApiOp)
+	opsRecord := make(map[string]openApiOp)
+
+	if api.HasGet {
+		responsesGet := map[string]openApiResponse{
+			"200": {Description: "Result rows, non-verbose or verbose", Content: map[string]openApiMediaType{"application/json": {Schema: rowsOrEnvelopeSchema}}},
+			"400": getOpenApiResponseError("Invalid request"),
+			"401": getOpenApiResponseError("Unauthorized"),
+			"403": getOpenApiResponseError("No access to API"),
+		}
+		responsesGetList := maps.Clone(responsesGet)
+		responsesGetList["200"] = openApiResponse{
+			Description: responsesGet["200"].Description,
+			Content: map[string]openApiMediaType{
+				"application/json": responsesGet["200"].Content["application/json"],
+
+				// streaming mode, requested via Accept header, one row per line
+				"application/x-ndjson": {Schema: rowsSchema.Items},
+			},
+			Headers: map[string]openApiHeader{
+				"X-Total-Count": {Description: "Total number of results", Schema: &openApiSchema{Type: "integer", Format: "int64"}},
+				"X-Next-Cursor": {Description: "Cursor for the next page, if cursor paging is used and more results are available", Schema: &openApiSchema{Type: "string"}},
+			},
+		}
+		paramCursor := openApiParameter{
+			Name:        "cursor",
+			In:          "query",
+			Description: "Cursor paging, empty value for first page, returned next cursor for following pages; cannot be combined with offset",
+			Schema:      &openApiSchema{Type: "string"},
+		}
+		opsBase["get"] = openApiOp{
+			Summary:     fmt.Sprintf("Get records of API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.get", ref),
+			Tags:        tags,
+			Parameters:  append([]openApiParameter{paramCursor}, paramsGet...),
+			Responses:   responsesGetList,
+		}
+		opsRecord["get"] = openApiOp{
+			Summary:     fmt.Sprintf("Get single record of API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.getRecord", ref),
+			Tags:        tags,
+			Parameters:  append([]openApiParameter{paramRecordId}, paramsGet...),
+			Responses:   responsesGet,
+		}
+	}
+	if api.HasPost {
+		opsBase["post"] = openApiOp{
+			Summary:     fmt.Sprintf("Create or update records via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.post", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramVerbose},
+			RequestBody: getOpenApiRequestBody(refSetNonVerbose, refSetVerbose),
+			Responses:   responsesSet,
+		}
+		opsBulk["post"] = openApiOp{
+			Summary:     fmt.Sprintf("Create or update multiple records via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.postBulk", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramVerbose, paramBestEffort},
+			RequestBody: &openApiRequestBody{Required: true, Content: map[string]openApiMediaType{"application/json": {
+				Schema: &openApiSchema{Type: "array", Items: &openApiSchema{OneOf: []*openApiSchema{
+					{Ref: getOpenApiRef(refSetNonVerbose)},
+					{Ref: getOpenApiRef(refSetVerbose)},
+				}}}}}},
+			Responses: responsesBulk,
+		}
+
+		paramIfMatch := openApiParameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "ETag of record, as returned by single record GET, to reject update if record was changed",
+			Schema:      &openApiSchema{Type: "string"},
+		}
+		responsesPatch := make(map[string]openApiResponse)
+		for k, v := range responsesSet {
+			responsesPatch[k] = v
+		}
+		responsesPatch["404"] = getOpenApiResponseError("Record does not exist")
+		responsesPatch["412"] = getOpenApiResponseError("Record was changed, ETag does not match")
+
+		opsRecord["patch"] = openApiOp{
+			Summary:     fmt.Sprintf("Update supplied column values of record via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.patch", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramRecordId, paramVerbose, paramIfMatch},
+			RequestBody: getOpenApiRequestBody(refPatchNonVerbose, refSetVerbose),
+			Responses:   responsesPatch,
+		}
+		opsRecord["put"] = openApiOp{
+			Summary:     fmt.Sprintf("Update all column values of record via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.put", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramRecordId, paramVerbose, paramIfMatch},
+			RequestBody: getOpenApiRequestBody(refPatchNonVerbose, refSetVerbose),
+			Responses:   responsesPatch,
+		}
+	}
+	if api.HasDelete {
+		opsRecord["delete"] = openApiOp{
+			Summary:     fmt.Sprintf("Delete record via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.delete", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramRecordId},
+			Responses: map[string]openApiResponse{
+				"200": {Description: "Record deleted"},
+				"400": getOpenApiResponseError("Invalid request"),
+				"401": getOpenApiResponseError("Unauthorized"),
+				"403": getOpenApiResponseError("No access to API"),
+				"409": getOpenApiResponseError("Record could not be deleted"),
+			},
+		}
+		opsBulk["delete"] = openApiOp{
+			Summary:     fmt.Sprintf("Delete multiple records via API %s (v%d)", api.Name, api.Version),
+			OperationId: fmt.Sprintf("%s.deleteBulk", ref),
+			Tags:        tags,
+			Parameters:  []openApiParameter{paramBestEffort},
+			RequestBody: &openApiRequestBody{Required: true, Content: map[string]openApiMediaType{"application/json": {
+				Schema: &openApiSchema{Type: "array", Items: &openApiSchema{Type: "integer", Format: "int64"}}}}},
+			Responses: responsesBulk,
+		}
+	}
+
+	if len(opsBase) != 0 {
+		doc.Paths[pathBase] = opsBase
+	}
+	if len(opsBulk) != 0 {
+		doc.Paths[pathBulk] = opsBulk
+	}
+	if len(opsRecord) != 0 {
+		doc.Paths[pathRecord] = opsRecord
+	}
+}
+
+// value schema of a single API column
+func getOpenApiColumnSchema(api types.Api, column types.Column, languageCode string) *openApiSchema {
+	atr, exists := cache.AttributeIdMap[column.AttributeId]
+	if !exists {
+		return &openApiSchema{}
+	}
+
+	s := getOpenApiAttributeSchema(atr)
+	s.Description = atr.Name
+	if title, exists := atr.Captions["attributeTitle"][languageCode]; exists && title != "" {
+		s.Description = title
+	}
+
+	// values can be NULL if attribute is nullable, from a joined relation or from a sub query
+	s.Nullable = atr.Nullable || column.Index != 0 || column.SubQuery
+
+	if !column.Aggregator.Valid {
+		return s
+	}
+
+	switch column.Aggregator.String {
+	case "array", "json":
+		return &openApiSchema{Type: "array", Items: s, Nullable: true, Description: s.Description}
+	case "avg":
+		return &openApiSchema{Type: "number", Nullable: true, Description: s.Description}
+	case "count":
+		return &openApiSchema{Type: "integer", Format: "int64", Description: s.Description}
+	case "list":
+		return &openApiSchema{Type: "string", Nullable: true, Description: s.Description}
+	}
+	return s
+}
+
+func getOpenApiAttributeSchema(atr types.Attribute) *openApiSchema {
+
+	if schema.IsContentRelationship(atr.Content) {
+		return &openApiSchema{Type: "integer", Format: "int64", Description: "Record ID"}
+	}
+	if schema.IsContentFiles(atr.Content) {
+		return &openApiSchema{Type: "array", Items: &openApiSchema{
+			Type: "object",
+			Properties: map[string]*openApiSchema{
+				"id":      {Type: "string", Format: "uuid"},
+				"name":    {Type: "string"},
+				"hash":    {Type: "string"},
+				"size":    {Type: "integer", Format: "int64"},
+				"version": {Type: "integer", Format: "int64"},
+				"changed": {Type: "integer", Format: "int64"},
+			},
+		}}
+	}
+
+	switch atr.Content {
+	case "integer":
+		return &openApiSchema{Type: "integer", Format: "int32"}
+	case "bigint":
+		if slices.Contains([]string{"date", "datetime", "time"}, atr.ContentUse) {
+			return &openApiSchema{Type: "integer", Format: "int64", Description: "Unix time"}
+		}
+		return &openApiSchema{Type: "integer", Format: "int64"}
+	case "numeric":
+		return &openApiSchema{Type: "number"}
+	case "real":
+		return &openApiSchema{Type: "number", Format: "float"}
+	case "double precision":
+		return &openApiSchema{Type: "number", Format: "double"}
+	case "varchar":
+		return &openApiSchema{Type: "string", MaxLength: atr.Length}
+	case "text", "regconfig":
+		return &openApiSchema{Type: "string"}
+	case "boolean":
+		return &openApiSchema{Type: "boolean"}
+	case "uuid":
+		return &openApiSchema{Type: "string", Format: "uuid"}
+	}
+	return &openApiSchema{}
+}
+
+// returns names of all filter getters used in API query, column sub queries and filter sub queries
+func getApiFilterGetters(api types.Api) []string {
+	getters := make([]string, 0)
+
+	var addFromQuery func(query types.Query)
+	var addFromSide = func(side types.QueryFilterSide) {
+		if side.Content == "getter" && side.Value.Valid && !slices.Contains(getters, side.Value.String) {
+			getters = append(getters, side.Value.String)
+		}
+		if side.Query.RelationId.Valid {
+			addFromQuery(side.Query)
+		}
+	}
+	addFromQuery = func(query types.Query) {
+		for _, f := range query.Filters {
+			addFromSide(f.Side0)
+			addFromSide(f.Side1)
+		}
+	}
+
+	addFromQuery(api.Query)
+	for _, column := range api.Columns {
+		if column.SubQuery {
+			addFromQuery(column.Query)
+		}
+	}
+	sort.Strings(getters)
+	return getters
+}
+
+// helpers
+func getOpenApiBoolInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+func getOpenApiLanguageCode(mod types.Module, languageCode string) string {
+	if slices.Contains(mod.Languages, languageCode) {
+		return languageCode
+	}
+	return mod.LanguageMain
+}
+func getOpenApiRef(name string) string {
+	return fmt.Sprintf("#/components/schemas/%s", name)
+}
+func getOpenApiRequestBody(refNonVerbose string, refVerbose string) *openApiRequestBody {
+	return &openApiRequestBody{
+		Required: true,
+		Content: map[string]openApiMediaType{"application/json": {Schema: &openApiSchema{OneOf: []*openApiSchema{
+			{Ref: getOpenApiRef(refNonVerbose)},
+			{Ref: getOpenApiRef(refVerbose)},
+		}}}},
+	}
+}
+func getOpenApiResponseError(description string) openApiResponse {
+	return openApiResponse{
+		Description: description,
+		Content: map[string]openApiMediaType{"application/json": {
+			Schema: &openApiSchema{Ref: getOpenApiRef("error")},
+		}},
+	}
+}
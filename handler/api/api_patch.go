@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"r3/cache"
+	"r3/data"
+	"r3/data/data_query"
+	"r3/handler"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// updates existing records of the base relation and joined relations (if join allows updates)
+// PATCH only applies supplied column values, PUT applies all column values (missing values are set to NULL)
+// returns record IDs by relation index, new ETag of the record and HTTP status code in case of error
+func patch_tx(ctx context.Context, tx pgx.Tx, api types.Api, recordId int64, body io.Reader,
+	verbose bool, replace bool, ifMatch string, loginId int64, languageCode string) (map[int]int64, string, int, error) {
+
+	indexRecordIds := make(map[int]int64)
+
+	if recordId < 1 {
+		return indexRecordIds, "", http.StatusBadRequest, errors.New("record ID must be > 0")
+	}
+
+	// lock base record until transaction is done, to avoid concurrent changes between ETag check and update
+	rel, exists := cache.RelationIdMap[api.Query.RelationId.Bytes]
+	if !exists {
+		return indexRecordIds, "", http.StatusServiceUnavailable, handler.ErrSchemaUnknownRelation(api.Query.RelationId.Bytes)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return indexRecordIds, "", http.StatusServiceUnavailable, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		SELECT "%s"
+		FROM "%s"."%s"
+		WHERE "%s" = $1
+		FOR UPDATE
+	`, schema.PkName, mod.Name, rel.Name, schema.PkName), recordId); err != nil {
+		return indexRecordIds, "", http.StatusServiceUnavailable, err
+	}
+
+	// get current record state, including record IDs of joined relations
+	result, exists, err := getRecord_tx(ctx, tx, api, recordId, loginId, languageCode)
+	if err != nil {
+		return indexRecordIds, "", http.StatusServiceUnavailable, err
+	}
+	if !exists {
+		return indexRecordIds, "", http.StatusNotFound, fmt.Errorf("record ID %d does not exist", recordId)
+	}
+	etag, err := getEtag(api, result)
+	if err != nil {
+		return indexRecordIds, "", http.StatusServiceUnavailable, err
+	}
+
+	// optimistic concurrency, abort if record changed since client has seen it
+	if ifMatch != "" && !etagMatches(ifMatch, etag) {
+		return indexRecordIds, etag, http.StatusPreconditionFailed, errors.New("record was changed, ETag does not match")
+	}
+
+	// parse supplied column values, key: column position
+	columnPosMapValue := make(map[int]interface{})
+	if !verbose {
+		// non-verbose mode: column position -> value
+		// {"0":123,"2":"Hans"}
+		var jsonObj map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&jsonObj); err != nil {
+			return indexRecordIds, etag, http.StatusBadRequest, errors.New("invalid JSON object")
+		}
+		for posStr, value := range jsonObj {
+			pos, err := strconv.Atoi(posStr)
+			if err != nil || pos < 0 || pos >= len(api.Columns) {
+				return indexRecordIds, etag, http.StatusBadRequest, fmt.Errorf("invalid column position '%s'", posStr)
+			}
+			columnPosMapValue[pos] = value
+		}
+	} else {
+		// verbose mode: same structure as POST, relation index (+ optional name) -> attribute name -> value
+		var jsonObj map[string]map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&jsonObj); err != nil {
+			return indexRecordIds, etag, http.StatusBadRequest, errors.New("invalid JSON object")
+		}
+		for relStr, columnNameMapValues := range jsonObj {
+			relStr = strings.TrimSpace(rxRelIndexName.ReplaceAllString(relStr, ""))
+
+			relIndex, err := strconv.Atoi(relStr)
+			if err != nil {
+				return indexRecordIds, etag, http.StatusBadRequest, fmt.Errorf("invalid relation index '%s', integer expected", relStr)
+			}
+			for i, column := range api.Columns {
+				if column.Index != relIndex {
+					continue
+				}
+				if value, exists := columnNameMapValues[getColumnRef(column, languageCode)]; exists {
+					columnPosMapValue[i] = value
+				}
+			}
+		}
+	}
+
+	// PUT replaces all column values, missing values are set to NULL
+	if replace {
+		for i, column := range api.Columns {
+			if _, exists := columnPosMapValue[i]; !exists && !column.SubQuery {
+				columnPosMapValue[i] = nil
+			}
+		}
+	}
+
+	if len(columnPosMapValue) == 0 {
+		return indexRecordIds, etag, http.StatusBadRequest, errors.New("no column values given")
+	}
+
+	// build data SET for each relation index with supplied values
+	joinsByIndex := make(map[int]types.QueryJoin)
+	for _, join := range api.Query.Joins {
+		joinsByIndex[join.Index] = join
+	}
+
+	positions := make([]int, 0)
+	for pos := range columnPosMapValue {
+		positions = append(positions, pos)
+	}
+	slices.Sort(positions)
+
+	dataSetsByIndex := make(map[int]types.DataSet)
+	for _, pos := range positions {
+		column := api.Columns[pos]
+
+		if column.SubQuery {
+			return indexRecordIds, etag, http.StatusBadRequest, errors.New("PATCH/PUT does not support sub queries")
+		}
+
+		atr, exists := cache.AttributeIdMap[column.AttributeId]
+		if !exists {
+			return indexRecordIds, etag, http.StatusServiceUnavailable, handler.ErrSchemaUnknownAttribute(column.AttributeId)
+		}
+		if atr.Encrypted {
+			return indexRecordIds, etag, http.StatusBadRequest, errors.New("cannot handle value for encrypted attribute")
+		}
+		if atr.Generated.Valid {
+			// generated attributes are computed by the database, values sent back by clients are ignored
+			continue
+		}
+
+		join, exists := joinsByIndex[column.Index]
+		if !exists || !join.ApplyUpdate {
+			return indexRecordIds, etag, http.StatusBadRequest,
+				fmt.Errorf("updates are not allowed for relation index %d", column.Index)
+		}
+
+		dataSet, exists := dataSetsByIndex[column.Index]
+		if !exists {
+			id, err := getRecordIdFromResult(result, column.Index)
+			if err != nil {
+				return indexRecordIds, etag, http.StatusServiceUnavailable, err
+			}
+			if id == 0 {
+				return indexRecordIds, etag, http.StatusConflict,
+					fmt.Errorf("no record exists for relation index %d", column.Index)
+			}
+			dataSet = types.DataSet{
+				RelationId: join.RelationId,
+				RecordId:   id,
+				Attributes: make([]types.DataSetAttribute, 0),
+			}
+		}
+		dataSet.Attributes = append(dataSet.Attributes, types.DataSetAttribute{
+			AttributeId:   column.AttributeId,
+			AttributeIdNm: pgtype.UUID{},
+			OutsideIn:     false,
+			Value:         columnPosMapValue[pos],
+		})
+		dataSetsByIndex[column.Index] = dataSet
+	}
+
+	indexRecordIds, err = data.Set_tx(ctx, tx, dataSetsByIndex, loginId, uuid.Nil)
+	if err != nil {
+		return indexRecordIds, etag, http.StatusConflict, err
+	}
+
+	// get new ETag after update
+	result, exists, err = getRecord_tx(ctx, tx, api, recordId, loginId, languageCode)
+	if err != nil {
+		return indexRecordIds, "", http.StatusServiceUnavailable, err
+	}
+	if !exists {
+		// record might not be visible anymore after update (relation policy)
+		return indexRecordIds, "", 0, nil
+	}
+	etag, err = getEtag(api, result)
+	return indexRecordIds, etag, 0, err
+}
+
+// gets single record with all API columns via base relation record ID
+func getRecord_tx(ctx context.Context, tx pgx.Tx, api types.Api, recordId int64,
+	loginId int64, languageCode string) (types.DataGetResult, bool, error) {
+
+	dataGet := types.DataGet{
+		RelationId:  api.Query.RelationId.Bytes,
+		IndexSource: 0,
+		Joins:       data_query.ConvertApiQueryToDataJoins(api.Query.Joins),
+		Filters: []types.DataGetFilter{{
+			Connector: "AND",
+			Index:     0,
+			Operator:  "=",
+			Side0: types.DataGetFilterSide{
+				AttributeId: pgtype.UUID{
+					Bytes: cache.RelationIdMap[api.Query.RelationId.Bytes].AttributeIdPk,
+					Valid: true,
+				},
+			},
+			Side1: types.DataGetFilterSide{Value: recordId},
+		}},
+	}
+	for _, column := range api.Columns {
+		dataGet.Expressions = append(dataGet.Expressions, data_query.ConvertColumnToExpression(
+			column, loginId, languageCode, make(map[string]string)))
+	}
+
+	var query string
+	results, _, err := data.Get_tx(ctx, tx, dataGet, loginId, &query)
+	if err != nil {
+		return types.DataGetResult{}, false, err
+	}
+	if len(results) != 1 {
+		return types.DataGetResult{}, false, nil
+	}
+	return results[0], true, nil
+}
+
+// ETag is a hash of all non-sub query column values and record IDs of a single API record
+// sub query values are ignored as they can depend on GET filter getters
+func getEtag(api types.Api, result types.DataGetResult) (string, error) {
+	values := make([]interface{}, 0)
+	for i, column := range api.Columns {
+		if !column.SubQuery && i < len(result.Values) {
+			values = append(values, result.Values[i])
+		}
+	}
+
+	j, err := json.Marshal(struct {
+		IndexRecordIds map[int]interface{} `json:"indexRecordIds"`
+		Values         []interface{}       `json:"values"`
+	}{result.IndexRecordIds, values})
+
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`"%s"`, tools.Hash(string(j))), nil
+}
+
+// checks If-Match header value (can be '*' or a list of ETags) against current ETag
+func etagMatches(ifMatch string, etag string) bool {
+	for _, v := range strings.Split(ifMatch, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// rate limiting of REST API calls, token buckets per login, per API and per node
+// buckets are stored in the cluster schema, so limits are shared by all cluster nodes
+// each bucket holds up to the configured number of requests per minute and is refilled continuously
+// a request consumes one token from each relevant bucket, if any bucket is empty the request is rejected
+// and tokens consumed from other buckets are returned
+// each bucket is updated by a single statement, so concurrent requests only wait for each other briefly
+
+type rateBucket struct {
+	kind   string // login, api, node
+	entity string // ID of login, API or node
+	limit  uint64 // max. requests per minute
+}
+
+// tokens available after refill since last update, capped by bucket capacity
+const rateAvailableExpr = `LEAST($3, r.tokens + GREATEST($4 - r.date_update, 0)::DOUBLE PRECISION / 1000 * $5)`
+
+// checks all relevant buckets, request is only allowed if all buckets allow it
+// returns seconds to wait if any limit is reached, 0 if request is allowed
+func rateCheck(ctx context.Context, loginId int64, apiId uuid.UUID) (int64, error) {
+
+	buckets := make([]rateBucket, 0)
+	for _, b := range []rateBucket{
+		{kind: "node", entity: cache.GetNodeId().String()},
+		{kind: "api", entity: apiId.String()},
+		{kind: "login", entity: fmt.Sprintf("%d", loginId)},
+	} {
+		b.limit = config.GetApiRateLimit(b.kind, b.entity)
+		if b.limit != 0 {
+			buckets = append(buckets, b)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	consumed := make([]rateBucket, 0)
+	var retryAfter int64 = 0
+
+	for _, b := range buckets {
+		capacity := float64(b.limit)
+		refillPerSec := capacity / 60
+
+		// consume token if available, otherwise only store refill and count denial
+		var allowed bool
+		var tokens float64
+		if err := db.Pool.QueryRow(ctx, fmt.Sprintf(`
+			INSERT INTO instance_cluster.api_rate AS r (kind, entity, tokens,
+				allowed, count_allowed, count_denied, date_start, date_update)
+			VALUES ($1, $2, $3 - 1, TRUE, 1, 0, $4, $4)
+			ON CONFLICT (kind, entity) DO UPDATE SET
+				tokens        = %[1]s - CASE WHEN %[1]s >= 1 THEN 1 ELSE 0 END,
+				allowed       = %[1]s >= 1,
+				count_allowed = r.count_allowed + CASE WHEN %[1]s >= 1 THEN 1 ELSE 0 END,
+				count_denied  = r.count_denied  + CASE WHEN %[1]s >= 1 THEN 0 ELSE 1 END,
+				date_update   = GREATEST(r.date_update, $4)
+			RETURNING allowed, tokens
+		`, rateAvailableExpr), b.kind, b.entity, capacity, now, refillPerSec).Scan(&allowed, &tokens); err != nil {
+			return 0, err
+		}
+
+		if allowed {
+			consumed = append(consumed, b)
+			continue
+		}
+		if wait := int64(math.Ceil((1 - tokens) / refillPerSec)); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter == 0 {
+		return 0, nil
+	}
+
+	// request denied, return tokens of buckets that allowed it
+	for _, b := range consumed {
+		if _, err := db.Pool.Exec(ctx, `
+			UPDATE instance_cluster.api_rate
+			SET tokens = LEAST($3, tokens + 1), count_allowed = count_allowed - 1
+			WHERE kind   = $1
+			AND   entity = $2
+		`, b.kind, b.entity, float64(b.limit)); err != nil {
+			return 0, err
+		}
+	}
+	return retryAfter, nil
+}
@@ -16,6 +16,8 @@ import (
 	"r3/types"
 	"slices"
 	"time"
+
+	"github.com/gofrs/uuid"
 )
 
 type accessRequest struct {
@@ -79,8 +81,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	log.Info(log.ContextServer, fmt.Sprintf("DIRECT ACCESS, %s data, payload: %s", req.Action, req.Request))
 
-	res, err := request.Exec_tx(ctx, tx, "", login.Id, login.Admin,
-		types.WebsocketClientDeviceBrowser, login.NoAuth, "data", req.Action, req.Request)
+	res, err := request.Exec_tx(ctx, tx, "", uuid.Nil, login.Id, login.Admin,
+		types.WebsocketClientDeviceBrowser, login.NoAuth, "data", req.Action, req.Request, nil)
 
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextDataAccess, err, handler.ErrGeneral)
@@ -43,6 +43,8 @@ const (
 	ErrCodeAppUnknownModule         int = 7
 	ErrCodeAppUnknownRelation       int = 8
 	ErrCodeAppUnknownAttribute      int = 9
+	ErrCodeAppRecordLocked          int = 10
+	ErrCodeAppRecordChanged         int = 11
 	ErrCodeCsvParseInt              int = 1
 	ErrCodeCsvParseFloat            int = 2
 	ErrCodeCsvParseDateTime         int = 3
@@ -224,6 +226,9 @@ func ErrSchemaUnknownModule(id uuid.UUID) error {
 func ErrSchemaUnknownRelation(id uuid.UUID) error {
 	return fmt.Errorf("unknown relation '%s'", id)
 }
+func ErrSchemaUnknownApi(id uuid.UUID) error {
+	return fmt.Errorf("unknown API '%s'", id)
+}
 func ErrSchemaUnknownAttribute(id uuid.UUID) error {
 	return fmt.Errorf("unknown attribute '%s'", id)
 }
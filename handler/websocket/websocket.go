@@ -3,8 +3,8 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"r3/bruteforce"
 	"r3/cache"
@@ -39,6 +39,10 @@ type clientType struct {
 	pwaModuleId uuid.UUID                   // ID of module for direct app access via subdomain, nil UUID if not used
 	write_mx    sync.Mutex                  // to force sequential writes
 	ws          *websocket.Conn             // websocket connection
+
+	// relations with open fields (lists, forms, etc.) on the client, to only send relevant data change events
+	relationIdMapWatched map[uuid.UUID]bool
+	watch_mx             sync.RWMutex
 }
 
 // a hub for all active websocket clients
@@ -83,7 +87,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// get client host address
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	host, err := bruteforce.GetHost(r)
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextWebsocket, err, handler.ErrGeneral)
 		return
@@ -118,6 +122,9 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		pwaModuleId: cache.GetPwaModuleId(strings.Split(r.Host, ".")[0]), // assign PWA module ID if host matches any defined PWA direct app access rule
 		write_mx:    sync.Mutex{},
 		ws:          ws,
+
+		relationIdMapWatched: make(map[uuid.UUID]bool),
+		watch_mx:             sync.RWMutex{},
 	}
 
 	if r.Header.Get("User-Agent") == "r3-client-fat" {
@@ -155,6 +162,9 @@ func (hub *hubType) start() {
 			if err := login_session.LogRemove(client.id); err != nil {
 				log.Error(log.ContextWebsocket, "failed to remove login session log", err)
 			}
+			if err := request.DataUnlockByClient(client.id); err != nil {
+				log.Error(log.ContextWebsocket, "failed to release record locks", err)
+			}
 		}()
 	}
 
@@ -181,6 +191,8 @@ func (hub *hubType) start() {
 				jsonMsg, err = prepareUnrequested("collectionChanged", event.Payload)
 			case "configChanged":
 				jsonMsg, err = prepareUnrequested("configChanged", nil)
+			case "dataChanged":
+				jsonMsg, err = prepareUnrequested("dataChanged", event.Payload)
 			case "filesCopied":
 				jsonMsg, err = prepareUnrequested("filesCopied", event.Payload)
 			case "fileRequested":
@@ -191,6 +203,8 @@ func (hub *hubType) start() {
 			case "keystrokesRequested":
 				jsonMsg, err = prepareUnrequested("keystrokesRequested", event.Payload)
 				singleRecipient = true
+			case "recordLockChanged":
+				jsonMsg, err = prepareUnrequested("recordLockChanged", event.Payload)
 			case "renew":
 				jsonMsg, err = prepareUnrequested("reauthorized", nil)
 			case "schemaLoaded":
@@ -227,6 +241,18 @@ func (hub *hubType) start() {
 					continue
 				}
 
+				// skip if exclusion filter applies to client
+				if event.Target.ClientIdExcluded != uuid.Nil && event.Target.ClientIdExcluded == client.id {
+					continue
+				}
+
+				// skip data & record lock changes if client does not watch the relation or cannot read it (anymore)
+				if event.Content == "dataChanged" || event.Content == "recordLockChanged" {
+					if !client.watchesRelation(getEventRelationId(event)) {
+						continue
+					}
+				}
+
 				// store as fallback if preferred target filter does apply to client
 				// fallback clients are only used if no other clients match the target filters
 				if event.Target.PwaModuleIdPreferred != uuid.Nil && event.Target.PwaModuleIdPreferred != client.pwaModuleId {
@@ -314,21 +340,46 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 
 	defer ctxCanc()
 
-	// client can either authenticate or execute requests
+	// client can either authenticate, set its watched relations or execute requests
 	authRequest := len(reqTrans.Requests) == 1 && reqTrans.Requests[0].Ressource == "auth"
+	watchRequest := len(reqTrans.Requests) == 1 && reqTrans.Requests[0].Ressource == "data" &&
+		reqTrans.Requests[0].Action == "watch"
+
+	if watchRequest {
+		// watched relations are stored with the client, not in the database
+		resTrans.Responses = make([]types.Response, 0)
+
+		if err := client.setRelationsWatched(reqTrans.Requests[0].Payload); err != nil {
+			resTrans.Error = processReturnErr(err, client.admin, client.loginId, reqTrans.TransactionNr).Error()
+		} else {
+			resTrans.Responses = append(resTrans.Responses, types.Response{Payload: []byte("null")})
+		}
+
+	} else if !authRequest {
+		// partial results are sent with the transaction number of the request, before the final response
+		streamed := false
+		stream := func(payload interface{}) error {
+			chunkJson, err := prepareStreamed(reqTrans.TransactionNr, payload)
+			if err != nil {
+				return err
+			}
+			client.write(chunkJson)
+			streamed = true
+			return nil
+		}
 
-	if !authRequest {
 		// execute non-authentication transaction
-		resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.loginId,
-			client.admin, client.device, client.noAuth, reqTrans, false)
+		resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.id, client.loginId,
+			client.admin, client.device, client.noAuth, reqTrans, false, stream)
 
 		if err != nil {
 			returnErr := processReturnErr(err, client.admin, client.loginId, reqTrans.TransactionNr)
 
-			if handler.CheckForDbsCacheErrCode(returnErr) {
+			// cannot repeat once partial results have been sent, client would receive them twice
+			if handler.CheckForDbsCacheErrCode(returnErr) && !streamed {
 				// known PGX cache error, repeat with cleared DB statement/description cache
-				resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.loginId,
-					client.admin, client.device, client.noAuth, reqTrans, true)
+				resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.id, client.loginId,
+					client.admin, client.device, client.noAuth, reqTrans, true, stream)
 
 				if err != nil {
 					resTrans.Responses = make([]types.Response, 0)
@@ -413,6 +464,62 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 	return resTransJson
 }
 
+// sets relations watched by client for data change events, replacing previously watched ones
+func (client *clientType) setRelationsWatched(reqJson json.RawMessage) error {
+	if client.loginId == 0 {
+		return errors.New(handler.ErrUnauthorized)
+	}
+
+	var req struct {
+		RelationIds []uuid.UUID `json:"relationIds"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return err
+	}
+
+	client.watch_mx.Lock()
+	defer client.watch_mx.Unlock()
+
+	client.relationIdMapWatched = make(map[uuid.UUID]bool)
+	for _, id := range req.RelationIds {
+		client.relationIdMapWatched[id] = true
+	}
+	return nil
+}
+
+// checks whether client watches relation and has read access to it
+// access is checked when the event is sent, as it can change while the relation is watched
+func (client *clientType) watchesRelation(relationId uuid.UUID) bool {
+	if relationId == uuid.Nil || client.loginId == 0 {
+		return false
+	}
+
+	client.watch_mx.RLock()
+	watched := client.relationIdMapWatched[relationId]
+	client.watch_mx.RUnlock()
+
+	if !watched {
+		return false
+	}
+
+	access, err := cache.GetAccessById(client.loginId)
+	if err != nil {
+		return false
+	}
+	return access.Relation[relationId] >= types.AccessRead
+}
+
+// returns relation ID of relation-specific event, nil UUID if payload is invalid
+func getEventRelationId(event types.ClusterEvent) uuid.UUID {
+	switch p := event.Payload.(type) {
+	case uuid.UUID:
+		return p
+	case types.ClusterEventRecordLockChanged:
+		return p.RelationId
+	}
+	return uuid.Nil
+}
+
 func processReturnErr(err error, isAdmin bool, loginId int64, transNr uint64) error {
 	returnErr, isExpected := handler.ConvertToErrCode(err, !isAdmin)
 	if !isExpected {
@@ -442,3 +549,22 @@ func prepareUnrequested(ressource string, payload interface{}) ([]byte, error) {
 	}
 	return transJson, nil
 }
+
+// partial result of a running transaction, matched by the client via transaction number
+func prepareStreamed(transactionNr uint64, payload interface{}) ([]byte, error) {
+
+	var resTrans types.UnreqResponseTransaction
+	resTrans.TransactionNr = transactionNr
+
+	payloadJson, err := json.Marshal(payload)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	resTrans.Responses = make([]types.UnreqResponse, 1)
+	resTrans.Responses[0].Payload = payloadJson
+	resTrans.Responses[0].Ressource = "stream"
+	resTrans.Responses[0].Result = "OK"
+
+	return json.Marshal(resTrans)
+}
@@ -0,0 +1,202 @@
+package login_apiKey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// API keys, used as bearer tokens for REST API calls instead of login credentials
+// only a hash of the key is stored, the key itself is only returned once on creation
+const (
+	Prefix       = "r3k_" // prefix to distinguish API keys from JWTs
+	prefixLength = 12     // stored characters of key, to identify key without revealing it
+)
+
+func Del_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_api_key
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// returns API keys of a single login or of all logins (login ID 0)
+func Get_tx(ctx context.Context, tx pgx.Tx, loginId int64) ([]types.LoginApiKey, error) {
+	keys := make([]types.LoginApiKey, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT k.id, k.login_id, l.name, k.name, k.prefix, k.active, k.read_only,
+			k.ip_allow, k.date_create, k.date_expiry, k.date_used, k.ip_used,
+			ARRAY(
+				SELECT api_id
+				FROM instance.login_api_key_api
+				WHERE login_api_key_id = k.id
+			),
+			ARRAY(
+				SELECT module_id
+				FROM instance.login_api_key_module
+				WHERE login_api_key_id = k.id
+			)
+		FROM instance.login_api_key AS k
+		JOIN instance.login         AS l ON l.id = k.login_id
+		WHERE $1 = 0 OR k.login_id = $1
+		ORDER BY l.name ASC, k.name ASC
+	`, loginId)
+	if err != nil {
+		return keys, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var k types.LoginApiKey
+		if err := rows.Scan(&k.Id, &k.LoginId, &k.LoginName, &k.Name, &k.Prefix,
+			&k.Active, &k.ReadOnly, &k.IpAllow, &k.DateCreate, &k.DateExpiry,
+			&k.DateUsed, &k.IpUsed, &k.ApiIds, &k.ModuleIds); err != nil {
+
+			return keys, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// creates or updates API key
+// returns the key if it was newly created, it cannot be retrieved afterwards
+func Set_tx(ctx context.Context, tx pgx.Tx, k types.LoginApiKey) (string, error) {
+
+	if k.Name == "" {
+		return "", errors.New("API key name must not be empty")
+	}
+	if len(k.ApiIds) == 0 && len(k.ModuleIds) == 0 {
+		return "", errors.New("API key requires at least one API or module scope")
+	}
+	for _, ip := range k.IpAllow {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return "", fmt.Errorf("invalid IP address or network '%s'", ip)
+			}
+		}
+	}
+	if k.ApiIds == nil {
+		k.ApiIds = make([]uuid.UUID, 0)
+	}
+	if k.ModuleIds == nil {
+		k.ModuleIds = make([]uuid.UUID, 0)
+	}
+	if k.IpAllow == nil {
+		k.IpAllow = make([]string, 0)
+	}
+
+	key := ""
+	newRecord := k.Id == uuid.Nil
+	if newRecord {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return "", err
+		}
+		k.Id = id
+
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+		key = Prefix + base64.RawURLEncoding.EncodeToString(random)
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.login_api_key (id, login_id, name, prefix, hash,
+				active, read_only, ip_allow, date_create, date_expiry)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		`, k.Id, k.LoginId, k.Name, key[:prefixLength], GetHash(key), k.Active,
+			k.ReadOnly, k.IpAllow, tools.GetTimeUnix(), k.DateExpiry); err != nil {
+
+			return "", err
+		}
+	} else {
+		// login of existing key cannot be changed
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.login_api_key
+			SET name = $1, active = $2, read_only = $3, ip_allow = $4, date_expiry = $5
+			WHERE id = $6
+		`, k.Name, k.Active, k.ReadOnly, k.IpAllow, k.DateExpiry, k.Id); err != nil {
+			return "", err
+		}
+	}
+
+	// scopes
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_api_key_api
+		WHERE login_api_key_id = $1
+		AND   api_id <> ALL($2)
+	`, k.Id, k.ApiIds); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.login_api_key_api (login_api_key_id, api_id)
+			SELECT $1, UNNEST($2::UUID[])
+		ON CONFLICT DO NOTHING
+	`, k.Id, k.ApiIds); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_api_key_module
+		WHERE login_api_key_id = $1
+		AND   module_id <> ALL($2)
+	`, k.Id, k.ModuleIds); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.login_api_key_module (login_api_key_id, module_id)
+			SELECT $1, UNNEST($2::UUID[])
+		ON CONFLICT DO NOTHING
+	`, k.Id, k.ModuleIds); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// returns hash of API key, as stored in database
+func GetHash(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// returns whether API key can be used from given IP address
+func IsIpAllowed(k types.LoginApiKey, host string) bool {
+	if len(k.IpAllow) == 0 {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, allow := range k.IpAllow {
+		if strings.Contains(allow, "/") {
+			if _, network, err := net.ParseCIDR(allow); err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowIp := net.ParseIP(allow); allowIp != nil && allowIp.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns whether API key can be used for given API
+func IsInScope(k types.LoginApiKey, api types.Api) bool {
+	return slices.Contains(k.ApiIds, api.Id) || slices.Contains(k.ModuleIds, api.ModuleId)
+}
@@ -0,0 +1,90 @@
+package login_auth
+
+import (
+	"context"
+	"errors"
+	"r3/cache"
+	"r3/db"
+	"r3/login/login_apiKey"
+	"r3/tools"
+	"r3/types"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// performs authentication by using API key, sent as bearer token with REST API calls
+// returns login details and API key with its scopes, which must be checked by the caller
+// cannot grant admin access
+func ApiKey(ctx context.Context, key string, host string) (types.LoginAuthResult, types.LoginApiKey, error) {
+
+	var k types.LoginApiKey
+	if !strings.HasPrefix(key, login_apiKey.Prefix) {
+		return types.LoginAuthResult{}, k, errors.New("invalid API key")
+	}
+
+	var l = types.LoginAuthResult{
+		Admin:  false,
+		NoAuth: false,
+	}
+	var admin bool
+	var limited bool
+	var loginActive bool
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT k.id, k.login_id, k.active, k.read_only, k.ip_allow, k.date_expiry,
+			ARRAY(
+				SELECT api_id
+				FROM instance.login_api_key_api
+				WHERE login_api_key_id = k.id
+			),
+			ARRAY(
+				SELECT module_id
+				FROM instance.login_api_key_module
+				WHERE login_api_key_id = k.id
+			),
+			l.name, l.active, l.admin, l.limited, s.language_code
+		FROM instance.login_api_key AS k
+		JOIN instance.login         AS l ON l.id       = k.login_id
+		JOIN instance.login_setting AS s ON s.login_id = k.login_id
+		WHERE k.hash = $1
+	`, login_apiKey.GetHash(key)).Scan(&k.Id, &k.LoginId, &k.Active, &k.ReadOnly,
+		&k.IpAllow, &k.DateExpiry, &k.ApiIds, &k.ModuleIds, &l.Name, &loginActive,
+		&admin, &limited, &l.LanguageCode); err != nil {
+
+		if err == pgx.ErrNoRows {
+			return types.LoginAuthResult{}, k, errors.New("invalid API key")
+		}
+		return types.LoginAuthResult{}, k, err
+	}
+	l.Id = k.LoginId
+
+	if !k.Active || !loginActive {
+		return types.LoginAuthResult{}, k, errors.New("API key or login inactive")
+	}
+	if k.DateExpiry.Valid && tools.GetTimeUnix() > k.DateExpiry.Int64 {
+		return types.LoginAuthResult{}, k, errors.New("API key expired")
+	}
+	if !login_apiKey.IsIpAllowed(k, host) {
+		return types.LoginAuthResult{}, k, errors.New("API key is not allowed from this IP address")
+	}
+
+	if err := preAuthChecks(l.Id, admin, limited, true); err != nil {
+		return types.LoginAuthResult{}, k, err
+	}
+
+	// everything in order, auth successful
+	if err := cache.LoadAccessIfUnknown(l.Id); err != nil {
+		return types.LoginAuthResult{}, k, err
+	}
+
+	// track last usage
+	if _, err := db.Pool.Exec(ctx, `
+		UPDATE instance.login_api_key
+		SET date_used = $1, ip_used = $2
+		WHERE id = $3
+	`, tools.GetTimeUnix(), host, k.Id); err != nil {
+		return types.LoginAuthResult{}, k, err
+	}
+	return l, k, nil
+}
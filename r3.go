@@ -61,8 +61,8 @@ var (
 	// overwritten by build parameters
 	appName          string = "Axia"
 	appNameShort     string = "Ax4"
-	appVersion       string = "4.0.1.0"
-	appVersionClient string = "4.0.1.0"
+	appVersion       string = "4.1.0.0"
+	appVersionClient string = "4.1.0.0"
 
 	// start parameters
 	cli struct {
@@ -14,12 +14,14 @@ import (
 	"r3/log"
 	"r3/types"
 
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
 // executes a websocket transaction with multiple requests within a single DB transaction
-func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin bool, device types.WebsocketClientDevice,
-	isNoAuth bool, reqTrans types.RequestTransaction, clearDbCache bool) ([]types.Response, error) {
+// stream function sends partial results to the requestor before the transaction completes, nil if not supported
+func ExecTransaction(ctx context.Context, address string, clientId uuid.UUID, loginId int64, isAdmin bool, device types.WebsocketClientDevice,
+	isNoAuth bool, reqTrans types.RequestTransaction, clearDbCache bool, stream func(payload interface{}) error) ([]types.Response, error) {
 
 	var tx pgx.Tx
 	var err error
@@ -47,7 +49,7 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 	for _, req := range reqTrans.Requests {
 		log.Info(log.ContextWebsocket, fmt.Sprintf("TRANSACTION %d, %s %s, payload: %s", reqTrans.TransactionNr, req.Action, req.Ressource, req.Payload))
 
-		payload, err := Exec_tx(ctx, tx, address, loginId, isAdmin, device, isNoAuth, req.Ressource, req.Action, req.Payload)
+		payload, err := Exec_tx(ctx, tx, address, clientId, loginId, isAdmin, device, isNoAuth, req.Ressource, req.Action, req.Payload, stream)
 		if err != nil {
 			return nil, err
 		}
@@ -66,9 +68,10 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 	return responses, nil
 }
 
-func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAdmin bool,
+// client ID identifies the websocket client for record locks, nil UUID if request does not come from websocket client
+func Exec_tx(ctx context.Context, tx pgx.Tx, address string, clientId uuid.UUID, loginId int64, isAdmin bool,
 	device types.WebsocketClientDevice, isNoAuth bool, ressource string, action string,
-	reqJson json.RawMessage) (interface{}, error) {
+	reqJson json.RawMessage, stream func(payload interface{}) error) (interface{}, error) {
 
 	// public requests: accessible to all
 	switch ressource {
@@ -107,17 +110,35 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 	case "data":
 		switch action {
 		case "del":
-			return DataDel_tx(ctx, tx, reqJson, loginId)
+			return DataDel_tx(ctx, tx, reqJson, loginId, clientId)
 		case "get":
 			return DataGet_tx(ctx, tx, reqJson, loginId)
 		case "getKeys":
 			return DataGetKeys_tx(ctx, tx, reqJson, loginId)
 		case "getLog":
 			return DataLogGet_tx(ctx, tx, reqJson, loginId)
+		case "getLogDeleted":
+			return DataLogGetDeleted_tx(ctx, tx, reqJson, loginId)
+		case "getLogState":
+			return DataLogGetState_tx(ctx, tx, reqJson, loginId)
+		case "getStream":
+			return DataGetStream_tx(ctx, tx, reqJson, loginId, stream)
+		case "getTrash":
+			return DataGetTrash_tx(ctx, tx, reqJson, loginId)
+		case "lock":
+			return DataLock_tx(ctx, tx, reqJson, loginId, clientId)
+		case "revertLog":
+			return DataLogRevert_tx(ctx, tx, reqJson, loginId, clientId)
+		case "restore":
+			return DataRestore_tx(ctx, tx, reqJson, loginId)
 		case "set":
-			return DataSet_tx(ctx, tx, reqJson, loginId)
+			return DataSet_tx(ctx, tx, reqJson, loginId, clientId)
 		case "setKeys":
 			return DataSetKeys_tx(ctx, tx, reqJson)
+		case "undelete":
+			return DataUndelete_tx(ctx, tx, reqJson, loginId)
+		case "unlock":
+			return DataUnlock_tx(ctx, tx, reqJson, clientId)
 		}
 	case "event":
 		switch action {
@@ -236,6 +257,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "exec": // user may exec non-trigger backend function, available to frontend
 			return PgFunctionExec_tx(ctx, tx, reqJson, true)
 		}
+	case "task":
+		switch action {
+		case "getManual": // schedules the login may run manually via its roles
+			return TaskGetManual_tx(ctx, tx, loginId)
+		case "runManual": // admins or logins with roles assigned to the task schedule
+			return TaskRunManual_tx(ctx, tx, reqJson, loginId, isAdmin)
+		}
 	}
 
 	// authorized requests: admin
@@ -253,6 +281,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return ApiSet_tx(ctx, tx, reqJson)
 		}
+	case "apiRate":
+		switch action {
+		case "get":
+			return ApiRateGet_tx(ctx, tx)
+		case "reset":
+			return ApiRateReset_tx(ctx, tx, reqJson)
+		}
 	case "article":
 		switch action {
 		case "assign":
@@ -322,6 +357,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		}
 	case "dataSql":
 		switch action {
+		case "explain":
+			return DataSqlExplain_tx(ctx, tx, reqJson, loginId)
 		case "get":
 			return DataSqlGet_tx(ctx, tx, reqJson, loginId)
 		}
@@ -353,6 +390,24 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "setName":
 			return IconSetName_tx(ctx, tx, reqJson)
 		}
+	case "job":
+		switch action {
+		case "del":
+			return JobDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobGet_tx(ctx, tx, reqJson)
+		case "reset":
+			return JobReset_tx(ctx, tx, reqJson)
+		}
+	case "jobQueue":
+		switch action {
+		case "del":
+			return JobQueueDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobQueueGet_tx(ctx, tx)
+		case "set":
+			return JobQueueSet_tx(ctx, tx, reqJson)
+		}
 	case "jsFunction":
 		switch action {
 		case "del":
@@ -389,6 +444,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		switch action {
 		case "get":
 			return LogGet_tx(ctx, tx, reqJson)
+		case "getSlow":
+			return LogGetSlow_tx(ctx, tx, reqJson)
 		}
 	case "login":
 		switch action {
@@ -415,6 +472,15 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "setMembers":
 			return LoginSetMembers_tx(ctx, tx, reqJson)
 		}
+	case "loginApiKey":
+		switch action {
+		case "del":
+			return LoginApiKeyDel_tx(ctx, tx, reqJson)
+		case "get":
+			return LoginApiKeyGet_tx(ctx, tx, reqJson)
+		case "set":
+			return LoginApiKeySet_tx(ctx, tx, reqJson)
+		}
 	case "loginForm":
 		switch action {
 		case "del":
@@ -561,6 +627,15 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "update":
 			return RepoModuleUpdate_tx(ctx, tx)
 		}
+	case "restSpooler":
+		switch action {
+		case "del":
+			return RestSpoolerDel_tx(ctx, tx, reqJson)
+		case "get":
+			return RestSpoolerGet_tx(ctx, tx, reqJson)
+		case "reset":
+			return RestSpoolerReset_tx(ctx, tx, reqJson)
+		}
 	case "role":
 		switch action {
 		case "del":
@@ -572,6 +647,14 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		switch action {
 		case "get":
 			return schedulersGet_tx(ctx, tx)
+		case "getRuns":
+			return schedulerRunsGet_tx(ctx, tx, reqJson)
+		case "setAlerts":
+			return schedulerAlertsSet_tx(ctx, tx, reqJson)
+		case "setDependencies":
+			return schedulerDependenciesSet_tx(ctx, tx, reqJson)
+		case "setRoles":
+			return schedulerRolesSet_tx(ctx, tx, reqJson)
 		}
 	case "schema":
 		switch action {
@@ -610,6 +693,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return VariableSet_tx(ctx, tx, reqJson)
 		}
+	case "webhook":
+		switch action {
+		case "del":
+			return WebhookDel_tx(ctx, tx, reqJson)
+		case "set":
+			return WebhookSet_tx(ctx, tx, reqJson)
+		}
 	case "widget":
 		switch action {
 		case "del":
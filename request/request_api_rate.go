@@ -0,0 +1,71 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// current consumption of REST API rate limits
+func ApiRateGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+
+	type bucket struct {
+		Kind         string  `json:"kind"`       // login, api, node
+		Entity       string  `json:"entity"`     // ID of login, API or node
+		EntityName   string  `json:"entityName"` // name of login, API or node
+		Limit        uint64  `json:"limit"`      // currently configured limit per minute
+		Tokens       float64 `json:"tokens"`     // available requests, as of last update
+		CountAllowed int64   `json:"countAllowed"`
+		CountDenied  int64   `json:"countDenied"`
+		DateStart    int64   `json:"dateStart"`  // unix time, milliseconds
+		DateUpdate   int64   `json:"dateUpdate"` // unix time, milliseconds
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT r.kind, r.entity, COALESCE(l.name, m.name || '.' || a.name || '.v' || a.version, n.name),
+			r.tokens, r.count_allowed, r.count_denied, r.date_start, r.date_update
+		FROM instance_cluster.api_rate AS r
+		LEFT JOIN instance.login         AS l ON r.kind = 'login' AND l.id::TEXT = r.entity
+		LEFT JOIN app.api                AS a ON r.kind = 'api'   AND a.id::TEXT = r.entity
+		LEFT JOIN app.module             AS m ON m.id   = a.module_id
+		LEFT JOIN instance_cluster.node  AS n ON r.kind = 'node'  AND n.id::TEXT = r.entity
+		ORDER BY r.kind ASC, r.count_allowed + r.count_denied DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]bucket, 0)
+	for rows.Next() {
+		var b bucket
+		var name pgtype.Text
+		if err := rows.Scan(&b.Kind, &b.Entity, &name, &b.Tokens, &b.CountAllowed,
+			&b.CountDenied, &b.DateStart, &b.DateUpdate); err != nil {
+
+			return nil, err
+		}
+		b.EntityName = name.String
+		b.Limit = config.GetApiRateLimit(b.Kind, b.Entity)
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+// resets rate limit buckets, all limits start fresh
+func ApiRateReset_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Kind string `json:"kind"` // reset only buckets of given kind, all if empty
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance_cluster.api_rate
+		WHERE $1 = '' OR kind = $1
+	`, req.Kind)
+	return nil, err
+}
@@ -3,13 +3,22 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"r3/cache"
+	"r3/cluster"
 	"r3/data"
 	"r3/data/data_enc"
+	"r3/data/data_query"
+	"r3/db"
+	"r3/handler"
 	"r3/types"
+	"slices"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
@@ -29,7 +38,10 @@ func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 		return nil, err
 	}
 
+	dateStart := time.Now()
 	res.Rows, res.Count, err = data.Get_tx(ctx, tx, req, loginId, &query)
+	data.LogSlow("get", dateStart, loginId, []uuid.UUID{req.RelationId}, req.QueryId, pgtype.UUID{}, query)
+
 	if err != nil {
 		if query != "" {
 			return nil, fmt.Errorf("%s, SQL: %s", err, query)
@@ -40,8 +52,73 @@ func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	return res, nil
 }
 
+// streams data rows in chunks, returns total count once all rows have been sent
+// rows are not collected, large results can be retrieved without holding them in memory
+func DataGetStream_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64, stream func(payload interface{}) error) (interface{}, error) {
+
+	var (
+		err   error
+		query string
+		req   struct {
+			types.DataGet
+			ChunkSize int `json:"chunkSize"`
+		}
+		res struct {
+			Count int64 `json:"count"`
+		}
+	)
+
+	if stream == nil {
+		return nil, errors.New("data stream is not supported by this connection")
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if req.ChunkSize < 1 || req.ChunkSize > 10000 {
+		req.ChunkSize = 1000
+	}
+
+	chunk := make([]types.DataGetResult, 0, req.ChunkSize)
+	var sendChunk = func() error {
+		if err := stream(struct {
+			Rows []types.DataGetResult `json:"rows"`
+		}{chunk}); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	dateStart := time.Now()
+	res.Count, err = data.GetIter_tx(ctx, tx, req.DataGet, loginId, &query,
+		func(row types.DataGetResult) error {
+			chunk = append(chunk, row)
+			if len(chunk) < req.ChunkSize {
+				return nil
+			}
+			return sendChunk()
+		})
+
+	data.LogSlow("get", dateStart, loginId, []uuid.UUID{req.RelationId}, req.QueryId, pgtype.UUID{}, query)
+
+	if err != nil {
+		if query != "" {
+			return nil, fmt.Errorf("%s, SQL: %s", err, query)
+		} else {
+			return nil, fmt.Errorf("%s", err)
+		}
+	}
+	if len(chunk) != 0 {
+		if err := sendChunk(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 func DataSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
-	loginId int64) (interface{}, error) {
+	loginId int64, clientId uuid.UUID) (interface{}, error) {
 
 	var (
 		err error
@@ -53,7 +130,18 @@ func DataSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 		return nil, err
 	}
 
-	res.IndexRecordIds, err = data.Set_tx(ctx, tx, req, loginId)
+	relationIds := make([]uuid.UUID, 0)
+	for _, dataSet := range req {
+		if !slices.Contains(relationIds, dataSet.RelationId) {
+			relationIds = append(relationIds, dataSet.RelationId)
+		}
+	}
+
+	dateStart := time.Now()
+	txRec := data.NewTxRecorder(tx)
+	res.IndexRecordIds, err = data.Set_tx(ctx, txRec, req, loginId, clientId)
+	data.LogSlow("set", dateStart, loginId, relationIds, pgtype.UUID{}, pgtype.UUID{}, txRec.QueriesJoined())
+
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +149,25 @@ func DataSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 }
 
 func DataDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64, clientId uuid.UUID) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordId   int64     `json:"recordId"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	dateStart := time.Now()
+	txRec := data.NewTxRecorder(tx)
+	err := data.Del_tx(ctx, txRec, req.RelationId, req.RecordId, loginId, clientId)
+	data.LogSlow("del", dateStart, loginId, []uuid.UUID{req.RelationId}, pgtype.UUID{}, pgtype.UUID{}, txRec.QueriesJoined())
+
+	return nil, err
+}
+
+func DataUndelete_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	loginId int64) (interface{}, error) {
 
 	var req struct {
@@ -71,7 +178,134 @@ func DataDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
-	return nil, data.Del_tx(ctx, tx, req.RelationId, req.RecordId, loginId)
+	return nil, data.Undelete_tx(ctx, tx, req.RelationId, req.RecordId, loginId)
+}
+
+// record locks for concurrent editing, held by websocket client
+func DataLock_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64, clientId uuid.UUID) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordId   int64     `json:"recordId"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	lock, acquired, err := data.Lock_tx(ctx, tx, req.RelationId, req.RecordId, loginId, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	// inform other clients about new lock holder
+	if acquired {
+		if err := cluster.RecordLockChanged_tx(ctx, tx, types.ClusterEventRecordLockChanged{
+			RelationId: lock.RelationId,
+			RecordId:   lock.RecordId,
+			Locked:     true,
+			LoginId:    lock.LoginId,
+			LoginName:  lock.LoginName,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return lock, nil
+}
+func DataUnlock_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	clientId uuid.UUID) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordId   int64     `json:"recordId"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	released, err := data.Unlock_tx(ctx, tx, req.RelationId, req.RecordId, clientId)
+	if err != nil || !released {
+		return nil, err
+	}
+	return nil, cluster.RecordLockChanged_tx(ctx, tx, types.ClusterEventRecordLockChanged{
+		RelationId: req.RelationId,
+		RecordId:   req.RecordId,
+		Locked:     false,
+	})
+}
+
+// releases all record locks of disconnected websocket client and informs other clients
+func DataUnlockByClient(clientId uuid.UUID) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	locks, err := data.UnlockByClient_tx(ctx, tx, clientId)
+	if err != nil {
+		return err
+	}
+	for _, l := range locks {
+		if err := cluster.RecordLockChanged_tx(ctx, tx, types.ClusterEventRecordLockChanged{
+			RelationId: l.RelationId,
+			RecordId:   l.RecordId,
+			Locked:     false,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// trash bin of soft delete relations
+func DataGetTrash_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var (
+		err error
+		req struct {
+			RelationId uuid.UUID `json:"relationId"`
+			Limit      int       `json:"limit"`
+			Offset     int       `json:"offset"`
+		}
+		res struct {
+			Records []types.DataTrashRecord `json:"records"`
+			Total   int64                   `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Records, res.Total, err = data.GetTrash_tx(ctx, tx, req.RelationId, loginId, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+func DataRestore_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordIds  []int64   `json:"recordIds"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	for _, recordId := range req.RecordIds {
+		if err := data.Restore_tx(ctx, tx, req.RelationId, recordId, loginId); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
 }
 
 // data log
@@ -89,6 +323,61 @@ func DataLogGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	return data.GetLogs_tx(ctx, tx, req.RecordId, req.AttributeIds, loginId)
 }
 
+func DataLogGetDeleted_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var (
+		err error
+		req struct {
+			RelationId uuid.UUID `json:"relationId"`
+			Limit      int       `json:"limit"`
+			Offset     int       `json:"offset"`
+		}
+		res struct {
+			Logs  []types.DataLog `json:"logs"`
+			Total int64           `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Logs, res.Total, err = data.GetLogsDeleted_tx(ctx, tx, req.RelationId, loginId, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+func DataLogGetState_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordId   int64     `json:"recordId"`
+		Date       int64     `json:"date"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.GetLogState_tx(ctx, tx, req.RelationId, req.RecordId, req.Date, loginId)
+}
+func DataLogRevert_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64, clientId uuid.UUID) (interface{}, error) {
+
+	var req struct {
+		RelationId   uuid.UUID   `json:"relationId"`
+		RecordId     int64       `json:"recordId"`
+		Date         int64       `json:"date"`
+		AttributeIds []uuid.UUID `json:"attributeIds"` // optional, all logged attributes if empty
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.RevertLog_tx(ctx, tx, req.RelationId, req.RecordId, req.Date, req.AttributeIds, loginId, clientId)
+}
+
 // data SQL
 func DataSqlGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	loginId int64) (interface{}, error) {
@@ -105,6 +394,63 @@ func DataSqlGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	return query, nil
 }
 
+func DataSqlExplain_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		ApiId   pgtype.UUID       `json:"apiId"`   // API to explain, data GET is built from API query
+		Getters map[string]string `json:"getters"` // sample filter getters for API query
+		DataGet types.DataGet     `json:"dataGet"` // data GET to explain (field/collection queries)
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if req.ApiId.Valid {
+		var err error
+		req.DataGet, err = getDataGetFromApi(req.ApiId.Bytes, req.Getters, loginId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data.Explain_tx(ctx, tx, req.DataGet, loginId)
+}
+
+// returns data GET request for API query, as executed by API GET call without consumer getters
+func getDataGetFromApi(apiId uuid.UUID, getters map[string]string, loginId int64) (types.DataGet, error) {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	api, exists := cache.ApiIdMap[apiId]
+	if !exists {
+		return types.DataGet{}, handler.ErrSchemaUnknownApi(apiId)
+	}
+	mod, exists := cache.ModuleIdMap[api.ModuleId]
+	if !exists {
+		return types.DataGet{}, handler.ErrSchemaUnknownModule(api.ModuleId)
+	}
+
+	dataGet := types.DataGet{
+		RelationId:  api.Query.RelationId.Bytes,
+		IndexSource: 0,
+		Joins:       data_query.ConvertApiQueryToDataJoins(api.Query.Joins),
+		Filters: data_query.ConvertQueryToDataFilter(
+			api.Query.Filters, loginId, mod.LanguageMain, getters),
+		Orders:       data_query.ConvertQueryToDataOrders(api.Query.Orders),
+		Limit:        api.LimitDef,
+		Grouping:     api.Query.Grouping.String,
+		GroupingSets: api.Query.GroupingSets,
+	}
+	if api.Query.FixedLimit != 0 && api.Query.FixedLimit < dataGet.Limit {
+		dataGet.Limit = api.Query.FixedLimit
+	}
+	for _, column := range api.Columns {
+		dataGet.Expressions = append(dataGet.Expressions, data_query.ConvertColumnToExpression(
+			column, loginId, mod.LanguageMain, getters))
+	}
+	return dataGet, nil
+}
+
 // data keys
 func DataGetKeys_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	loginId int64) (interface{}, error) {
@@ -0,0 +1,206 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/types"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var jobQueueNameRx = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+func JobDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	// running jobs cannot be deleted, their executor would update them after completion
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job
+		WHERE id = ANY($1)
+		AND   state <> 'running'
+	`, req.Ids)
+
+	return nil, err
+}
+
+func JobGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		req struct {
+			Limit     int    `json:"limit"`
+			Offset    int    `json:"offset"`
+			QueueName string `json:"queueName"` // filter by queue, empty for all
+			State     string `json:"state"`     // filter by state, empty for all
+		}
+		res struct {
+			Jobs  []types.Job `json:"jobs"`
+			Total int64       `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	// prepare SQL request and arguments
+	sqlArgs := make([]interface{}, 0)
+	sqlWhere := "WHERE TRUE"
+	if req.QueueName != "" {
+		sqlArgs = append(sqlArgs, req.QueueName)
+		sqlWhere = fmt.Sprintf("%s\nAND j.queue_name = $%d", sqlWhere, len(sqlArgs))
+	}
+	if req.State != "" {
+		sqlArgs = append(sqlArgs, req.State)
+		sqlWhere = fmt.Sprintf("%s\nAND j.state = $%d::instance.job_state", sqlWhere, len(sqlArgs))
+	}
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM instance.job AS j
+		%s
+	`, sqlWhere), sqlArgs...).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+
+	sqlArgs = append(sqlArgs, req.Limit, req.Offset)
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT j.id, j.queue_name, j.pg_function_id, j.args, j.state,
+			j.attempt_count, j.date_added, j.date_next, j.date_start,
+			j.date_end, n.name, j.last_error
+		FROM instance.job AS j
+		LEFT JOIN instance_cluster.node AS n ON n.id = j.node_id
+		%s
+		ORDER BY j.id DESC
+		LIMIT $%d
+		OFFSET $%d
+	`, sqlWhere, len(sqlArgs)-1, len(sqlArgs)), sqlArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Jobs = make([]types.Job, 0)
+	for rows.Next() {
+		var j types.Job
+		if err := rows.Scan(&j.Id, &j.QueueName, &j.PgFunctionId, &j.Args,
+			&j.State, &j.AttemptCount, &j.DateAdded, &j.DateNext, &j.DateStart,
+			&j.DateEnd, &j.NodeName, &j.LastError); err != nil {
+
+			return nil, err
+		}
+		res.Jobs = append(res.Jobs, j)
+	}
+	return res, rows.Err()
+}
+
+// resets attempts of failed or waiting jobs, to be executed with the next job run
+func JobReset_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.job
+		SET state = 'waiting', attempt_count = 0, date_next = 0
+		WHERE id = ANY($1)
+		AND   state IN ('waiting','failed')
+	`, req.Ids)
+
+	return nil, err
+}
+
+func JobQueueDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "default" {
+		return nil, fmt.Errorf("default job queue cannot be deleted")
+	}
+
+	var isRunning bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT id
+			FROM instance.job
+			WHERE queue_name = $1
+			AND   state      = 'running'
+		)
+	`, req.Name).Scan(&isRunning); err != nil {
+		return nil, err
+	}
+	if isRunning {
+		return nil, fmt.Errorf("job queue '%s' has running jobs", req.Name)
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job_queue
+		WHERE name = $1
+	`, req.Name)
+
+	return nil, err
+}
+
+func JobQueueGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+	queues := make([]types.JobQueue, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT q.name, q.concurrency_max, q.attempt_max, q.backoff_base, q.timeout,
+			COUNT(j.id) FILTER(WHERE j.state = 'waiting'),
+			COUNT(j.id) FILTER(WHERE j.state = 'running'),
+			COUNT(j.id) FILTER(WHERE j.state = 'failed')
+		FROM instance.job_queue AS q
+		LEFT JOIN instance.job  AS j ON j.queue_name = q.name
+		GROUP BY q.name
+		ORDER BY q.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q types.JobQueue
+		if err := rows.Scan(&q.Name, &q.ConcurrencyMax, &q.AttemptMax, &q.BackoffBase,
+			&q.Timeout, &q.CountWaiting, &q.CountRunning, &q.CountFailed); err != nil {
+
+			return nil, err
+		}
+		queues = append(queues, q)
+	}
+	return queues, rows.Err()
+}
+
+func JobQueueSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.JobQueue
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if !jobQueueNameRx.MatchString(req.Name) {
+		return nil, fmt.Errorf("job queue name must consist of 1-64 lower case letters, digits or underscores")
+	}
+	if req.ConcurrencyMax < 1 || req.AttemptMax < 1 || req.BackoffBase < 0 || req.Timeout < 1 {
+		return nil, fmt.Errorf("invalid job queue options")
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance.job_queue (name, concurrency_max, attempt_max, backoff_base, timeout)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (name) DO UPDATE
+		SET concurrency_max = $2, attempt_max = $3, backoff_base = $4, timeout = $5
+	`, req.Name, req.ConcurrencyMax, req.AttemptMax, req.BackoffBase, req.Timeout)
+
+	return nil, err
+}
@@ -3,6 +3,7 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"r3/data"
 	"r3/log"
 	"r3/types"
 
@@ -36,3 +37,26 @@ func LogGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interfa
 
 	return res, err
 }
+
+func LogGetSlow_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		err error
+		req struct {
+			Limit           int  `json:"limit"`
+			Offset          int  `json:"offset"`
+			OrderByDuration bool `json:"orderByDuration"`
+		}
+		res struct {
+			Entries []types.LogSlowQuery `json:"entries"`
+			Total   int64                `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Entries, res.Total, err = data.GetLogSlow_tx(ctx, tx, req.OrderByDuration, req.Limit, req.Offset)
+
+	return res, err
+}
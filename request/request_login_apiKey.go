@@ -0,0 +1,46 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/login/login_apiKey"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func LoginApiKeyDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_apiKey.Del_tx(ctx, tx, req.Id)
+}
+
+func LoginApiKeyGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		LoginId int64 `json:"loginId"` // 0 for keys of all logins
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return login_apiKey.Get_tx(ctx, tx, req.LoginId)
+}
+
+func LoginApiKeySet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var (
+		err error
+		req types.LoginApiKey
+		res struct {
+			Key string `json:"key"` // only set for new API keys
+		}
+	)
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Key, err = login_apiKey.Set_tx(ctx, tx, req)
+	return res, err
+}
@@ -0,0 +1,114 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func RestSpoolerDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []uuid.UUID `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.rest_spool
+		WHERE id = ANY($1)
+	`, req.Ids)
+
+	return nil, err
+}
+
+func RestSpoolerGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		req struct {
+			FailedOnly bool   `json:"failedOnly"` // only calls that exceeded their attempts
+			Limit      int    `json:"limit"`
+			Offset     int    `json:"offset"`
+			Search     string `json:"search"`
+		}
+		res struct {
+			Calls []types.RestSpool `json:"calls"`
+			Total int64             `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	// prepare SQL request and arguments
+	sqlArgs := make([]interface{}, 0)
+	sqlWhere := "WHERE TRUE"
+	if req.FailedOnly {
+		sqlWhere = fmt.Sprintf("%s\nAND attempt_count >= attempt_max", sqlWhere)
+	}
+	if req.Search != "" {
+		sqlArgs = append(sqlArgs, fmt.Sprintf("%%%s%%", req.Search))
+		sqlWhere = fmt.Sprintf("%s\nAND (url ILIKE $1 OR body ILIKE $1 OR last_error ILIKE $1)", sqlWhere)
+	}
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM instance.rest_spool
+		%s
+	`, sqlWhere), sqlArgs...).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+
+	sqlArgs = append(sqlArgs, req.Limit, req.Offset)
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT id, pg_function_id_callback, webhook_id, method, url, body,
+			skip_verify, attempt_count, attempt_max, backoff_base, status_success,
+			date_added, date_attempt, date_next, last_error, last_status, last_response
+		FROM instance.rest_spool
+		%s
+		ORDER BY date_added DESC
+		LIMIT $%d
+		OFFSET $%d
+	`, sqlWhere, len(sqlArgs)-1, len(sqlArgs)), sqlArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Calls = make([]types.RestSpool, 0)
+	for rows.Next() {
+		var c types.RestSpool
+		if err := rows.Scan(&c.Id, &c.PgFunctionIdCallback, &c.WebhookId, &c.Method,
+			&c.Url, &c.Body, &c.SkipVerify, &c.AttemptCount, &c.AttemptMax,
+			&c.BackoffBase, &c.StatusSuccess, &c.DateAdded, &c.DateAttempt,
+			&c.DateNext, &c.LastError, &c.LastStatus, &c.LastResponse); err != nil {
+
+			return nil, err
+		}
+		res.Calls = append(res.Calls, c)
+	}
+	return res, nil
+}
+
+// resets attempts of spooled calls, to be retried with the next spooler run
+func RestSpoolerReset_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []uuid.UUID `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.rest_spool
+		SET attempt_count = 0, date_next = 0
+		WHERE id = ANY($1)
+	`, req.Ids)
+
+	return nil, err
+}
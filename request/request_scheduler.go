@@ -2,9 +2,13 @@ package request
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
@@ -17,6 +21,8 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	type task struct {
 		Active               bool          `json:"active"`
 		ActiveOnly           bool          `json:"activeOnly"`
+		AlertDuration        int           `json:"alertDuration"`
+		AlertFailCount       int           `json:"alertFailCount"`
 		ClusterMasterOnly    bool          `json:"clusterMasterOnly"`
 		DateAttempt          int64         `json:"dateAttempt"`
 		DateSuccess          int64         `json:"dateSuccess"`
@@ -25,12 +31,15 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 		IntervalValue        int           `json:"intervalValue"`
 		PgFunctionId         uuid.NullUUID `json:"pgFunctionId"`
 		PgFunctionScheduleId uuid.NullUUID `json:"pgFunctionScheduleId"`
+		RoleIds              []uuid.UUID   `json:"roleIds"` // roles allowed to run PG function schedule manually
+		ScheduleId           int64         `json:"scheduleId"`
+		ScheduleIdsUpstream  []int64       `json:"scheduleIdsUpstream"`
 		TaskName             string        `json:"taskName"`
 	}
 	tasks := make([]task, 0)
 
 	rows, err := tx.Query(ctx, `
-		SELECT fs.pg_function_id,
+		SELECT s.id, fs.pg_function_id,
 			s.pg_function_schedule_id,
 			s.date_attempt,
 			s.date_success,
@@ -39,7 +48,19 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 			COALESCE(fs.interval_value,t.interval_seconds),
 			COALESCE(t.cluster_master_only,false),
 			COALESCE(t.active_only,false),
-			COALESCE(t.active,true),(
+			COALESCE(t.active,true),
+			s.alert_duration,
+			s.alert_fail_count,
+			COALESCE((
+				SELECT ARRAY_AGG(d.schedule_id_upstream ORDER BY d.schedule_id_upstream)
+				FROM instance.schedule_dependency AS d
+				WHERE d.schedule_id = s.id
+			),'{}'),
+			COALESCE((
+				SELECT ARRAY_AGG(sr.role_id ORDER BY sr.role_id)
+				FROM instance.schedule_role AS sr
+				WHERE sr.schedule_id = s.id
+			),'{}'),(
 				SELECT JSON_AGG(sub.node)
 				FROM(
 					SELECT JSON_BUILD_OBJECT(
@@ -70,10 +91,11 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	for rows.Next() {
 		var t task
 
-		if err := rows.Scan(&t.PgFunctionId, &t.PgFunctionScheduleId,
+		if err := rows.Scan(&t.ScheduleId, &t.PgFunctionId, &t.PgFunctionScheduleId,
 			&t.DateAttempt, &t.DateSuccess, &t.TaskName, &t.IntervalType,
 			&t.IntervalValue, &t.ClusterMasterOnly, &t.ActiveOnly,
-			&t.Active, &t.NodeMeta); err != nil {
+			&t.Active, &t.AlertDuration, &t.AlertFailCount, &t.ScheduleIdsUpstream,
+			&t.RoleIds, &t.NodeMeta); err != nil {
 
 			return tasks, err
 		}
@@ -81,3 +103,175 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	}
 	return tasks, nil
 }
+
+func schedulerAlertsSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		AlertDuration  int   `json:"alertDuration"`
+		AlertFailCount int   `json:"alertFailCount"`
+		ScheduleId     int64 `json:"scheduleId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if req.AlertDuration < 0 || req.AlertFailCount < 0 {
+		return nil, fmt.Errorf("alert thresholds must not be negative")
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.schedule
+		SET alert_duration = $1, alert_fail_count = $2
+		WHERE id = $3
+	`, req.AlertDuration, req.AlertFailCount, req.ScheduleId)
+	return nil, err
+}
+
+// sets upstream schedules, whose successful completion triggers the schedule
+// dependencies must not form a cycle
+func schedulerDependenciesSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		ScheduleId          int64   `json:"scheduleId"`
+		ScheduleIdsUpstream []int64 `json:"scheduleIdsUpstream"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if slices.Contains(req.ScheduleIdsUpstream, req.ScheduleId) {
+		return nil, fmt.Errorf("task cannot depend on itself")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.schedule_dependency
+		WHERE schedule_id = $1
+	`, req.ScheduleId); err != nil {
+		return nil, err
+	}
+
+	if len(req.ScheduleIdsUpstream) == 0 {
+		return nil, nil
+	}
+
+	// check whether schedule is upstream of any of its new upstream schedules
+	var isCycle bool
+	if err := tx.QueryRow(ctx, `
+		WITH RECURSIVE upstream AS (
+			SELECT schedule_id_upstream AS id
+			FROM instance.schedule_dependency
+			WHERE schedule_id = ANY($1)
+			UNION
+			SELECT d.schedule_id_upstream
+			FROM instance.schedule_dependency AS d
+			JOIN upstream AS u ON u.id = d.schedule_id
+		)
+		SELECT EXISTS(
+			SELECT id
+			FROM upstream
+			WHERE id = $2
+		)
+	`, req.ScheduleIdsUpstream, req.ScheduleId).Scan(&isCycle); err != nil {
+		return nil, err
+	}
+	if isCycle {
+		return nil, fmt.Errorf("task dependencies cannot form a cycle")
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance.schedule_dependency (schedule_id, schedule_id_upstream)
+		SELECT DISTINCT $1::INTEGER, UNNEST($2::INTEGER[])
+	`, req.ScheduleId, req.ScheduleIdsUpstream)
+	return nil, err
+}
+
+// sets roles, whose members may run the PG function schedule manually
+func schedulerRolesSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		RoleIds    []uuid.UUID `json:"roleIds"`
+		ScheduleId int64       `json:"scheduleId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.schedule_role
+		WHERE schedule_id = $1
+	`, req.ScheduleId); err != nil {
+		return nil, err
+	}
+
+	if len(req.RoleIds) == 0 {
+		return nil, nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance.schedule_role (schedule_id, role_id)
+		SELECT DISTINCT $1::INTEGER, UNNEST($2::UUID[])
+		FROM instance.schedule
+		WHERE id = $1
+		AND   pg_function_schedule_id IS NOT NULL
+	`, req.ScheduleId, req.RoleIds)
+	return nil, err
+}
+
+func schedulerRunsGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Limit      int   `json:"limit"`
+		Offset     int   `json:"offset"`
+		ScheduleId int64 `json:"scheduleId"`
+	}
+	type run struct {
+		Args       pgtype.Text `json:"args"` // JSON arguments of manual run
+		DateEnd    pgtype.Int8 `json:"dateEnd"`
+		DateStart  int64       `json:"dateStart"`
+		DurationMs pgtype.Int4 `json:"durationMs"`
+		Error      pgtype.Text `json:"error"`
+		LoginName  pgtype.Text `json:"loginName"` // login that ran task manually
+		NodeName   pgtype.Text `json:"nodeName"`
+		Output     pgtype.Text `json:"output"`
+		Skipped    bool        `json:"skipped"` // not executed, as upstream task failed
+		Success    pgtype.Bool `json:"success"` // NULL if run did not finish (yet)
+	}
+	var res struct {
+		Runs  []run `json:"runs"`
+		Total int64 `json:"total"`
+	}
+	res.Runs = make([]run, 0)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.schedule_run
+		WHERE schedule_id = $1
+	`, req.ScheduleId).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.date_start, r.date_end, r.duration_ms, r.skipped,
+			r.success, r.error, r.output, n.name, r.args, l.name
+		FROM instance.schedule_run AS r
+		LEFT JOIN instance_cluster.node AS n ON n.id = r.node_id
+		LEFT JOIN instance.login        AS l ON l.id = r.login_id
+		WHERE r.schedule_id = $1
+		ORDER BY r.id DESC
+		LIMIT $2
+		OFFSET $3
+	`, req.ScheduleId, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r run
+		if err := rows.Scan(&r.DateStart, &r.DateEnd, &r.DurationMs, &r.Skipped,
+			&r.Success, &r.Error, &r.Output, &r.NodeName, &r.Args, &r.LoginName); err != nil {
+
+			return nil, err
+		}
+		res.Runs = append(res.Runs, r)
+	}
+	return res, rows.Err()
+}
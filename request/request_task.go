@@ -3,7 +3,11 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/scheduler"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -61,3 +65,60 @@ func TaskRun_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interf
 
 	return nil, err
 }
+
+// returns IDs of PG function schedules, that the login may run manually via its roles
+func TaskGetManual_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	access, err := cache.GetAccessById(loginId)
+	if err != nil {
+		return nil, err
+	}
+
+	pgFunctionScheduleIds := make([]uuid.UUID, 0)
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(DISTINCT s.pg_function_schedule_id), '{}')
+		FROM instance.schedule_role AS sr
+		JOIN instance.schedule      AS s ON s.id = sr.schedule_id
+		WHERE s.pg_function_schedule_id IS NOT NULL
+		AND   sr.role_id = ANY($1)
+	`, access.RoleIds).Scan(&pgFunctionScheduleIds)
+
+	return pgFunctionScheduleIds, err
+}
+
+// runs PG function of schedule manually on this node, with optional arguments
+// admins may run any schedule, other logins need a role assigned to the schedule
+func TaskRunManual_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64, isAdmin bool) (interface{}, error) {
+
+	var req struct {
+		Args                 []interface{} `json:"args"`
+		DryRun               bool          `json:"dryRun"`
+		PgFunctionScheduleId uuid.UUID     `json:"pgFunctionScheduleId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if !isAdmin {
+		access, err := cache.GetAccessById(loginId)
+		if err != nil {
+			return nil, err
+		}
+
+		var allowed bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT sr.role_id
+				FROM instance.schedule_role AS sr
+				JOIN instance.schedule      AS s ON s.id = sr.schedule_id
+				WHERE s.pg_function_schedule_id = $1
+				AND   sr.role_id = ANY($2)
+			)
+		`, req.PgFunctionScheduleId, access.RoleIds).Scan(&allowed); err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New(handler.ErrUnauthorized)
+		}
+	}
+	return scheduler.RunPgFunctionManual(req.PgFunctionScheduleId, loginId, req.Args, req.DryRun)
+}
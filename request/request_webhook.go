@@ -0,0 +1,32 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/schema/webhook"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func WebhookDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, webhook.Del_tx(ctx, tx, req.Id)
+}
+
+func WebhookSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.Webhook
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, webhook.Set_tx(ctx, tx, req)
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
@@ -17,12 +18,15 @@ import (
 	"r3/repo"
 	"r3/schema"
 	"r3/spooler/file_process"
+	"r3/spooler/job_run"
 	"r3/spooler/mail_attach"
 	"r3/spooler/mail_receive"
 	"r3/spooler/mail_send"
 	"r3/spooler/rest_send"
 	"r3/tools"
+	"r3/tools/cron"
 	"r3/transfer"
+	"r3/types"
 	"slices"
 	"sync"
 	"sync/atomic"
@@ -53,9 +57,22 @@ type taskSchedule struct {
 	id                int64  // schedule ID
 	clusterMasterOnly bool   // schedule only to be executed by cluster master (instead of by all nodes)
 	interval          int64  // execution interval
-	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once)
+	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once, cron)
 	runLastUnix       int64  // unix time of last execution time of this schedule
 
+	// PG function schedule specific
+	blackouts []types.PgFunctionScheduleBlackout // blackout windows, in which executions are skipped
+	cron      cron.Expression                    // cron expression for interval type cron
+	location  *time.Location                     // time zone to evaluate schedule in, server local time if nil
+
+	// IDs of upstream schedules, if set schedule is triggered by their successful completion instead of its interval
+	upstreamIds []int64
+	downstream  bool // schedule is upstream of other schedules, its runs trigger them
+
+	// alerts, 0 if disabled
+	alertDuration  int64 // alert if execution runs longer than x seconds
+	alertFailCount int   // alert if execution failed x times in a row
+
 	// target day for interval types weeks/months
 	atDay int
 
@@ -72,7 +89,7 @@ var (
 	nextExecutionUnix       int64          = 0    // unix time of next (earliest) task to run
 	oneDayInSeconds         int64          = 60 * 60 * 24
 	tasks                   []task         // all tasks
-	tasksDisabledMirrorMode []string       = []string{"adminMails", "backupRun", "mailAttach", "mailRetrieve", "mailSend", "restExecute"}
+	tasksDisabledMirrorMode []string       = []string{"adminMails", "backupRun", "jobRun", "mailAttach", "mailRetrieve", "mailSend", "restExecute"}
 	OsExit                  chan os.Signal = make(chan os.Signal)
 
 	// main loop
@@ -139,6 +156,15 @@ func runTasksBySchedule() error {
 		return nil
 	}
 
+	// trigger tasks whose upstream tasks completed
+	if dependencyCheckNow || tools.GetTimeUnix() >= dependencyCheckNextUnix {
+		if err := triggerDependentTasks(); err != nil {
+			log.Error(log.ContextScheduler, "failed to check task dependencies", err)
+		}
+		dependencyCheckNow = false
+		dependencyCheckNextUnix = tools.GetTimeUnix() + dependencyCheckInterval
+	}
+
 	// get earliest unix time for any task to run
 	if nextExecutionUnix == 0 {
 
@@ -228,8 +254,6 @@ func runTaskByIndex(taskIndex int) {
 	change_mx.Unlock()
 
 	// run task and store schedule meta data
-	var err error
-
 	log.Info(log.ContextScheduler, fmt.Sprintf("task '%s' started (scheduled for: %s)",
 		t.nameLog, time.Unix(t.runNextUnix, 0)))
 
@@ -238,10 +262,63 @@ func runTaskByIndex(taskIndex int) {
 			t.nameLog), err)
 	}
 
+	// store execution in run history
+	// system tasks run frequently, their successful runs are only stored if alerts or dependencies rely on them
+	s := t.taskSchedule
+	if !t.isSystemTask {
+		s = t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext]
+	}
+	storeRuns := !t.isSystemTask || s.alertDuration > 0 || s.alertFailCount > 0 ||
+		len(s.upstreamIds) != 0 || s.downstream
+
+	var runId int64
+	var err error
+	dateStart := tools.GetTimeUnix()
+	if storeRuns {
+		runId, err = runStart(s.id, dateStart)
+		if err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run", t.nameLog), err)
+		}
+	}
+
+	// alert once if execution takes longer than expected
+	var alertTimer *time.Timer
+	if s.alertDuration > 0 {
+		alertTimer = time.AfterFunc(time.Duration(s.alertDuration)*time.Second, func() {
+			runAlertOnDuration(t, s)
+		})
+	}
+
+	var output []string
+	timeStart := time.Now()
+
 	if t.isSystemTask {
 		err = t.fn()
 	} else {
-		err = runPgFunction(t.pgFunctionId)
+		output, err = runPgFunction(t.pgFunctionId)
+	}
+
+	if alertTimer != nil {
+		alertTimer.Stop()
+	}
+
+	// failed runs are always stored
+	if !storeRuns && err != nil {
+		var errStore error
+		runId, errStore = runStart(s.id, dateStart)
+		if errStore != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run", t.nameLog), errStore)
+		}
+	}
+	if runId != 0 {
+		if err := runEnd(runId, time.Since(timeStart), err, output); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run", t.nameLog), err)
+		}
+	}
+	if err != nil && s.alertFailCount > 0 {
+		if err := runAlertOnFailures(t, s, err); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to send alert", t.nameLog), err)
+		}
 	}
 
 	if err == nil {
@@ -267,10 +344,12 @@ func runTaskByIndex(taskIndex int) {
 	t.running = false
 
 	// update task list if it was not reloaded during execution
+	// check dependent tasks right away, as this task might have been their upstream
 	change_mx.Lock()
 	if loadCounterPre == loadCounter {
 		tasks[taskIndex] = t
 	}
+	dependencyCheckNow = true
 	change_mx.Unlock()
 }
 
@@ -279,9 +358,14 @@ func load() error {
 	tasks = nil
 
 	// get system tasks and their states
-	rows, err := db.Pool.Query(context.Background(), `
+	rows, err := db.Pool.Query(context.Background(), fmt.Sprintf(`
 		SELECT t.name, t.embedded_only, t.interval_seconds,
-			t.cluster_master_only, s.id, s.date_attempt, ns.date_attempt
+			t.cluster_master_only, s.id, s.date_attempt, ns.date_attempt,
+			s.alert_duration, s.alert_fail_count, (%s), EXISTS(
+				SELECT 1
+				FROM instance.schedule_dependency
+				WHERE schedule_id_upstream = s.id
+			)
 		FROM instance.task AS t
 		INNER JOIN instance.schedule AS s
 			ON s.task_name = t.name
@@ -289,7 +373,7 @@ func load() error {
 			ON  ns.schedule_id = s.id
 			AND ns.node_id     = $1
 		WHERE t.active
-	`, cache.GetNodeId())
+	`, dependencyUpstreamIdsQuery), cache.GetNodeId())
 	if err != nil {
 		return err
 	}
@@ -301,7 +385,8 @@ func load() error {
 		var runLastUnixNode pgtype.Int8
 
 		if err := rows.Scan(&t.name, &embeddedOnly, &s.interval,
-			&s.clusterMasterOnly, &s.id, &s.runLastUnix, &runLastUnixNode); err != nil {
+			&s.clusterMasterOnly, &s.id, &s.runLastUnix, &runLastUnixNode,
+			&s.alertDuration, &s.alertFailCount, &s.upstreamIds, &s.downstream); err != nil {
 
 			return err
 		}
@@ -345,6 +430,12 @@ func load() error {
 		case "backupRun":
 			t.nameLog = "Integrated full backups"
 			t.fn = backup.Run
+		case "cleanupApiIdempotency":
+			t.nameLog = "Cleanup of expired API idempotency keys"
+			t.fn = cleanupApiIdempotency
+		case "cleanupApiRates":
+			t.nameLog = "Cleanup of unused API rate limit buckets"
+			t.fn = cleanupApiRates
 		case "cleanupBruteforce":
 			t.nameLog = "Cleanup of bruteforce cache"
 			t.fn = bruteforce.ClearHostMap
@@ -354,6 +445,9 @@ func load() error {
 		case "cleanupDataLogs":
 			t.nameLog = "Cleanup of data change logs"
 			t.fn = data.DelLogsBackground
+		case "cleanupJobs":
+			t.nameLog = "Cleanup of finished jobs"
+			t.fn = cleanupJobs
 		case "cleanupLogs":
 			t.nameLog = "Cleanup of system logs"
 			t.fn = cleanupLogs
@@ -363,6 +457,15 @@ func load() error {
 		case "cleanupMailTraffic":
 			t.nameLog = "Cleanup of mail traffic entries"
 			t.fn = cleanupMailTraffic
+		case "cleanupSlowQueries":
+			t.nameLog = "Cleanup of slow query log entries"
+			t.fn = cleanupSlowQueries
+		case "cleanupTaskRuns":
+			t.nameLog = "Cleanup of task run history"
+			t.fn = cleanupTaskRuns
+		case "cleanupTrash":
+			t.nameLog = "Cleanup of expired soft deleted records"
+			t.fn = cleanupTrash
 		case "clusterCheckIn":
 			t.nameLog = "Cluster node check-in to database"
 			t.fn = cluster.CheckInNode
@@ -381,6 +484,9 @@ func load() error {
 		case "importLdapLogins":
 			t.nameLog = "Import from LDAP connections"
 			t.fn = ldap_import.RunAll
+		case "jobRun":
+			t.nameLog = "Job queue execution"
+			t.fn = job_run.DoAll
 		case "mailAttach":
 			t.nameLog = "Email attachment transfer"
 			t.fn = mail_attach.DoAll
@@ -413,10 +519,19 @@ func load() error {
 	if cache.GetIsClusterMaster() {
 		pgFunctionIdMapTasks := make(map[uuid.UUID]task)
 
-		rows, err = db.Pool.Query(context.Background(), `
+		rows, err = db.Pool.Query(context.Background(), fmt.Sprintf(`
 			SELECT f.name, fs.pg_function_id, fs.id, fs.at_hour, fs.at_minute,
 				fs.at_second, fs.at_day, fs.interval_type, fs.interval_value,
-				s.id, s.date_attempt
+				fs.cron, fs.time_zone, s.id, s.date_attempt, s.alert_duration,
+				s.alert_fail_count, COALESCE((
+					SELECT JSON_AGG(JSON_BUILD_OBJECT(
+						'dateFrom',b.date_from,
+						'dateTo',b.date_to,
+						'yearly',b.yearly
+					) ORDER BY b.position ASC)
+					FROM app.pg_function_schedule_blackout AS b
+					WHERE b.pg_function_schedule_id = fs.id
+				),'[]'), (%s)
 			FROM app.pg_function AS f
 			INNER JOIN app.pg_function_schedule AS fs ON fs.pg_function_id = f.id
 			INNER JOIN instance.schedule AS s
@@ -425,7 +540,7 @@ func load() error {
 					s.date_attempt = 0
 					OR fs.interval_type <> 'once'
 				)
-		`)
+		`, dependencyUpstreamIdsQuery))
 		if err != nil {
 			return err
 		}
@@ -434,17 +549,34 @@ func load() error {
 			var t task
 			var s taskSchedule
 			var pgFunctionScheduleId uuid.UUID
+			var cronExpr, timeZone pgtype.Text
 
 			t.pgFunctionScheduleIdMap = make(map[uuid.UUID]taskSchedule)
 
 			if err := rows.Scan(&t.name, &t.pgFunctionId, &pgFunctionScheduleId,
 				&s.atHour, &s.atMinute, &s.atSecond, &s.atDay, &s.intervalType,
-				&s.interval, &s.id, &s.runLastUnix); err != nil {
+				&s.interval, &cronExpr, &timeZone, &s.id, &s.runLastUnix,
+				&s.alertDuration, &s.alertFailCount, &s.blackouts, &s.upstreamIds); err != nil {
 
 				return err
 			}
 			t.nameLog = t.name
 
+			// invalid schedules are rejected when the schema is saved
+			// they can still occur if time zone data is missing on this system
+			if timeZone.Valid {
+				if s.location, err = time.LoadLocation(timeZone.String); err != nil {
+					log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' has schedule with unknown time zone, ignoring it", t.nameLog), err)
+					continue
+				}
+			}
+			if s.intervalType == "cron" {
+				if s.cron, err = cron.Parse(cronExpr.String); err != nil {
+					log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' has schedule with invalid cron expression, ignoring it", t.nameLog), err)
+					continue
+				}
+			}
+
 			if _, exists := pgFunctionIdMapTasks[t.pgFunctionId]; exists {
 				t = pgFunctionIdMapTasks[t.pgFunctionId]
 			}
@@ -465,25 +597,45 @@ func load() error {
 }
 
 // helpers
-func runPgFunction(pgFunctionId uuid.UUID) error {
+// executes PG function, returns notices raised during execution
+func runPgFunction(pgFunctionId uuid.UUID) ([]string, error) {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc)
 	defer ctxCanc()
 
-	tx, err := db.Pool.Begin(ctx)
+	// dedicated connection to capture notices of this execution
+	con, err := db.Pool.Acquire(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer con.Release()
+
+	noticesGet := db.CaptureNotices(con.Conn())
+
+	tx, err := con.Begin(ctx)
+	if err != nil {
+		return noticesGet(), err
 	}
 	defer tx.Rollback(ctx)
 
+	// wait for other runs of the same PG function (like manual runs) to finish
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, getPgFunctionLockKey(pgFunctionId)); err != nil {
+		return noticesGet(), err
+	}
+
 	modName, fncName, _, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, pgFunctionId)
 	if err != nil {
-		return err
+		return noticesGet(), err
 	}
 
 	if _, err := tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, modName, fncName)); err != nil {
-		return err
+		return noticesGet(), err
 	}
-	return tx.Commit(ctx)
+	return noticesGet(), tx.Commit(ctx)
+}
+
+// advisory lock key of PG function, shared by all runs of it on all nodes
+func getPgFunctionLockKey(pgFunctionId uuid.UUID) int64 {
+	return int64(binary.BigEndian.Uint64(pgFunctionId.Bytes()[:8]))
 }
 
 // get unix time and index of task schedule to run next
@@ -506,8 +658,57 @@ func getNextRunScheduleFromTask(t task) (int64, uuid.UUID) {
 	return nextRun, nextRunId
 }
 
+// time zone to evaluate schedule in, server local time if not defined
+func (s taskSchedule) getLocation() *time.Location {
+	if s.location == nil {
+		return time.Local
+	}
+	return s.location
+}
+
 func getNextRunFromSchedule(s taskSchedule) int64 {
 
+	// schedule is triggered by its upstream schedules, not by time
+	if len(s.upstreamIds) != 0 {
+		return -1
+	}
+	nextRun := getNextRunFromInterval(s)
+
+	// skip executions within blackout windows
+	for i := 0; i < blackoutSkipsMax; i++ {
+		if nextRun <= 0 {
+			return nextRun
+		}
+		blackoutEnd, inBlackout := getBlackoutEnd(s, nextRun)
+		if !inBlackout {
+			return nextRun
+		}
+
+		switch s.intervalType {
+		case "once":
+			// single execution is postponed until after blackout window
+			return blackoutEnd
+		case "seconds", "minutes", "hours":
+			// keep interval steps, continue with first step after blackout window
+			step := nextRun - s.runLastUnix
+			if step < 1 {
+				return blackoutEnd
+			}
+			nextRun += ((blackoutEnd - nextRun + step - 1) / step) * step
+		case "cron":
+			s.runLastUnix = blackoutEnd - 1
+			nextRun = getNextRunFromInterval(s)
+		default:
+			// continue as if skipped execution took place
+			s.runLastUnix = nextRun
+			nextRun = getNextRunFromInterval(s)
+		}
+	}
+	return -1 // no execution outside of blackout windows found
+}
+
+func getNextRunFromInterval(s taskSchedule) int64 {
+
 	// run without schedule, just once
 	if s.intervalType == "once" {
 		if s.runLastUnix != 0 {
@@ -516,6 +717,20 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 		return tools.GetTimeUnix()
 	}
 
+	// cron expression, next match after last run
+	// schedule never ran, next match from now on
+	if s.intervalType == "cron" {
+		after := time.Unix(s.runLastUnix, 0)
+		if s.runLastUnix == 0 {
+			after = time.Now()
+		}
+		tm, ok := s.cron.Next(after.In(s.getLocation()))
+		if !ok {
+			return -1
+		}
+		return tm.Unix()
+	}
+
 	// simple intervals, just add seconds
 	switch s.intervalType {
 	case "seconds":
@@ -527,8 +742,8 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 	}
 
 	// more complex intervals, add dates and set to target day/time
-	// as no timezone is defined, tm will be in local time, which will affect all date operations
-	tm := time.Unix(s.runLastUnix, 0)
+	// tm is in the schedule time zone (or local time), which affects all date operations
+	tm := time.Unix(s.runLastUnix, 0).In(s.getLocation())
 
 	switch s.intervalType {
 	case "days":
@@ -563,7 +778,7 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 		targetMonth = 1
 	}
 
-	// apply target month/day and time at local time
+	// apply target month/day and time at schedule time zone
 	tm = time.Date(tm.Year(), targetMonth, targetDay, s.atHour, s.atMinute,
 		s.atSecond, 0, tm.Location())
 
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// max. number of executions to skip due to blackout windows before a schedule is considered stopped
+const blackoutSkipsMax = 10000
+
+// checks whether execution time is within a blackout window of the schedule
+// blackout windows cover full days in the schedule time zone
+// returns start of the day after the blackout window (unix) if execution time is within it
+func getBlackoutEnd(s taskSchedule, runUnix int64) (int64, bool) {
+	loc := s.getLocation()
+	tm := time.Unix(runUnix, 0).In(loc)
+	year, month, day := tm.Date()
+	dayKey := getBlackoutDayKey(0, month, day)
+
+	for _, b := range s.blackouts {
+		// blackout dates are stored as unix time of the date at UTC midnight
+		fromYear, fromMonth, fromDay := time.Unix(b.DateFrom, 0).UTC().Date()
+		toYear, toMonth, toDay := time.Unix(b.DateTo, 0).UTC().Date()
+
+		if !b.Yearly {
+			runKey := getBlackoutDayKey(year, month, day)
+			if runKey >= getBlackoutDayKey(fromYear, fromMonth, fromDay) &&
+				runKey <= getBlackoutDayKey(toYear, toMonth, toDay) {
+
+				return time.Date(toYear, toMonth, toDay+1, 0, 0, 0, 0, loc).Unix(), true
+			}
+			continue
+		}
+
+		// yearly windows only compare month & day, they can span the turn of the year (Dec 24 - Jan 2)
+		fromKey := getBlackoutDayKey(0, fromMonth, fromDay)
+		toKey := getBlackoutDayKey(0, toMonth, toDay)
+
+		if fromKey <= toKey {
+			if dayKey >= fromKey && dayKey <= toKey {
+				return time.Date(year, toMonth, toDay+1, 0, 0, 0, 0, loc).Unix(), true
+			}
+			continue
+		}
+		if dayKey >= fromKey {
+			return time.Date(year+1, toMonth, toDay+1, 0, 0, 0, 0, loc).Unix(), true
+		}
+		if dayKey <= toKey {
+			return time.Date(year, toMonth, toDay+1, 0, 0, 0, 0, loc).Unix(), true
+		}
+	}
+	return 0, false
+}
+
+func getBlackoutDayKey(year int, month time.Month, day int) int {
+	return year*10000 + int(month)*100 + day
+}
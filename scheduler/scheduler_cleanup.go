@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"r3/cache"
 	"r3/config"
 	"r3/data"
 	"r3/db"
@@ -67,6 +68,18 @@ func cleanupLogs() error {
 	return err
 }
 
+// deletes expired idempotency keys of REST API calls
+func cleanupApiIdempotency() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.api_idempotency
+		WHERE date_created < $1
+	`, tools.GetTimeUnix()-(int64(config.GetUint64("apiIdempotencyHours"))*3600))
+	return err
+}
+
 // deletes expired mail traffic entries
 func cleanupMailTraffic() error {
 	keepForDays := config.GetUint64("mailTrafficKeepDays")
@@ -84,6 +97,107 @@ func cleanupMailTraffic() error {
 	return err
 }
 
+// deletes expired slow query log entries
+func cleanupSlowQueries() error {
+	keepForDays := config.GetUint64("slowQueryKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.log_slow_query
+		WHERE date_milli < $1
+	`, (tools.GetTimeUnix()-(oneDayInSeconds*int64(keepForDays)))*1000)
+	return err
+}
+
+// permanently deletes soft deleted records from trash bins of relations with purge setting
+func cleanupTrash() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	relationIdMapDays := make(map[uuid.UUID]int64)
+	cache.Schema_mx.RLock()
+	for _, rel := range cache.RelationIdMap {
+		if rel.SoftDelete && rel.SoftDeleteDays.Valid {
+			relationIdMapDays[rel.Id] = int64(rel.SoftDeleteDays.Int32)
+		}
+	}
+	cache.Schema_mx.RUnlock()
+
+	for relationId, days := range relationIdMapDays {
+		tx, err := db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if err := data.DelTrash_tx(ctx, tx, relationId, tools.GetTimeUnix()-(oneDayInSeconds*days)); err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletes API rate limit buckets, that were not used for a while
+// unused buckets are full, removing them does not change rate limiting
+func cleanupApiRates() error {
+	keepForDays := config.GetUint64("apiRateKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	// bucket dates are stored in milliseconds
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance_cluster.api_rate
+		WHERE date_update < $1
+	`, (tools.GetTimeUnix()-(oneDayInSeconds*int64(keepForDays)))*1000)
+	return err
+}
+
+// deletes finished jobs (done or failed) from job queue
+func cleanupJobs() error {
+	keepForDays := config.GetUint64("jobsKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.job
+		WHERE state IN ('done','failed')
+		AND   date_end < $1
+	`, tools.GetTimeUnix()-(oneDayInSeconds*int64(keepForDays)))
+	return err
+}
+
+// deletes expired task run history entries
+func cleanupTaskRuns() error {
+	keepForDays := config.GetUint64("taskRunsKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.schedule_run
+		WHERE date_start < $1
+	`, tools.GetTimeUnix()-(oneDayInSeconds*int64(keepForDays)))
+	return err
+}
+
 // removes files that were deleted from their attribute or that are not assigned to a record
 func cleanUpFiles() error {
 
@@ -101,11 +215,29 @@ func cleanUpFiles() error {
 	}
 
 	for _, atrId := range attributeIdsFile {
+
+		// files of soft deleted records are kept, until their record is purged from the trash bin
+		trashFilter := ""
+		cache.Schema_mx.RLock()
+		if atr, exists := cache.AttributeIdMap[atrId]; exists {
+			rel := cache.RelationIdMap[atr.RelationId]
+			if rel.SoftDelete {
+				trashFilter = fmt.Sprintf(`AND record_id NOT IN (
+					SELECT "%s"
+					FROM "%s"."%s"
+					WHERE "%s" IS NOT NULL
+				)`, schema.PkName, cache.ModuleIdMap[rel.ModuleId].Name,
+					rel.Name, schema.SoftDeleteName)
+			}
+		}
+		cache.Schema_mx.RUnlock()
+
 		if _, err := db.Pool.Exec(context.Background(), fmt.Sprintf(`
 			DELETE FROM instance_file."%s"
 			WHERE date_delete IS NOT NULL
 			AND   date_delete < $1
-		`, schema.GetFilesTableName(atrId)), keepFilesUntil); err != nil {
+			%s
+		`, schema.GetFilesTableName(atrId), trashFilter), keepFilesUntil); err != nil {
 			return err
 		}
 	}
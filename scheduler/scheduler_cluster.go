@@ -65,9 +65,10 @@ func clusterProcessEvents() error {
 
 	// react to collected events
 	collectionUpdates := make([]types.ClusterEventCollectionUpdated, 0)
+	relationIdMapChanged := make(map[uuid.UUID]uuid.UUID) // value: client ID that originated change, nil UUID if none or multiple
 
 	for _, e := range events {
-		if err := clusterProcessEvent(ctx, tx, e, &collectionUpdates); err != nil {
+		if err := clusterProcessEvent(ctx, tx, e, &collectionUpdates, relationIdMapChanged); err != nil {
 			return err
 		}
 	}
@@ -75,10 +76,14 @@ func clusterProcessEvents() error {
 	// apply collection updates
 	cluster.CollectionsUpdated(collectionUpdates)
 
+	// inform clients about changed data, once per relation as bulk changes can create many identical events
+	cluster.DataChanged(relationIdMapChanged)
+
 	return tx.Commit(ctx)
 }
 
-func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, collectionUpdates *[]types.ClusterEventCollectionUpdated) error {
+func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent,
+	collectionUpdates *[]types.ClusterEventCollectionUpdated, relationIdMapChanged map[uuid.UUID]uuid.UUID) error {
 
 	log.Info(log.ContextCluster, fmt.Sprintf("node is reacting to event '%s'", e.Content))
 	var err error
@@ -105,6 +110,18 @@ func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, c
 			return err
 		}
 		err = cluster.ConfigChanged_tx(ctx, tx, false, true, switchToMaintenance)
+	case "dataChanged":
+		var p types.ClusterEventDataChanged
+		if err := json.Unmarshal(jsonPayload, &p); err != nil {
+			return err
+		}
+		// originating client is only skipped if all merged changes came from it
+		if clientId, exists := relationIdMapChanged[p.RelationId]; exists && clientId != p.ClientIdOrigin {
+			relationIdMapChanged[p.RelationId] = uuid.Nil
+		} else {
+			relationIdMapChanged[p.RelationId] = p.ClientIdOrigin
+		}
+		err = nil
 	case "filesCopied":
 		var p types.ClusterEventFilesCopied
 		if err := json.Unmarshal(jsonPayload, &p); err != nil {
@@ -144,6 +161,13 @@ func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, c
 			return err
 		}
 		err = cluster.MasterAssigned(p.State)
+	case "recordLockChanged":
+		var p types.ClusterEventRecordLockChanged
+		if err := json.Unmarshal(jsonPayload, &p); err != nil {
+			return err
+		}
+		cluster.RecordLockChanged(p)
+		err = nil
 	case "schemaChanged":
 		var moduleIds []uuid.UUID
 		if err := json.Unmarshal(jsonPayload, &moduleIds); err != nil {
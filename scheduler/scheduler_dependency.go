@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// schedules with upstream schedules are not triggered by time, but by the completion of all their upstream schedules
+// upstream completion is read from the run history, which is written by all cluster nodes
+// runs of tasks executed by all nodes (node schedules) are only considered on the same node
+// if any upstream schedule failed, the dependent schedule is skipped - which also skips its own dependents
+
+// upstream schedule IDs of a schedule, used in task load queries (alias 's' = instance.schedule)
+const dependencyUpstreamIdsQuery = `
+	SELECT COALESCE(ARRAY_AGG(d.schedule_id_upstream), '{}')
+	FROM instance.schedule_dependency AS d
+	WHERE d.schedule_id = s.id
+`
+
+var (
+	dependencyCheckInterval int64 = 5     // seconds between checks, to catch upstream runs of other cluster nodes
+	dependencyCheckNextUnix int64 = 0     // unix time of next dependency check
+	dependencyCheckNow      bool  = false // check on next scheduler loop, set when a task finished on this node
+)
+
+// triggers or skips dependent schedules, whose upstream schedules all completed since their last run
+// must be called while holding the change lock
+func triggerDependentTasks() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	for i, t := range tasks {
+		if t.running {
+			continue
+		}
+
+		// system tasks have a single schedule
+		schedules := t.pgFunctionScheduleIdMap
+		if t.isSystemTask {
+			schedules = map[uuid.UUID]taskSchedule{uuid.Nil: t.taskSchedule}
+		}
+
+		for pgFunctionScheduleId, s := range schedules {
+			if len(s.upstreamIds) == 0 {
+				continue
+			}
+
+			completed, failedNames, err := getUpstreamState(ctx, t, s)
+			if err != nil {
+				return err
+			}
+			if !completed {
+				continue
+			}
+
+			if len(failedNames) != 0 {
+				reason := fmt.Sprintf("skipped, upstream task(s) failed: %s", strings.Join(failedNames, ", "))
+				log.Info(log.ContextScheduler, fmt.Sprintf("task '%s' %s", t.nameLog, reason))
+
+				if !t.isSystemTask {
+					t.pgFunctionScheduleIdNext = pgFunctionScheduleId
+				}
+				if err := skipDependentSchedule(ctx, t, s, reason); err != nil {
+					return err
+				}
+				continue
+			}
+
+			log.Info(log.ContextScheduler, fmt.Sprintf("task '%s' triggered by completion of its upstream tasks", t.nameLog))
+
+			tasks[i].runNextUnix = tools.GetTimeUnix()
+			if !t.isSystemTask {
+				tasks[i].pgFunctionScheduleIdNext = pgFunctionScheduleId
+			}
+			nextExecutionUnix = 0
+			break
+		}
+	}
+	return nil
+}
+
+// returns whether all upstream schedules completed a run since the last run of the dependent schedule
+// returns names of upstream tasks whose latest run failed
+func getUpstreamState(ctx context.Context, t task, s taskSchedule) (bool, []string, error) {
+
+	// runs of node schedules are only compared on the same node
+	nodeOnly := t.isSystemTask && !s.clusterMasterOnly
+
+	var runIdLast int64
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(MAX(id),0)
+		FROM instance.schedule_run
+		WHERE schedule_id = $1
+		AND (NOT $2 OR node_id = $3)
+	`, s.id, nodeOnly, cache.GetNodeId()).Scan(&runIdLast); err != nil {
+		return false, nil, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT COALESCE(t.name, f.name, ''), r.id, r.success
+		FROM instance.schedule AS s
+		LEFT JOIN instance.task                AS t  ON t.name  = s.task_name
+		LEFT JOIN app.pg_function_schedule     AS fs ON fs.id   = s.pg_function_schedule_id
+		LEFT JOIN app.pg_function              AS f  ON f.id    = fs.pg_function_id
+		LEFT JOIN LATERAL (
+			SELECT id, success
+			FROM instance.schedule_run
+			WHERE schedule_id = s.id
+			AND   id          > $2
+			AND (
+				COALESCE(t.cluster_master_only, TRUE)
+				OR node_id = $3
+			)
+			ORDER BY id DESC
+			LIMIT 1
+		) AS r ON TRUE
+		WHERE s.id = ANY($1)
+	`, s.upstreamIds, runIdLast, cache.GetNodeId())
+	if err != nil {
+		return false, nil, err
+	}
+	defer rows.Close()
+
+	completed := true
+	failedNames := make([]string, 0)
+	for rows.Next() {
+		var name string
+		var runId pgtype.Int8
+		var success pgtype.Bool
+
+		if err := rows.Scan(&name, &runId, &success); err != nil {
+			return false, nil, err
+		}
+
+		// no run since last run of dependent schedule or run not finished yet
+		if !runId.Valid || !success.Valid {
+			completed = false
+			continue
+		}
+		if !success.Bool {
+			failedNames = append(failedNames, name)
+		}
+	}
+	return completed, failedNames, rows.Err()
+}
+
+// stores skipped execution of dependent schedule in its run history
+// skipped runs count as failed runs for their own dependents
+func skipDependentSchedule(ctx context.Context, t task, s taskSchedule, reason string) error {
+	now := tools.GetTimeUnix()
+
+	if _, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.schedule_run (schedule_id, node_id, date_start,
+			date_end, duration_ms, success, error, skipped)
+		VALUES ($1,$2,$3,$3,0,FALSE,$4,TRUE)
+	`, s.id, cache.GetNodeId(), now, reason); err != nil {
+		return err
+	}
+	return storeTaskDate(t, "attempt")
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/log"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// manual runs execute the PG function of a schedule directly on this node, with optional arguments
+// they are executed as the triggering login and do not affect the regular schedule timing
+// dry runs are always rolled back, they are not stored in the run history
+// sequence values and changes outside the database (mails, REST calls done by the database) are not rolled back
+// all runs of a PG function (scheduled, manual or dry, on all nodes) hold a transaction level advisory lock of it
+// manual runs are rejected while this lock is held, scheduled runs wait until the lock is released
+// scheduled runs of the PG function on this node are also held back until the manual run is done
+
+// row change counters of all tables, changed within the current transaction
+const manualRunRowCountsQuery = `
+	SELECT schemaname, relname, n_tup_ins, n_tup_upd, n_tup_del
+	FROM pg_catalog.pg_stat_xact_user_tables
+	WHERE n_tup_ins <> 0
+	OR    n_tup_upd <> 0
+	OR    n_tup_del <> 0
+`
+
+func RunPgFunctionManual(pgFunctionScheduleId uuid.UUID, loginId int64, args []interface{}, dryRun bool) (types.TaskRunManualResult, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc)
+	defer ctxCanc()
+
+	res := types.TaskRunManualResult{
+		DryRun:    dryRun,
+		Notices:   make([]string, 0),
+		RowCounts: make([]types.TaskRunManualRowCount, 0),
+	}
+
+	var scheduleId int64
+	var pgFunctionId uuid.UUID
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT s.id, fs.pg_function_id
+		FROM instance.schedule AS s
+		JOIN app.pg_function_schedule AS fs ON fs.id = s.pg_function_schedule_id
+		WHERE fs.id = $1
+	`, pgFunctionScheduleId).Scan(&scheduleId, &pgFunctionId); err != nil {
+		return res, err
+	}
+
+	// dedicated connection to capture notices of this execution
+	con, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer con.Release()
+
+	noticesGet := db.CaptureNotices(con.Conn())
+
+	tx, err := con.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(ctx)
+
+	taskIndex, loadCounterPre, err := manualRunClaim(ctx, tx, pgFunctionId)
+	if err != nil {
+		return res, err
+	}
+	defer manualRunRelease(taskIndex, loadCounterPre)
+
+	if args == nil {
+		args = make([]interface{}, 0)
+	}
+	argsJson, err := json.Marshal(args)
+	if err != nil {
+		return res, err
+	}
+
+	var runId int64
+	if !dryRun {
+		if err := db.Pool.QueryRow(ctx, `
+			INSERT INTO instance.schedule_run (schedule_id, node_id, date_start, login_id, args)
+			VALUES ($1,$2,$3,$4,$5)
+			RETURNING id
+		`, scheduleId, cache.GetNodeId(), tools.GetTimeUnix(), loginId, string(argsJson)).Scan(&runId); err != nil {
+			return res, err
+		}
+	}
+
+	timeStart := time.Now()
+	errRun := runPgFunctionManual_tx(ctx, tx, pgFunctionId, loginId, args, dryRun, &res)
+	notices := noticesGet()
+	duration := time.Since(timeStart)
+
+	res.DurationMs = duration.Milliseconds()
+	res.Notices = notices
+	if errRun != nil {
+		res.Error = pgtype.Text{String: errRun.Error(), Valid: true}
+	}
+
+	if runId != 0 {
+		if err := runEnd(runId, duration, errRun, notices); err != nil {
+			log.Error(log.ContextScheduler, "failed to store manual task run", err)
+		}
+	}
+
+	log.Info(log.ContextScheduler, fmt.Sprintf("PG function schedule '%s' was run manually by login %d (dry run: %v, arguments: %s)",
+		pgFunctionScheduleId, loginId, dryRun, argsJson))
+
+	return res, nil
+}
+
+// locks PG function for the given transaction and marks its task as running on this node
+// returns task index (-1 if not scheduled on this node)
+// fails if the PG function is already running (scheduled, manual or dry run) on this or any other node
+func manualRunClaim(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) (int, int, error) {
+	errRunning := errors.New("function is currently running, manual run is not possible")
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`,
+		getPgFunctionLockKey(pgFunctionId)).Scan(&locked); err != nil {
+
+		return -1, 0, err
+	}
+	if !locked {
+		return -1, 0, errRunning
+	}
+
+	change_mx.Lock()
+	defer change_mx.Unlock()
+
+	for i, t := range tasks {
+		if t.isSystemTask || t.pgFunctionId != pgFunctionId {
+			continue
+		}
+		if t.running {
+			return -1, 0, errRunning
+		}
+		tasks[i].running = true
+		return i, loadCounter, nil
+	}
+	return -1, loadCounter, nil
+}
+
+// releases task of PG function after manual run, if tasks were not reloaded in the meantime
+// next execution is recalculated, as scheduled runs were held back during the manual run
+func manualRunRelease(taskIndex int, loadCounterPre int) {
+	if taskIndex == -1 {
+		return
+	}
+	change_mx.Lock()
+	defer change_mx.Unlock()
+
+	if loadCounterPre == loadCounter {
+		tasks[taskIndex].running = false
+		nextExecutionUnix = 0
+	}
+}
+
+// executes PG function in given transaction, commits it unless it is a dry run
+// affected row counts and return value are stored in given result
+func runPgFunctionManual_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID, loginId int64,
+	args []interface{}, dryRun bool, res *types.TaskRunManualResult) error {
+
+	if err := db.SetSessionConfig_tx(ctx, tx, loginId); err != nil {
+		return err
+	}
+
+	modName, fncName, _, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, pgFunctionId)
+	if err != nil {
+		return err
+	}
+
+	// statistics of the transaction include its own changes, take snapshot before execution to compare
+	countsPre, err := getManualRunRowCounts_tx(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	placeholders := make([]string, 0)
+	for i := range args {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	}
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT "%s"."%s"(%s)::TEXT`,
+		modName, fncName, strings.Join(placeholders, ",")), args...).Scan(&res.ReturnValue); err != nil {
+
+		return err
+	}
+
+	countsPost, err := getManualRunRowCounts_tx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for key, c := range countsPost {
+		cPre := countsPre[key]
+		c.Inserted -= cPre.Inserted
+		c.Updated -= cPre.Updated
+		c.Deleted -= cPre.Deleted
+
+		if c.Inserted != 0 || c.Updated != 0 || c.Deleted != 0 {
+			res.RowCounts = append(res.RowCounts, c)
+		}
+	}
+	slices.SortFunc(res.RowCounts, func(a, b types.TaskRunManualRowCount) int {
+		return strings.Compare(a.Schema+"."+a.Table, b.Schema+"."+b.Table)
+	})
+
+	if dryRun {
+		return tx.Rollback(ctx)
+	}
+	return tx.Commit(ctx)
+}
+
+func getManualRunRowCounts_tx(ctx context.Context, tx pgx.Tx) (map[string]types.TaskRunManualRowCount, error) {
+	counts := make(map[string]types.TaskRunManualRowCount)
+
+	rows, err := tx.Query(ctx, manualRunRowCountsQuery)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c types.TaskRunManualRowCount
+		if err := rows.Scan(&c.Schema, &c.Table, &c.Inserted, &c.Updated, &c.Deleted); err != nil {
+			return counts, err
+		}
+		counts[fmt.Sprintf("%s.%s", c.Schema, c.Table)] = c
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var runAlertTemplates = struct {
+	intro           string
+	durationBody    string
+	durationSubject string
+	failedBody      string
+	failedSubject   string
+}{
+	intro: `<p>You are receiving this message, because your email address has been added to the Axia admin notification list.</p>
+	<p>To change this setting, please visit your Axia instance: {URL}</p>`,
+	durationBody:    `<p>Task '{TASK}' is running for more than {SECONDS} seconds on node '{NODE}'.</p>`,
+	durationSubject: `Axia task '{TASK}' is running longer than expected`,
+	failedBody:      `<p>Task '{TASK}' failed {COUNT} times in a row on node '{NODE}'.</p><p>Last error: {ERROR}</p>`,
+	failedSubject:   `Axia task '{TASK}' failed repeatedly`,
+}
+
+// payload of task alert webhook calls
+type runAlertPayload struct {
+	Date   int64  `json:"date"`
+	Detail string `json:"detail"`
+	Node   string `json:"node"`
+	Reason string `json:"reason"` // duration, failed
+	Task   string `json:"task"`
+}
+
+// stores start of task schedule execution in run history, returns run ID
+func runStart(scheduleId int64, dateStart int64) (int64, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var runId int64
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO instance.schedule_run (schedule_id, node_id, date_start)
+		VALUES ($1,$2,$3)
+		RETURNING id
+	`, scheduleId, cache.GetNodeId(), dateStart).Scan(&runId)
+	return runId, err
+}
+
+// stores outcome of task schedule execution in run history
+// output contains notices raised during PG function execution
+func runEnd(runId int64, duration time.Duration, errRun error, output []string) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	errText := pgtype.Text{}
+	if errRun != nil {
+		errText.String = errRun.Error()
+		errText.Valid = true
+	}
+	outputText := pgtype.Text{}
+	if len(output) != 0 {
+		outputText.String = strings.Join(output, "\n")
+		outputText.Valid = true
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.schedule_run
+		SET date_end = $1, duration_ms = $2, success = $3, error = $4, output = $5
+		WHERE id = $6
+	`, tools.GetTimeUnix(), duration.Milliseconds(), errRun == nil, errText, outputText, runId)
+	return err
+}
+
+// sends alert if task schedule failed as many times in a row as defined, on this node
+// alert is only sent once, when the defined count is reached
+func runAlertOnFailures(t task, s taskSchedule, errRun error) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var failCount int
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.schedule_run
+		WHERE schedule_id = $1
+		AND   node_id     = $2
+		AND   success     = FALSE
+		AND   skipped     = FALSE
+		AND   id > COALESCE((
+			SELECT MAX(id)
+			FROM instance.schedule_run
+			WHERE schedule_id = $1
+			AND   node_id     = $2
+			AND   success     = TRUE
+		), 0)
+	`, s.id, cache.GetNodeId()).Scan(&failCount); err != nil {
+		return err
+	}
+
+	if failCount != s.alertFailCount {
+		return nil
+	}
+
+	r := strings.NewReplacer("{TASK}", t.nameLog, "{NODE}", cache.GetNodeName(),
+		"{COUNT}", fmt.Sprintf("%d", failCount), "{ERROR}", errRun.Error())
+
+	return runAlertSend(ctx, "failed", r.Replace(runAlertTemplates.failedSubject),
+		r.Replace(runAlertTemplates.failedBody), t.nameLog, errRun.Error())
+}
+
+// sends alert for task schedule running longer than defined
+func runAlertOnDuration(t task, s taskSchedule) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	r := strings.NewReplacer("{TASK}", t.nameLog, "{NODE}", cache.GetNodeName(),
+		"{SECONDS}", fmt.Sprintf("%d", s.alertDuration))
+
+	if err := runAlertSend(ctx, "duration", r.Replace(runAlertTemplates.durationSubject),
+		r.Replace(runAlertTemplates.durationBody), t.nameLog,
+		fmt.Sprintf("running for more than %d seconds", s.alertDuration)); err != nil {
+
+		log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to send alert", t.nameLog), err)
+	}
+}
+
+// sends task alert to admin mail receivers and alert webhook, if defined
+func runAlertSend(ctx context.Context, reason string, subject string,
+	body string, taskName string, detail string) error {
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var toList []string
+	if config.GetString("adminMails") != "" {
+		if err := json.Unmarshal([]byte(config.GetString("adminMails")), &toList); err != nil {
+			return fmt.Errorf("cannot read admin mail receivers, %s", err.Error())
+		}
+	}
+
+	if len(toList) != 0 {
+		body = strings.Replace(fmt.Sprintf("%s%s", runAlertTemplates.intro, body),
+			"{URL}", config.GetString("publicHostName"), -1)
+
+		if _, err := tx.Exec(ctx, `
+			SELECT instance.mail_send($1,$2,$3)
+		`, subject, body, strings.Join(toList, ",")); err != nil {
+			return err
+		}
+	}
+
+	if url := config.GetString("taskAlertWebhookUrl"); url != "" {
+		payload, err := json.Marshal(runAlertPayload{
+			Date:   tools.GetTimeUnix(),
+			Detail: detail,
+			Node:   cache.GetNodeName(),
+			Reason: reason,
+			Task:   taskName,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.rest_spool (method, headers, url,
+				body, date_added, skip_verify)
+			VALUES ('POST', $1, $2, $3, EXTRACT(EPOCH FROM NOW()), FALSE)
+		`, map[string]string{"Content-Type": "application/json"},
+			url, string(payload)); err != nil {
+
+			return err
+		}
+	}
+
+	if len(toList) == 0 && config.GetString("taskAlertWebhookUrl") == "" {
+		log.Warning(log.ContextScheduler, fmt.Sprintf("cannot send alert for task '%s'", taskName),
+			fmt.Errorf("no admin mail receivers or alert webhook defined"))
+	}
+	return tx.Commit(ctx)
+}
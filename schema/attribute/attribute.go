@@ -70,7 +70,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 	attributes := make([]types.Attribute, 0)
 	rows, err := tx.Query(ctx, `
 		SELECT id, relationship_id, icon_id, name, content, content_use,
-			length, length_fract, nullable, encrypted, def, on_update, on_delete
+			length, length_fract, nullable, encrypted, def, generated,
+			on_update, on_delete
 		FROM app.attribute
 		WHERE relation_id = $1
 		ORDER BY CASE WHEN name = 'id' THEN 0 END, name ASC
@@ -84,7 +85,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 		var atr types.Attribute
 		if err := rows.Scan(&atr.Id, &atr.RelationshipId, &atr.IconId, &atr.Name,
 			&atr.Content, &atr.ContentUse, &atr.Length, &atr.LengthFract, &atr.Nullable,
-			&atr.Encrypted, &atr.Def, &onUpdateNull, &onDeleteNull); err != nil {
+			&atr.Encrypted, &atr.Def, &atr.Generated, &onUpdateNull, &onDeleteNull); err != nil {
 
 			return attributes, err
 		}
@@ -134,6 +135,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 	isNew := atr.Id == uuid.Nil
 	isRel := schema.IsContentRelationship(atr.Content)
 	isFiles := schema.IsContentFiles(atr.Content)
+	isGenerated := atr.Generated.Valid
+	if err := checkGenerated(atr, isRel, isFiles); err != nil {
+		return err
+	}
+
 	known, err := schema.CheckCreateId_tx(ctx, tx, &atr.Id, schema.DbAttribute, "id")
 	if err != nil {
 		return err
@@ -161,16 +167,17 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		var lengthFractEx int
 		var nullableEx bool
 		var defEx string
+		var generatedEx pgtype.Text
 		var onUpdateEx pgtype.Text
 		var onDeleteEx pgtype.Text
 		var relationshipIdEx pgtype.UUID
 		if err := tx.QueryRow(ctx, `
 			SELECT name, content, length, length_fract, nullable,
-				def, on_update, on_delete, relationship_id
+				def, generated, on_update, on_delete, relationship_id
 			FROM app.attribute
 			WHERE id = $1
 		`, atr.Id).Scan(&nameEx, &contentEx, &lengthEx, &lengthFractEx, &nullableEx,
-			&defEx, &onUpdateEx, &onDeleteEx, &relationshipIdEx); err != nil {
+			&defEx, &generatedEx, &onUpdateEx, &onDeleteEx, &relationshipIdEx); err != nil {
 
 			return err
 		}
@@ -234,6 +241,12 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			return fmt.Errorf("cannot change relationship target for existing attribute")
 		}
 
+		// do not allow switching between regular and generated attribute
+		// regular values would be overwritten, generated values would not be maintained anymore
+		if generatedEx.Valid != isGenerated {
+			return fmt.Errorf("cannot change existing attribute from or to generated attribute")
+		}
+
 		// do not check for nullable removal, postgres will block if unsafe
 		// do not check for varchar length reduction, its their choice to loose data
 
@@ -245,10 +258,18 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			}
 		}
 
-		// update attribute column definition (not for files attributes: no column)
-		if !isFiles && (contentEx != atr.Content || nullableEx != atr.Nullable || defEx != atr.Def ||
+		columnChanged := contentEx != atr.Content || nullableEx != atr.Nullable || defEx != atr.Def ||
 			(atr.Content == "varchar" && lengthEx != atr.Length) ||
-			(atr.Content == "numeric" && (lengthEx != atr.Length || lengthFractEx != atr.LengthFract))) {
+			(atr.Content == "numeric" && (lengthEx != atr.Length || lengthFractEx != atr.LengthFract))
+
+		// update attribute column definition (not for files attributes: no column)
+		if isGenerated {
+			if columnChanged || generatedEx.String != atr.Generated.String {
+				if err := setGeneratedColumn_tx(ctx, tx, moduleName, relationName, atr, true); err != nil {
+					return err
+				}
+			}
+		} else if !isFiles && columnChanged {
 
 			// handle relationship attribute
 			var contentRel string
@@ -325,10 +346,10 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.attribute
 			SET icon_id = $1, content = $2, content_use = $3, length = $4, length_fract = $5,
-				nullable = $6, def = $7, on_update = $8, on_delete = $9
-			WHERE id = $10
+				nullable = $6, def = $7, generated = $8, on_update = $9, on_delete = $10
+			WHERE id = $11
 		`, atr.IconId, atr.Content, atr.ContentUse, atr.Length, atr.LengthFract, atr.Nullable,
-			atr.Def, onUpdateNull, onDeleteNull, atr.Id); err != nil {
+			atr.Def, atr.Generated, onUpdateNull, onDeleteNull, atr.Id); err != nil {
 
 			return err
 		}
@@ -348,6 +369,10 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			if err := fileRelationsCreate_tx(ctx, tx, atr.Id, moduleName, relationName); err != nil {
 				return err
 			}
+		} else if isGenerated {
+			if err := setGeneratedColumn_tx(ctx, tx, moduleName, relationName, atr, false); err != nil {
+				return err
+			}
 		} else {
 			// check relationship target if relationship attribute
 			var contentRel string
@@ -401,11 +426,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.attribute (id, relation_id, relationship_id,
 				icon_id, name, content, content_use, length, length_fract,
-				nullable, encrypted, def, on_update, on_delete)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+				nullable, encrypted, def, generated, on_update, on_delete)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 		`, atr.Id, atr.RelationId, atr.RelationshipId, atr.IconId, atr.Name,
 			atr.Content, atr.ContentUse, atr.Length, atr.LengthFract, atr.Nullable,
-			atr.Encrypted, atr.Def, onUpdateNull, onDeleteNull); err != nil {
+			atr.Encrypted, atr.Def, atr.Generated, onUpdateNull, onDeleteNull); err != nil {
 
 			return err
 		}
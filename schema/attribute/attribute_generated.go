@@ -0,0 +1,151 @@
+package attribute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/schema"
+	"r3/schema/pgIndex"
+	"r3/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var generatedAttributeRegex = regexp.MustCompile(`\(([a-z0-9\-]{36})\)`)
+
+// generated attributes are stored generated columns, computed by the database on every write
+// their expression refers to other attributes of the same relation by ID, syntax: (ATTRIBUTE_ID)
+func checkGenerated(atr types.Attribute, isRel bool, isFiles bool) error {
+	if !atr.Generated.Valid {
+		return nil
+	}
+	if strings.TrimSpace(atr.Generated.String) == "" {
+		return errors.New("generated attribute requires an expression")
+	}
+	if atr.Name == schema.PkName || isRel || isFiles || atr.Encrypted {
+		return errors.New("primary key, relationship, files and encrypted attributes cannot be generated")
+	}
+	if atr.Def != "" {
+		return errors.New("generated attribute cannot have a default value")
+	}
+	return nil
+}
+
+// replaces attribute IDs in generated attribute expression with column names
+// only regular attributes of the same relation can be referred to
+func getGeneratedExpression_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) (string, error) {
+
+	expr := atr.Generated.String
+	idMap := make(map[uuid.UUID]bool)
+	matches := generatedAttributeRegex.FindAllStringSubmatch(expr, -1)
+	for _, matchesSub := range matches {
+
+		if len(matchesSub) != 2 {
+			continue
+		}
+		placeholder := matchesSub[0]
+
+		atrId, err := uuid.FromString(matchesSub[1])
+		if err != nil {
+			return "", err
+		}
+
+		if _, exists := idMap[atrId]; exists {
+			continue
+		}
+		idMap[atrId] = true
+
+		if atrId == atr.Id {
+			return "", errors.New("generated attribute cannot refer to itself")
+		}
+
+		var relationId uuid.UUID
+		var name, content string
+		var encrypted bool
+		var generated pgtype.Text
+		if err := tx.QueryRow(ctx, `
+			SELECT relation_id, name, content, encrypted, generated
+			FROM app.attribute
+			WHERE id = $1
+		`, atrId).Scan(&relationId, &name, &content, &encrypted, &generated); err != nil {
+			return "", fmt.Errorf("failed to get attribute %s of generated attribute expression: %w", atrId, err)
+		}
+
+		if relationId != atr.RelationId {
+			return "", fmt.Errorf("generated attribute expression can only refer to attributes of the same relation, '%s' is not", name)
+		}
+		if schema.IsContentFiles(content) || encrypted || generated.Valid {
+			return "", fmt.Errorf("generated attribute expression cannot refer to files, encrypted or generated attribute '%s'", name)
+		}
+		expr = strings.ReplaceAll(expr, placeholder, fmt.Sprintf(`"%s"`, name))
+	}
+	return expr, nil
+}
+
+// creates column of generated attribute
+// if column exists, it is dropped and recreated as expressions cannot be altered
+// PG indexes on the column are removed with it and must be recreated afterwards
+func setGeneratedColumn_tx(ctx context.Context, tx pgx.Tx, moduleName string,
+	relationName string, atr types.Attribute, recreate bool) error {
+
+	expr, err := getGeneratedExpression_tx(ctx, tx, atr)
+	if err != nil {
+		return err
+	}
+
+	columnDef, err := getContentColumnDefinition(atr.Content, atr.Length, atr.LengthFract, "")
+	if err != nil {
+		return err
+	}
+
+	nullableDef := ""
+	if !atr.Nullable {
+		nullableDef = "NOT NULL"
+	}
+
+	pgIndexes := make([]types.PgIndex, 0)
+	if recreate {
+		pgIndexesRel, err := pgIndex.Get_tx(ctx, tx, atr.RelationId)
+		if err != nil {
+			return err
+		}
+		for _, pgi := range pgIndexesRel {
+			if (pgi.AttributeIdDict.Valid && pgi.AttributeIdDict.Bytes == atr.Id) ||
+				slices.ContainsFunc(pgi.Attributes, func(a types.PgIndexAttribute) bool {
+					return a.AttributeId == atr.Id
+				}) {
+
+				pgIndexes = append(pgIndexes, pgi)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			ALTER TABLE "%s"."%s"
+			DROP COLUMN "%s"
+		`, moduleName, relationName, atr.Name)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s"
+		ADD COLUMN "%s" %s GENERATED ALWAYS AS (%s) STORED %s
+	`, moduleName, relationName, atr.Name, columnDef, expr, nullableDef)); err != nil {
+		return err
+	}
+
+	for _, pgi := range pgIndexes {
+		if err := pgIndex.Del_tx(ctx, tx, pgi.Id); err != nil {
+			return err
+		}
+		if err := pgIndex.Set_tx(ctx, tx, pgi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
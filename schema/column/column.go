@@ -30,7 +30,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 
 	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT id, attribute_id, index, batch, basis, length, display, group_by,
-			aggregator, distincted, hidden, on_mobile, sub_query, styles
+			aggregator, date_bucket, distincted, window_function, window_offset,
+			window_partition, hidden, on_mobile, sub_query, styles
 		FROM app.column
 		WHERE %s_id = $1
 		ORDER BY position ASC
@@ -43,7 +44,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 	for rows.Next() {
 		var c types.Column
 		if err := rows.Scan(&c.Id, &c.AttributeId, &c.Index, &c.Batch, &c.Basis,
-			&c.Length, &c.Display, &c.GroupBy, &c.Aggregator, &c.Distincted,
+			&c.Length, &c.Display, &c.GroupBy, &c.Aggregator, &c.DateBucket,
+			&c.Distincted, &c.Window, &c.WindowOffset, &c.WindowPartition,
 			&c.Hidden, &c.OnMobile, &c.SubQuery, &c.Styles); err != nil {
 
 			return columns, err
@@ -52,6 +54,14 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 	}
 
 	for i, c := range columns {
+		c = compatible.FixColumnWindowNil(c)
+		if c.Window.Valid {
+			c.WindowOrders, err = getWindowOrders_tx(ctx, tx, c.Id)
+			if err != nil {
+				return columns, err
+			}
+		}
+
 		if c.SubQuery {
 			c.Query, err = query.Get_tx(ctx, tx, schema.DbColumn, c.Id, 0, 0, 0)
 			if err != nil {
@@ -107,16 +117,22 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 		// fix imports < 3.8: Convert to new styles
 		c = compatible.FixColumnStyles(c)
 
+		// fix imports < 4.1: Missing window options
+		c = compatible.FixColumnWindowNil(c)
+
 		if known {
 			if _, err := tx.Exec(ctx, `
 				UPDATE app.column
 				SET attribute_id = $1, index = $2, position = $3, batch = $4, basis = $5,
 					length = $6, display = $7, group_by = $8, aggregator = $9, distincted = $10,
-					hidden = $11, on_mobile = $12, sub_query = $13, styles = $14
-				WHERE id = $15
+					hidden = $11, on_mobile = $12, sub_query = $13, styles = $14,
+					date_bucket = $15, window_function = $16, window_offset = $17,
+					window_partition = $18
+				WHERE id = $19
 			`, c.AttributeId, c.Index, position, c.Batch, c.Basis, c.Length, c.Display,
 				c.GroupBy, c.Aggregator, c.Distincted, c.Hidden, c.OnMobile, c.SubQuery,
-				c.Styles, c.Id); err != nil {
+				c.Styles, c.DateBucket, c.Window, c.WindowOffset, c.WindowPartition,
+				c.Id); err != nil {
 
 				return err
 			}
@@ -125,17 +141,23 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 				INSERT INTO app.column (
 					id, %s_id, attribute_id, index, position, batch, basis, length,
 					display, group_by, aggregator, distincted, hidden, on_mobile,
-					sub_query, styles
+					sub_query, styles, date_bucket, window_function, window_offset,
+					window_partition
 				)
-				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
 			`, entity), c.Id, entityId, c.AttributeId, c.Index, position, c.Batch,
 				c.Basis, c.Length, c.Display, c.GroupBy, c.Aggregator, c.Distincted,
-				c.Hidden, c.OnMobile, c.SubQuery, c.Styles); err != nil {
+				c.Hidden, c.OnMobile, c.SubQuery, c.Styles, c.DateBucket, c.Window,
+				c.WindowOffset, c.WindowPartition); err != nil {
 
 				return err
 			}
 		}
 
+		if err := setWindowOrders_tx(ctx, tx, c.Id, c.WindowOrders); err != nil {
+			return err
+		}
+
 		if c.SubQuery {
 			if err := query.Set_tx(ctx, tx, schema.DbColumn, c.Id, 0, 0, 0, c.Query); err != nil {
 				return err
@@ -149,3 +171,46 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 	}
 	return nil
 }
+
+func getWindowOrders_tx(ctx context.Context, tx pgx.Tx, columnId uuid.UUID) ([]types.ColumnWindowOrder, error) {
+	orders := make([]types.ColumnWindowOrder, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT column_pos, ascending
+		FROM app.column_window_order
+		WHERE column_id = $1
+		ORDER BY position ASC
+	`, columnId)
+	if err != nil {
+		return orders, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o types.ColumnWindowOrder
+		if err := rows.Scan(&o.ColumnPos, &o.Ascending); err != nil {
+			return orders, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+func setWindowOrders_tx(ctx context.Context, tx pgx.Tx, columnId uuid.UUID, orders []types.ColumnWindowOrder) error {
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.column_window_order
+		WHERE column_id = $1
+	`, columnId); err != nil {
+		return err
+	}
+
+	for position, o := range orders {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.column_window_order (column_id, position, column_pos, ascending)
+			VALUES ($1,$2,$3,$4)
+		`, columnId, position, o.ColumnPos, o.Ascending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -14,6 +14,18 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// < 4.1
+// fix missing window options
+func FixColumnWindowNil(column types.Column) types.Column {
+	if column.WindowOrders == nil {
+		column.WindowOrders = make([]types.ColumnWindowOrder, 0)
+	}
+	if column.WindowPartition == nil {
+		column.WindowPartition = make([]int, 0)
+	}
+	return column
+}
+
 // < 3.11
 // fix missing cost setting
 func FixMissingCost(fnc types.PgFunction) types.PgFunction {
@@ -8,6 +8,7 @@ import (
 
 // constants
 var PkName = "id"
+var SoftDeleteName = "_date_deleted" // deletion date (unix) of soft deleted records, '_' prefix cannot be used by entities
 
 // database entity names
 func GetPkConstraintName(relationId uuid.UUID) string {
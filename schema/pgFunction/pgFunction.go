@@ -85,7 +85,8 @@ func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]
 	schedules := make([]types.PgFunctionSchedule, 0)
 
 	rows, err := tx.Query(ctx, `
-		SELECT id, at_second, at_minute, at_hour, at_day, interval_type, interval_value
+		SELECT id, at_second, at_minute, at_hour, at_day, interval_type,
+			interval_value, cron, time_zone
 		FROM app.pg_function_schedule
 		WHERE pg_function_id = $1
 		ORDER BY id ASC
@@ -98,13 +99,22 @@ func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]
 	for rows.Next() {
 		var s types.PgFunctionSchedule
 
-		if err := rows.Scan(&s.Id, &s.AtSecond, &s.AtMinute, &s.AtHour,
-			&s.AtDay, &s.IntervalType, &s.IntervalValue); err != nil {
+		if err := rows.Scan(&s.Id, &s.AtSecond, &s.AtMinute, &s.AtHour, &s.AtDay,
+			&s.IntervalType, &s.IntervalValue, &s.Cron, &s.TimeZone); err != nil {
 
 			return schedules, err
 		}
 		schedules = append(schedules, s)
 	}
+	rows.Close()
+
+	for i, s := range schedules {
+		s.Blackouts, err = getScheduleBlackouts_tx(ctx, tx, s.Id)
+		if err != nil {
+			return schedules, err
+		}
+		schedules[i] = s
+	}
 	return schedules, nil
 }
 
@@ -214,14 +224,18 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 		// overwrite invalid inputs
 		s.AtDay = schema.GetValidAtDay(s.IntervalType, s.AtDay)
 
+		if err := checkSchedule(&s); err != nil {
+			return err
+		}
+
 		if known {
 			if _, err := tx.Exec(ctx, `
 				UPDATE app.pg_function_schedule
 				SET at_second = $1, at_minute = $2, at_hour = $3, at_day = $4,
-					interval_type = $5, interval_value = $6
-				WHERE id = $7
-			`, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay,
-				s.IntervalType, s.IntervalValue, s.Id); err != nil {
+					interval_type = $5, interval_value = $6, cron = $7, time_zone = $8
+				WHERE id = $9
+			`, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay, s.IntervalType,
+				s.IntervalValue, s.Cron, s.TimeZone, s.Id); err != nil {
 
 				return err
 			}
@@ -229,11 +243,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 			if _, err := tx.Exec(ctx, `
 				INSERT INTO app.pg_function_schedule (
 					id, pg_function_id, at_second, at_minute, at_hour, at_day,
-					interval_type, interval_value
+					interval_type, interval_value, cron, time_zone
 				)
-				VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 			`, s.Id, fnc.Id, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay,
-				s.IntervalType, s.IntervalValue); err != nil {
+				s.IntervalType, s.IntervalValue, s.Cron, s.TimeZone); err != nil {
 
 				return err
 			}
@@ -246,6 +260,10 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 				return err
 			}
 		}
+
+		if err := setScheduleBlackouts_tx(ctx, tx, s.Id, s.Blackouts); err != nil {
+			return err
+		}
 		scheduleIds = append(scheduleIds, s.Id)
 	}
 
@@ -0,0 +1,97 @@
+package pgFunction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/tools/cron"
+	"r3/types"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// validates cron expression, time zone and blackout windows of PG function schedule
+// cron expression is only kept for interval type 'cron'
+func checkSchedule(s *types.PgFunctionSchedule) error {
+
+	if s.IntervalType != "cron" {
+		s.Cron.Valid = false
+	} else {
+		s.Cron.String = strings.TrimSpace(s.Cron.String)
+		if !s.Cron.Valid || s.Cron.String == "" {
+			return errors.New("schedule with interval type 'cron' requires a cron expression")
+		}
+		expr, err := cron.Parse(s.Cron.String)
+		if err != nil {
+			return err
+		}
+		if _, ok := expr.Next(time.Now()); !ok {
+			return fmt.Errorf("cron expression '%s' does not match any date", s.Cron.String)
+		}
+	}
+
+	if s.TimeZone.Valid && s.TimeZone.String == "" {
+		s.TimeZone.Valid = false
+	}
+	if s.TimeZone.Valid {
+		if _, err := time.LoadLocation(s.TimeZone.String); err != nil {
+			return fmt.Errorf("invalid schedule time zone '%s', %s", s.TimeZone.String, err)
+		}
+	}
+
+	for _, b := range s.Blackouts {
+		if b.DateTo < b.DateFrom {
+			return errors.New("schedule blackout window cannot end before it starts")
+		}
+	}
+	return nil
+}
+
+func getScheduleBlackouts_tx(ctx context.Context, tx pgx.Tx, pgFunctionScheduleId uuid.UUID) ([]types.PgFunctionScheduleBlackout, error) {
+	blackouts := make([]types.PgFunctionScheduleBlackout, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT date_from, date_to, yearly
+		FROM app.pg_function_schedule_blackout
+		WHERE pg_function_schedule_id = $1
+		ORDER BY position ASC
+	`, pgFunctionScheduleId)
+	if err != nil {
+		return blackouts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b types.PgFunctionScheduleBlackout
+		if err := rows.Scan(&b.DateFrom, &b.DateTo, &b.Yearly); err != nil {
+			return blackouts, err
+		}
+		blackouts = append(blackouts, b)
+	}
+	return blackouts, nil
+}
+
+func setScheduleBlackouts_tx(ctx context.Context, tx pgx.Tx, pgFunctionScheduleId uuid.UUID,
+	blackouts []types.PgFunctionScheduleBlackout) error {
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.pg_function_schedule_blackout
+		WHERE pg_function_schedule_id = $1
+	`, pgFunctionScheduleId); err != nil {
+		return err
+	}
+
+	for i, b := range blackouts {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.pg_function_schedule_blackout (
+				pg_function_schedule_id, position, date_from, date_to, yearly)
+			VALUES ($1,$2,$3,$4,$5)
+		`, pgFunctionScheduleId, i, b.DateFrom, b.DateTo, b.Yearly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
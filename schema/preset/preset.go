@@ -222,17 +222,21 @@ func setValues_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, presetId
 
 		// make sure that preset values belong to the correct relation
 		var relationIdAtr uuid.UUID
+		var isGenerated bool
 		if err := tx.QueryRow(ctx, `
-			SELECT relation_id
+			SELECT relation_id, generated IS NOT NULL
 			FROM app.attribute
 			WHERE id = $1
-		`, value.AttributeId).Scan(&relationIdAtr); err != nil {
+		`, value.AttributeId).Scan(&relationIdAtr, &isGenerated); err != nil {
 			return err
 		}
 
 		if relationIdAtr.String() != relationId.String() {
 			return fmt.Errorf("cannot save preset values, at least 1 attribute value is from a different relation")
 		}
+		if isGenerated {
+			return fmt.Errorf("cannot save preset values, generated attributes are computed by the database")
+		}
 
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.preset_value (id, preset_id,
@@ -22,6 +22,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, id uuid.UUID
 	q.Orders = make([]types.QueryOrder, 0)
 	q.Lookups = make([]types.QueryLookup, 0)
 	q.Choices = make([]types.QueryChoice, 0)
+	q.GroupingSets = make([][]int, 0)
 
 	if !slices.Contains(schema.DbAssignedQuery, entity) {
 		return q, errors.New("unknown query parent entity")
@@ -38,11 +39,11 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, id uuid.UUID
 	}
 
 	err := tx.QueryRow(ctx, fmt.Sprintf(`
-		SELECT id, relation_id, fixed_limit
+		SELECT id, relation_id, fixed_limit, "grouping"
 		FROM app.query
 		WHERE %s_id = $1
 		%s
-	`, entity, filterClause), id).Scan(&q.Id, &q.RelationId, &q.FixedLimit)
+	`, entity, filterClause), id).Scan(&q.Id, &q.RelationId, &q.FixedLimit, &q.Grouping)
 
 	if err != nil && err != pgx.ErrNoRows {
 		return q, err
@@ -104,6 +105,29 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, id uuid.UUID
 		q.Orders = append(q.Orders, o)
 	}
 
+	// retrieve grouping sets
+	if q.Grouping.Valid && q.Grouping.String == "sets" {
+		rows, err = tx.Query(ctx, `
+			SELECT column_positions
+			FROM app.query_grouping_set
+			WHERE query_id = $1
+			ORDER BY position ASC
+		`, q.Id)
+		if err != nil {
+			return q, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var set []int
+
+			if err := rows.Scan(&set); err != nil {
+				return q, err
+			}
+			q.GroupingSets = append(q.GroupingSets, set)
+		}
+	}
+
 	// retrieve lookups
 	rows, err = tx.Query(ctx, `
 		SELECT pg_index_id, index
@@ -231,17 +255,18 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 	if createNew {
 		if !subQuery {
 			if _, err := tx.Exec(ctx, fmt.Sprintf(`
-				INSERT INTO app.query (id, relation_id, fixed_limit, %s_id)
-				VALUES ($1,$2,$3,$4)
-			`, entity), query.Id, query.RelationId, query.FixedLimit, entityId); err != nil {
+				INSERT INTO app.query (id, relation_id, fixed_limit, "grouping", %s_id)
+				VALUES ($1,$2,$3,$4,$5)
+			`, entity), query.Id, query.RelationId, query.FixedLimit, query.Grouping, entityId); err != nil {
 				return err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
-				INSERT INTO app.query (id, relation_id, fixed_limit, query_filter_query_id,
-					query_filter_index, query_filter_position, query_filter_side)
-				VALUES ($1,$2,$3,$4,$5,$6,$7)
-			`, query.Id, query.RelationId, query.FixedLimit, entityId,
+				INSERT INTO app.query (id, relation_id, fixed_limit, "grouping",
+					query_filter_query_id, query_filter_index, query_filter_position,
+					query_filter_side)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+			`, query.Id, query.RelationId, query.FixedLimit, query.Grouping, entityId,
 				filterIndex, filterPosition, filterSide); err != nil {
 
 				return err
@@ -250,9 +275,9 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 	} else {
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.query
-			SET relation_id = $1, fixed_limit = $2
-			WHERE id = $3
-		`, query.RelationId, query.FixedLimit, query.Id); err != nil {
+			SET relation_id = $1, fixed_limit = $2, "grouping" = $3
+			WHERE id = $4
+		`, query.RelationId, query.FixedLimit, query.Grouping, query.Id); err != nil {
 			return err
 		}
 	}
@@ -316,6 +341,31 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 		}
 	}
 
+	// reset grouping sets
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.query_grouping_set
+		WHERE query_id = $1
+	`, query.Id); err != nil {
+		return err
+	}
+
+	if query.Grouping.Valid && query.Grouping.String == "sets" {
+		for position, set := range query.GroupingSets {
+
+			// empty set is valid (grand total), but must not be NULL
+			if set == nil {
+				set = make([]int, 0)
+			}
+
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO app.query_grouping_set (query_id, position, column_positions)
+				VALUES ($1,$2,$3)
+			`, query.Id, position, set); err != nil {
+				return err
+			}
+		}
+	}
+
 	// reset lookups
 	if _, err := tx.Exec(ctx, `
 		DELETE FROM app.query_lookup
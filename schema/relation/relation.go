@@ -77,7 +77,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 
 	relations := make([]types.Relation, 0)
 	rows, err := tx.Query(ctx, `
-		SELECT id, name, comment, encryption, retention_count, retention_days, (
+		SELECT id, name, comment, encryption, retention_count, retention_days,
+			soft_delete, soft_delete_days, record_lock, (
 			SELECT id
 			FROM app.attribute
 			WHERE relation_id = app.relation.id
@@ -95,7 +96,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 	for rows.Next() {
 		var r types.Relation
 		if err := rows.Scan(&r.Id, &r.Name, &r.Comment, &r.Encryption,
-			&r.RetentionCount, &r.RetentionDays, &r.AttributeIdPk); err != nil {
+			&r.RetentionCount, &r.RetentionDays, &r.SoftDelete, &r.SoftDeleteDays,
+			&r.RecordLock, &r.AttributeIdPk); err != nil {
 
 			return relations, err
 		}
@@ -137,15 +139,43 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 			return err
 		}
 
+		var softDeleteEx bool
+		if err := tx.QueryRow(ctx, `
+			SELECT soft_delete
+			FROM app.relation
+			WHERE id = $1
+		`, rel.Id).Scan(&softDeleteEx); err != nil {
+			return err
+		}
+
 		// update relation reference
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.relation
-			SET name = $1, comment = $2, retention_count = $3, retention_days = $4
-			WHERE id = $5
-		`, rel.Name, rel.Comment, rel.RetentionCount, rel.RetentionDays, rel.Id); err != nil {
+			SET name = $1, comment = $2, retention_count = $3, retention_days = $4,
+				soft_delete = $5, soft_delete_days = $6, record_lock = $7
+			WHERE id = $8
+		`, rel.Name, rel.Comment, rel.RetentionCount, rel.RetentionDays,
+			rel.SoftDelete, rel.SoftDeleteDays, rel.RecordLock, rel.Id); err != nil {
 			return err
 		}
 
+		// record locks are only kept for pessimistic locking
+		if !rel.RecordLock.Valid || rel.RecordLock.String != "pessimistic" {
+			if _, err := tx.Exec(ctx, `
+				DELETE FROM instance.data_lock
+				WHERE relation_id = $1
+			`, rel.Id); err != nil {
+				return err
+			}
+		}
+
+		// soft delete option changed, add or remove deletion date column
+		if softDeleteEx != rel.SoftDelete {
+			if err := setSoftDelete_tx(ctx, tx, moduleName, nameEx, rel.SoftDelete); err != nil {
+				return err
+			}
+		}
+
 		// if name changed, update relation and all affected entities
 		if nameEx != rel.Name {
 			if _, err := tx.Exec(ctx, fmt.Sprintf(`
@@ -168,15 +198,23 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 
 		// insert relation reference
 		if _, err := tx.Exec(ctx, `
-			INSERT INTO app.relation (id, module_id, name, comment,
-				encryption, retention_count, retention_days)
-			VALUES ($1,$2,$3,$4,$5,$6,$7)
+			INSERT INTO app.relation (id, module_id, name, comment, encryption,
+				retention_count, retention_days, soft_delete, soft_delete_days,
+				record_lock)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		`, rel.Id, rel.ModuleId, rel.Name, rel.Comment, rel.Encryption,
-			rel.RetentionCount, rel.RetentionDays); err != nil {
+			rel.RetentionCount, rel.RetentionDays, rel.SoftDelete,
+			rel.SoftDeleteDays, rel.RecordLock); err != nil {
 
 			return err
 		}
 
+		if rel.SoftDelete {
+			if err := setSoftDelete_tx(ctx, tx, moduleName, rel.Name, true); err != nil {
+				return err
+			}
+		}
+
 		// create primary key attribute if relation is new (e. g. not imported or updated)
 		if isNew {
 			if err := attribute.Set_tx(ctx, tx, types.Attribute{
@@ -203,3 +241,27 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 	// set policies
 	return setPolicies_tx(ctx, tx, rel.Id, rel.Policies)
 }
+
+// adds or removes column for deletion date of soft deleted records
+// when disabled, records still in the trash bin are deleted permanently
+func setSoftDelete_tx(ctx context.Context, tx pgx.Tx, moduleName string,
+	relationName string, enable bool) error {
+
+	if enable {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			ALTER TABLE "%s"."%s" ADD COLUMN IF NOT EXISTS "%s" BIGINT
+		`, moduleName, relationName, schema.SoftDeleteName))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		DELETE FROM "%s"."%s"
+		WHERE "%s" IS NOT NULL
+	`, moduleName, relationName, schema.SoftDeleteName)); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s" DROP COLUMN IF EXISTS "%s"
+	`, moduleName, relationName, schema.SoftDeleteName))
+	return err
+}
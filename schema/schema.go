@@ -54,6 +54,7 @@ const (
 	DbSearchBar             DbEntity = "search_bar"
 	DbTab                   DbEntity = "tab"
 	DbVariable              DbEntity = "variable"
+	DbWebhook               DbEntity = "webhook"
 	DbWidget                DbEntity = "widget"
 )
 
@@ -138,6 +139,7 @@ var (
 		DbRole,
 		DbSearchBar,
 		DbVariable,
+		DbWebhook,
 		DbWidget,
 	}
 	DbTransferDeleteRelation = []DbEntity{
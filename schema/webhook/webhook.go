@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"r3/db/check"
+	"r3/schema"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func Del_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `DELETE FROM app.webhook WHERE id = $1`, id)
+	return err
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Webhook, error) {
+
+	webhooks := make([]types.Webhook, 0)
+	rows, err := tx.Query(ctx, `
+		SELECT id, relation_id, api_id, name, comment, url,
+			on_delete, on_insert, on_update, skip_verify
+		FROM app.webhook
+		WHERE module_id = $1
+		ORDER BY name ASC
+	`, moduleId)
+	if err != nil {
+		return webhooks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w types.Webhook
+		w.ModuleId = moduleId
+		if err := rows.Scan(&w.Id, &w.RelationId, &w.ApiId, &w.Name, &w.Comment,
+			&w.Url, &w.OnDelete, &w.OnInsert, &w.OnUpdate, &w.SkipVerify); err != nil {
+
+			return webhooks, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func Set_tx(ctx context.Context, tx pgx.Tx, w types.Webhook) error {
+
+	if err := check.DbIdentifier(w.Name); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(w.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook URL must be a valid HTTP(S) address")
+	}
+
+	// payload API must query the webhook relation
+	if w.ApiId.Valid {
+		var relationId uuid.UUID
+		if err := tx.QueryRow(ctx, `
+			SELECT relation_id
+			FROM app.query
+			WHERE api_id = $1
+		`, w.ApiId).Scan(&relationId); err != nil {
+			return err
+		}
+		if relationId != w.RelationId {
+			return errors.New("webhook payload API must use the webhook relation as base relation")
+		}
+	}
+
+	known, err := schema.CheckCreateId_tx(ctx, tx, &w.Id, schema.DbWebhook, "id")
+	if err != nil {
+		return err
+	}
+
+	if known {
+		if _, err := tx.Exec(ctx, `
+			UPDATE app.webhook
+			SET api_id = $1, name = $2, comment = $3, url = $4, on_delete = $5,
+				on_insert = $6, on_update = $7, skip_verify = $8
+			WHERE id = $9
+		`, w.ApiId, w.Name, w.Comment, w.Url, w.OnDelete, w.OnInsert,
+			w.OnUpdate, w.SkipVerify, w.Id); err != nil {
+
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.webhook (id, module_id, relation_id, api_id, name,
+				comment, url, on_delete, on_insert, on_update, skip_verify)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		`, w.Id, w.ModuleId, w.RelationId, w.ApiId, w.Name, w.Comment, w.Url,
+			w.OnDelete, w.OnInsert, w.OnUpdate, w.SkipVerify); err != nil {
+
+			return err
+		}
+	}
+	return nil
+}
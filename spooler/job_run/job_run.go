@@ -0,0 +1,270 @@
+// for executing backend functions from the instance job queue
+// jobs are claimed by all cluster nodes, the concurrency limit of a queue applies to the entire cluster
+
+package job_run
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/log"
+	"r3/schema"
+	"r3/tools"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	// grace period after queue timeout, before a running job is considered abandoned (node stopped during execution)
+	abandonedAfterTimeout int64 = 60
+
+	// maximum delay between attempts in seconds, exponential backoff is capped to it
+	backoffMax int64 = 86400
+
+	resetOwnDone bool = false // jobs left running by this node before its last restart were reset
+)
+
+type job struct {
+	id           int64
+	queueName    string
+	pgFunctionId uuid.UUID
+	args         []interface{}
+	attemptCount int
+	timeout      int64
+}
+
+// claims waiting jobs of all queues up to their concurrency limits and executes them in the background
+// each executor claims the next job of its queue when done, until the queue is empty
+func DoAll() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	if err := resetAbandoned(ctx); err != nil {
+		return err
+	}
+
+	queueNames := make([]string, 0)
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(name ORDER BY name), '{}')
+		FROM instance.job_queue
+	`).Scan(&queueNames); err != nil {
+		return err
+	}
+
+	for _, queueName := range queueNames {
+		jobs, err := claim(ctx, queueName, 0)
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			go execute(j)
+		}
+	}
+	return nil
+}
+
+// claims waiting jobs of queue, limited by free concurrency slots of the queue and the given limit (0 = no limit)
+func claim(ctx context.Context, queueName string, limit int) ([]job, error) {
+	jobs := make([]job, 0)
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return jobs, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock queue to apply its concurrency limit, skip queue if another node is currently claiming from it
+	var concurrencyMax int
+	var timeout int64
+	rows, err := tx.Query(ctx, `
+		SELECT concurrency_max, timeout
+		FROM instance.job_queue
+		WHERE name = $1
+		FOR UPDATE SKIP LOCKED
+	`, queueName)
+	if err != nil {
+		return jobs, err
+	}
+	locked := false
+	for rows.Next() {
+		if err := rows.Scan(&concurrencyMax, &timeout); err != nil {
+			rows.Close()
+			return jobs, err
+		}
+		locked = true
+	}
+	rows.Close()
+
+	if !locked {
+		return jobs, nil
+	}
+
+	var runningCount int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.job
+		WHERE queue_name = $1
+		AND   state      = 'running'
+	`, queueName).Scan(&runningCount); err != nil {
+		return jobs, err
+	}
+
+	free := concurrencyMax - runningCount
+	if limit != 0 && free > limit {
+		free = limit
+	}
+	if free <= 0 {
+		return jobs, nil
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE instance.job
+		SET state = 'running', node_id = $1, date_start = $2, date_end = NULL,
+			attempt_count = attempt_count + 1
+		WHERE id IN (
+			SELECT id
+			FROM instance.job
+			WHERE queue_name = $3
+			AND   state      = 'waiting'
+			AND   date_next <= $2
+			ORDER BY date_next ASC, id ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, pg_function_id, args, attempt_count
+	`, cache.GetNodeId(), tools.GetTimeUnix(), queueName, free)
+	if err != nil {
+		return jobs, err
+	}
+	for rows.Next() {
+		j := job{queueName: queueName, timeout: timeout}
+		if err := rows.Scan(&j.id, &j.pgFunctionId, &j.args, &j.attemptCount); err != nil {
+			rows.Close()
+			return jobs, err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return jobs, err
+	}
+	return jobs, tx.Commit(ctx)
+}
+
+// executes job, then continues with the next waiting job of the same queue
+func execute(j job) {
+	for {
+		errRun := run(j)
+		if errRun != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("job %d of queue '%s' failed (attempt %d)",
+				j.id, j.queueName, j.attemptCount), errRun)
+		}
+		if err := finish(j, errRun); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("failed to update state of job %d", j.id), err)
+			return
+		}
+
+		ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+		jobs, err := claim(ctx, j.queueName, 1)
+		ctxCanc()
+
+		if err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("failed to claim job of queue '%s'", j.queueName), err)
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+		j = jobs[0]
+	}
+}
+
+func run(j job) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), time.Duration(j.timeout)*time.Second)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	modName, fncName, _, isTrigger, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, j.pgFunctionId)
+	if err != nil {
+		return err
+	}
+	if isTrigger {
+		return fmt.Errorf("trigger function '%s.%s' cannot be executed as job", modName, fncName)
+	}
+
+	placeholders := make([]string, 0)
+	for i := range j.args {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	}
+
+	log.Info(log.ContextScheduler, fmt.Sprintf("is executing job %d, function '%s.%s'", j.id, modName, fncName))
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"(%s)`,
+		modName, fncName, strings.Join(placeholders, ",")), j.args...); err != nil {
+
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// stores job outcome, failed jobs are retried with exponential backoff until their queue's attempt limit is reached
+// exponent and delay are limited, as high attempt limits would overflow the delay
+func finish(j job, errRun error) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	if errRun == nil {
+		_, err := db.Pool.Exec(ctx, `
+			UPDATE instance.job
+			SET state = 'done', date_end = $1, last_error = NULL
+			WHERE id = $2
+		`, tools.GetTimeUnix(), j.id)
+		return err
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.job AS j
+		SET state      = CASE WHEN j.attempt_count >= q.attempt_max THEN 'failed' ELSE 'waiting' END::instance.job_state,
+			date_end   = $1,
+			date_next  = $1 + LEAST(q.backoff_base * POWER(2, LEAST(j.attempt_count - 1, 30)), $4)::BIGINT,
+			last_error = $2
+		FROM instance.job_queue AS q
+		WHERE q.name = j.queue_name
+		AND   j.id   = $3
+	`, tools.GetTimeUnix(), errRun.Error(), j.id, backoffMax)
+	return err
+}
+
+// resets running jobs, whose executing node stopped or which exceeded their queue timeout
+// jobs of this node are reset once after start, as they cannot be running anymore
+// reset counts as failed attempt
+func resetAbandoned(ctx context.Context) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.job AS j
+		SET state      = CASE WHEN j.attempt_count >= q.attempt_max THEN 'failed' ELSE 'waiting' END::instance.job_state,
+			date_end   = $1,
+			date_next  = $1,
+			last_error = 'execution was aborted, node stopped or timeout was exceeded'
+		FROM instance.job_queue AS q
+		WHERE q.name  = j.queue_name
+		AND   j.state = 'running'
+		AND (
+			j.date_start + q.timeout + $2 < $1
+			OR (NOT $3 AND j.node_id = $4)
+		)
+	`, tools.GetTimeUnix(), abandonedAfterTimeout, resetOwnDone, cache.GetNodeId())
+
+	if err == nil {
+		resetOwnDone = true
+	}
+	return err
+}
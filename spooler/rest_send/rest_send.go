@@ -4,6 +4,10 @@ package rest_send
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,15 +15,18 @@ import (
 	"r3/config"
 	"r3/db"
 	"r3/log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 var (
-	attemptsAllow = 5   // how many attempts for each REST call before quitting
-	callLimit     = 100 // how many REST calls to execute per loop
+	backoffMax    = 86400 // maximum delay between attempts in seconds, exponential backoff is capped to it
+	callLimit     = 100   // how many REST calls to execute per loop
+	responseLimit = 10000 // how many bytes of a failed response to keep for debugging
 )
 
 type restCall struct {
@@ -31,6 +38,15 @@ type restCall struct {
 	body                 pgtype.Text
 	callbackValue        pgtype.Text
 	skipVerify           bool
+	statusSuccess        string
+	webhookId            pgtype.UUID // webhook that spooled the call, webhook calls are signed
+}
+
+// failed REST call attempt, status and response are set if a response was received
+type restError struct {
+	err      error
+	status   pgtype.Int4
+	response pgtype.Text
 }
 
 func DoAll() error {
@@ -39,13 +55,14 @@ func DoAll() error {
 
 		// collect spooled REST calls
 		rows, err := db.Pool.Query(context.Background(), `
-			SELECT id, pg_function_id_callback, method, headers,
-				url, body, callback_value, skip_verify
+			SELECT id, pg_function_id_callback, method, headers, url, body,
+				callback_value, skip_verify, status_success, webhook_id
 			FROM instance.rest_spool
-			WHERE attempt_count < $1
+			WHERE attempt_count < attempt_max
+			AND   date_next     <= EXTRACT(EPOCH FROM NOW())
 			ORDER BY date_added ASC
-			LIMIT $2
-		`, attemptsAllow, callLimit)
+			LIMIT $1
+		`, callLimit)
 		if err != nil {
 			return err
 		}
@@ -55,7 +72,8 @@ func DoAll() error {
 		for rows.Next() {
 			var c restCall
 			if err := rows.Scan(&c.id, &c.pgFunctionIdCallback, &c.method, &c.headers,
-				&c.url, &c.body, &c.callbackValue, &c.skipVerify); err != nil {
+				&c.url, &c.body, &c.callbackValue, &c.skipVerify, &c.statusSuccess,
+				&c.webhookId); err != nil {
 
 				return err
 			}
@@ -64,14 +82,21 @@ func DoAll() error {
 		rows.Close()
 
 		for _, c := range calls {
-			if err := callExecute(c); err != nil {
-				log.Error(log.ContextApi, fmt.Sprintf("failed to execute REST call %s '%s'", c.method, c.url), err)
+			if errCall := callExecute(c); errCall.err != nil {
+				log.Error(log.ContextApi, fmt.Sprintf("failed to execute REST call %s '%s'", c.method, c.url), errCall.err)
 
+				// exponential backoff, next attempt is delayed further with each failure
+				// exponent is limited as well, as high attempt counts would overflow the delay
 				_, err := db.Pool.Exec(context.Background(), `
 					UPDATE instance.rest_spool
-					SET attempt_count = attempt_count + 1
+					SET attempt_count = attempt_count + 1,
+						date_attempt  = EXTRACT(EPOCH FROM NOW()),
+						date_next     = EXTRACT(EPOCH FROM NOW()) + LEAST(backoff_base * POWER(2, LEAST(attempt_count, 30)), $5)::BIGINT,
+						last_error    = $2,
+						last_status   = $3,
+						last_response = $4
 					WHERE id = $1
-				`, c.id)
+				`, c.id, errCall.err.Error(), errCall.status, errCall.response, backoffMax)
 
 				if err != nil {
 					log.Error(log.ContextApi, "failed to update call attempt count", err)
@@ -89,12 +114,15 @@ func DoAll() error {
 	return nil
 }
 
-func callExecute(c restCall) error {
+func callExecute(c restCall) restError {
 	log.Info(log.ContextApi, fmt.Sprintf("is calling %s '%s'", c.method, c.url))
 
+	var errCall restError
+
 	httpReq, err := http.NewRequest(c.method, c.url, strings.NewReader(c.body.String))
 	if err != nil {
-		return fmt.Errorf("could not prepare request, %s", err)
+		errCall.err = fmt.Errorf("could not prepare request, %s", err)
+		return errCall
 	}
 
 	httpReq.Header.Set("User-Agent", "r3-application")
@@ -102,17 +130,52 @@ func callExecute(c restCall) error {
 		httpReq.Header.Set(k, v)
 	}
 
+	// sign webhook calls, receivers can verify origin and reject replays via timestamp
+	// signature: HMAC-SHA256 over "{timestamp}.{body}" with the instance webhook secret
+	if c.webhookId.Valid {
+		timestamp := fmt.Sprintf("%d", time.Now().Unix())
+		mac := hmac.New(sha256.New, []byte(config.GetString("webhookSecret")))
+		mac.Write([]byte(timestamp + "." + c.body.String))
+
+		httpReq.Header.Set("X-Webhook-Id", c.id.String())
+		httpReq.Header.Set("X-Webhook-Timestamp", timestamp)
+		httpReq.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
 	httpClient, err := config.GetHttpClient(c.skipVerify, 30)
 	if err != nil {
-		return err
+		errCall.err = err
+		return errCall
 	}
 
 	httpRes, err := httpClient.Do(httpReq)
 	if err != nil {
-		return err
+		errCall.err = err
+		return errCall
 	}
 	defer httpRes.Body.Close()
 
+	bodyRaw, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		errCall.err = fmt.Errorf("could not read response body, %s", err)
+		return errCall
+	}
+
+	success, err := statusIsSuccess(httpRes.StatusCode, c.statusSuccess)
+	if err != nil || !success {
+		if err == nil {
+			err = fmt.Errorf("HTTP status code %d is not within success codes '%s'",
+				httpRes.StatusCode, c.statusSuccess)
+		}
+		if len(bodyRaw) > responseLimit {
+			bodyRaw = bodyRaw[:responseLimit]
+		}
+		errCall.err = err
+		errCall.status = pgtype.Int4{Int32: int32(httpRes.StatusCode), Valid: true}
+		errCall.response = pgtype.Text{String: strings.ToValidUTF8(string(bodyRaw), ""), Valid: true}
+		return errCall
+	}
+
 	// successfully executed
 	// execute callback if enabled
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc)
@@ -120,29 +183,28 @@ func callExecute(c restCall) error {
 
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
-		return err
+		errCall.err = err
+		return errCall
 	}
 	defer tx.Rollback(ctx)
 
 	if c.pgFunctionIdCallback.Valid {
-		bodyRaw, err := io.ReadAll(httpRes.Body)
-		if err != nil {
-			return fmt.Errorf("could not read response body, %s", err)
-		}
-
 		fnc, exists := cache.PgFunctionIdMap[c.pgFunctionIdCallback.Bytes]
 		if !exists {
-			return fmt.Errorf("unknown function '%s'", c.pgFunctionIdCallback.String())
+			errCall.err = fmt.Errorf("unknown function '%s'", c.pgFunctionIdCallback.String())
+			return errCall
 		}
 		mod, exists := cache.ModuleIdMap[fnc.ModuleId]
 		if !exists {
-			return fmt.Errorf("unknown module '%s'", fnc.ModuleId)
+			errCall.err = fmt.Errorf("unknown module '%s'", fnc.ModuleId)
+			return errCall
 		}
 
 		if _, err := tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"($1,$2,$3)`,
 			mod.Name, fnc.Name), httpRes.StatusCode, bodyRaw, c.callbackValue); err != nil {
 
-			return err
+			errCall.err = err
+			return errCall
 		}
 	}
 
@@ -151,7 +213,36 @@ func callExecute(c restCall) error {
 		DELETE FROM instance.rest_spool
 		WHERE id = $1
 	`, c.id); err != nil {
-		return err
+		errCall.err = err
+		return errCall
+	}
+	errCall.err = tx.Commit(ctx)
+	return errCall
+}
+
+// checks whether HTTP status code is within success definition
+// definition is a comma separated list of codes or code ranges, e. g. '200-299,304'
+func statusIsSuccess(status int, definition string) (bool, error) {
+	for _, part := range strings.Split(definition, ",") {
+		bounds := strings.Split(strings.TrimSpace(part), "-")
+		if len(bounds) > 2 {
+			return false, fmt.Errorf("invalid success code range '%s'", part)
+		}
+
+		from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return false, errors.New("invalid success code definition, codes must be integers")
+		}
+		to := from
+		if len(bounds) == 2 {
+			to, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err != nil {
+				return false, errors.New("invalid success code definition, codes must be integers")
+			}
+		}
+		if status >= from && status <= to {
+			return true, nil
+		}
 	}
-	return tx.Commit(ctx)
+	return false, nil
 }
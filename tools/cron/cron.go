@@ -0,0 +1,304 @@
+// cron expressions with 5 fields: minute hour day-of-month month day-of-week
+// supports lists (1,2), ranges (1-5), steps (*/15, 10-40/10), month & weekday names (JAN, MON)
+// day-of-month also supports L (last day), LW (last weekday) and xW (weekday nearest to day x)
+// day-of-week also supports x#n (n-th weekday x of month) and xL (last weekday x of month)
+// if both day fields are restricted, a day matches if either field matches (like Vixie cron)
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how far ahead the next execution is searched for
+const searchDaysMax = 366 * 5
+
+var (
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+	monthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+type Expression struct {
+	minutes  uint64 // bit set, 0-59
+	hours    uint64 // bit set, 0-23
+	days     uint64 // bit set, 1-31
+	months   uint64 // bit set, 1-12
+	weekdays uint64 // bit set, 0-6 (0 = Sunday)
+
+	daysAny     bool // day-of-month field is unrestricted (* or ?)
+	weekdaysAny bool // day-of-week field is unrestricted (* or ?)
+
+	// special day-of-month values
+	dayLast        bool  // L
+	dayLastWeekday bool  // LW
+	dayNearest     []int // xW
+
+	// special day-of-week values
+	weekdayLast []int    // xL
+	weekdayNth  [][2]int // x#n, [weekday, n]
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string // optional names, index 0 = min
+}
+
+var (
+	fieldMinute  = field{"minute", 0, 59, nil}
+	fieldHour    = field{"hour", 0, 23, nil}
+	fieldDay     = field{"day of month", 1, 31, nil}
+	fieldMonth   = field{"month", 1, 12, monthNames}
+	fieldWeekday = field{"day of week", 0, 7, weekdayNames} // 7 = Sunday
+)
+
+func Parse(expr string) (Expression, error) {
+	var e Expression
+
+	expr = strings.TrimSpace(expr)
+	if macro, exists := macros[strings.ToLower(expr)]; exists {
+		expr = macro
+	}
+
+	parts := strings.Fields(strings.ToUpper(expr))
+	if len(parts) != 5 {
+		return e, fmt.Errorf("cron expression '%s' must have 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+
+	var err error
+	if e.minutes, err = parseField(parts[0], fieldMinute); err != nil {
+		return e, err
+	}
+	if e.hours, err = parseField(parts[1], fieldHour); err != nil {
+		return e, err
+	}
+	if e.months, err = parseField(parts[3], fieldMonth); err != nil {
+		return e, err
+	}
+
+	// day of month
+	e.daysAny = parts[2] == "*" || parts[2] == "?"
+	if e.daysAny {
+		parts[2] = "*"
+	}
+	for _, item := range strings.Split(parts[2], ",") {
+		switch {
+		case item == "L":
+			e.dayLast = true
+		case item == "LW":
+			e.dayLastWeekday = true
+		case strings.HasSuffix(item, "W"):
+			day, err := parseValue(strings.TrimSuffix(item, "W"), fieldDay)
+			if err != nil {
+				return e, err
+			}
+			e.dayNearest = append(e.dayNearest, day)
+		default:
+			bits, err := parseField(item, fieldDay)
+			if err != nil {
+				return e, err
+			}
+			e.days |= bits
+		}
+	}
+
+	// day of week
+	e.weekdaysAny = parts[4] == "*" || parts[4] == "?"
+	if e.weekdaysAny {
+		parts[4] = "*"
+	}
+	for _, item := range strings.Split(parts[4], ",") {
+		switch {
+		case strings.Contains(item, "#"):
+			weekdayNth := strings.SplitN(item, "#", 2)
+			weekday, err := parseValue(weekdayNth[0], fieldWeekday)
+			if err != nil {
+				return e, err
+			}
+			nth, err := strconv.Atoi(weekdayNth[1])
+			if err != nil || nth < 1 || nth > 5 {
+				return e, fmt.Errorf("invalid occurrence in cron day of week '%s', must be 1-5", item)
+			}
+			e.weekdayNth = append(e.weekdayNth, [2]int{weekday % 7, nth})
+		case len(item) > 1 && strings.HasSuffix(item, "L"):
+			weekday, err := parseValue(strings.TrimSuffix(item, "L"), fieldWeekday)
+			if err != nil {
+				return e, err
+			}
+			e.weekdayLast = append(e.weekdayLast, weekday%7)
+		default:
+			bits, err := parseField(item, fieldWeekday)
+			if err != nil {
+				return e, err
+			}
+			e.weekdays |= bits
+		}
+	}
+
+	// Sunday can be given as 0 or 7
+	if e.weekdays&(1<<7) != 0 {
+		e.weekdays |= 1
+	}
+	return e, nil
+}
+
+// returns next execution time after given time, in the location of the given time
+// wall clock times skipped by DST changes are executed right after the change
+// wall clock times repeated by DST changes are executed once
+// returns false if expression does not match any time within the search range
+func (e Expression) Next(after time.Time) (time.Time, bool) {
+	loc := after.Location()
+	year, month, day := after.Date()
+
+	for i := 0; i < searchDaysMax; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+
+		if e.months&(1<<uint(date.Month())) == 0 || !e.matchDay(date) {
+			continue
+		}
+
+		for h := 0; h < 24; h++ {
+			if e.hours&(1<<uint(h)) == 0 {
+				continue
+			}
+			for m := 0; m < 60; m++ {
+				if e.minutes&(1<<uint(m)) == 0 {
+					continue
+				}
+				t := time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, loc)
+				if t.After(after) {
+					return t, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (e Expression) matchDay(date time.Time) bool {
+	day := date.Day()
+	weekday := int(date.Weekday())
+	daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	matchDom := e.days&(1<<uint(day)) != 0 ||
+		(e.dayLast && day == daysInMonth) ||
+		(e.dayLastWeekday && day == getNearestWeekday(date, daysInMonth, daysInMonth))
+
+	for _, d := range e.dayNearest {
+		if d <= daysInMonth && day == getNearestWeekday(date, d, daysInMonth) {
+			matchDom = true
+		}
+	}
+
+	matchDow := e.weekdays&(1<<uint(weekday)) != 0
+	for _, wn := range e.weekdayNth {
+		if weekday == wn[0] && (day-1)/7+1 == wn[1] {
+			matchDow = true
+		}
+	}
+	for _, w := range e.weekdayLast {
+		if weekday == w && day+7 > daysInMonth {
+			matchDow = true
+		}
+	}
+
+	switch {
+	case e.daysAny && e.weekdaysAny:
+		return true
+	case e.daysAny:
+		return matchDow
+	case e.weekdaysAny:
+		return matchDom
+	}
+	return matchDom || matchDow
+}
+
+// returns weekday (Monday-Friday) nearest to target day within the month of given date
+func getNearestWeekday(date time.Time, targetDay int, daysInMonth int) int {
+	switch time.Date(date.Year(), date.Month(), targetDay, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if targetDay == 1 {
+			return targetDay + 2
+		}
+		return targetDay - 1
+	case time.Sunday:
+		if targetDay == daysInMonth {
+			return targetDay - 2
+		}
+		return targetDay + 1
+	}
+	return targetDay
+}
+
+// parses comma separated field items, returns bit set of matching values
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(value, ",") {
+		rangeMin, rangeMax, step := f.min, f.max, 1
+
+		if rangeStep := strings.SplitN(item, "/", 2); len(rangeStep) == 2 {
+			var err error
+			if step, err = strconv.Atoi(rangeStep[1]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in cron %s '%s'", f.name, item)
+			}
+			item = rangeStep[0]
+
+			// single value with step runs until max value (5/15 = 5-max/15)
+			if !strings.Contains(item, "-") && item != "*" {
+				if rangeMin, err = parseValue(item, f); err != nil {
+					return 0, err
+				}
+				item = "*"
+			}
+		}
+
+		if item != "*" {
+			var err error
+			fromTo := strings.SplitN(item, "-", 2)
+			if rangeMin, err = parseValue(fromTo[0], f); err != nil {
+				return 0, err
+			}
+			rangeMax = rangeMin
+			if len(fromTo) == 2 {
+				if rangeMax, err = parseValue(fromTo[1], f); err != nil {
+					return 0, err
+				}
+			}
+			if rangeMax < rangeMin {
+				return 0, fmt.Errorf("invalid range in cron %s '%s'", f.name, item)
+			}
+		}
+
+		for v := rangeMin; v <= rangeMax; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parses single number or name value of field
+func parseValue(value string, f field) (int, error) {
+	for i, name := range f.names {
+		if value == name {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid cron %s '%s', must be %d-%d", f.name, value, f.min, f.max)
+	}
+	return v, nil
+}
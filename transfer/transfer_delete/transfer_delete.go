@@ -28,6 +28,7 @@ import (
 	"r3/schema/searchBar"
 	"r3/schema/tab"
 	"r3/schema/variable"
+	"r3/schema/webhook"
 	"r3/schema/widget"
 	"r3/types"
 	"slices"
@@ -118,6 +119,11 @@ func NotExisting_tx(ctx context.Context, tx pgx.Tx, module types.Module) error {
 		return err
 	}
 
+	// webhooks
+	if err := deleteWebhooks_tx(ctx, tx, module.Id, module.Webhooks); err != nil {
+		return err
+	}
+
 	// widgets
 	if err := deleteWidgets_tx(ctx, tx, module.Id, module.Widgets); err != nil {
 		return err
@@ -215,6 +221,15 @@ func deleteRelationAttributes_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.U
 	if err != nil {
 		return err
 	}
+
+	// delete generated attributes first, their expressions depend on other attributes
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(id ORDER BY generated IS NULL), '{}')
+		FROM app.attribute
+		WHERE id = ANY($1)
+	`, idsDelete).Scan(&idsDelete); err != nil {
+		return err
+	}
 	for _, id := range idsDelete {
 		log.Info(log.ContextTransfer, fmt.Sprintf("del attribute %s", id.String()))
 		if err := attribute.Del_tx(ctx, tx, id); err != nil {
@@ -567,6 +582,23 @@ func deleteVariables_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, vari
 	}
 	return nil
 }
+func deleteWebhooks_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, webhooks []types.Webhook) error {
+	idsKeep := make([]uuid.UUID, 0)
+	for _, entity := range webhooks {
+		idsKeep = append(idsKeep, entity.Id)
+	}
+	idsDelete, err := importGetIdsToDeleteFromModule_tx(ctx, tx, schema.DbWebhook, moduleId, idsKeep)
+	if err != nil {
+		return err
+	}
+	for _, id := range idsDelete {
+		log.Info(log.ContextTransfer, fmt.Sprintf("del webhook %s", id.String()))
+		if err := webhook.Del_tx(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func deleteWidgets_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, widgets []types.Widget) error {
 	idsKeep := make([]uuid.UUID, 0)
 	for _, entity := range widgets {
@@ -35,6 +35,7 @@ import (
 	"r3/schema/role"
 	"r3/schema/searchBar"
 	"r3/schema/variable"
+	"r3/schema/webhook"
 	"r3/schema/widget"
 	"r3/tools"
 	"r3/transfer/transfer_delete"
@@ -294,7 +295,7 @@ func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun
 	// attributes
 	for _, relation := range mod.Relations {
 		for _, e := range relation.Attributes {
-			if e.Name == schema.PkName {
+			if e.Name == schema.PkName || e.Generated.Valid {
 				continue
 			}
 
@@ -313,6 +314,28 @@ func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun
 		}
 	}
 
+	// generated attributes, refer to other attributes of the same relation
+	for _, relation := range mod.Relations {
+		for _, e := range relation.Attributes {
+			if !e.Generated.Valid {
+				continue
+			}
+
+			run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
+			if err != nil {
+				return err
+			}
+			if !run {
+				continue
+			}
+			log.Info(log.ContextTransfer, fmt.Sprintf("set generated attribute %s", e.Id))
+
+			if err := importCheckResultAndApply(ctx, tx, attribute.Set_tx(ctx, tx, e), e.Id, idMapSkipped); err != nil {
+				return err
+			}
+		}
+	}
+
 	// collections
 	for _, e := range mod.Collections {
 		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
@@ -380,6 +403,22 @@ func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun
 		}
 	}
 
+	// webhooks, refer to relations/APIs
+	for _, e := range mod.Webhooks {
+		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
+		if err != nil {
+			return err
+		}
+		if !run {
+			continue
+		}
+		log.Info(log.ContextTransfer, fmt.Sprintf("set webhook %s", e.Id))
+
+		if err := importCheckResultAndApply(ctx, tx, webhook.Set_tx(ctx, tx, e), e.Id, idMapSkipped); err != nil {
+			return err
+		}
+	}
+
 	// widgets
 	for _, e := range mod.Widgets {
 		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
@@ -23,6 +23,20 @@ type Log struct {
 	Date       int64       `json:"date"`
 }
 
+type LogSlowQuery struct {
+	Action       string      `json:"action"` // get, set, del
+	LoginName    pgtype.Text `json:"loginName"`
+	RelationIds  []uuid.UUID `json:"relationIds"`  // relations read or written
+	ApiId        pgtype.UUID `json:"apiId"`        // origin API
+	CollectionId pgtype.UUID `json:"collectionId"` // origin collection
+	FieldId      pgtype.UUID `json:"fieldId"`      // origin field
+	FormId       pgtype.UUID `json:"formId"`       // origin form (of field or form query)
+	NodeName     pgtype.Text `json:"nodeName"`
+	DurationMs   int64       `json:"durationMs"`
+	Date         int64       `json:"date"` // in ms
+	Sql          pgtype.Text `json:"sql"`
+}
+
 type LoginAdmin struct {
 	Id               int64              `json:"id"`
 	LdapId           pgtype.Int4        `json:"ldapId"`
@@ -111,3 +125,21 @@ type OauthClientOpenId struct {
 	RedirectUrl pgtype.Text `json:"redirectUrl"`
 	Scopes      []string    `json:"scopes"`
 }
+
+// outcome of manual PG function task run
+// row counts are taken from transaction statistics, they include changes made by triggers
+type TaskRunManualResult struct {
+	DryRun      bool                    `json:"dryRun"` // changes were rolled back
+	DurationMs  int64                   `json:"durationMs"`
+	Error       pgtype.Text             `json:"error"`
+	Notices     []string                `json:"notices"`
+	ReturnValue pgtype.Text             `json:"returnValue"` // function return value as text
+	RowCounts   []TaskRunManualRowCount `json:"rowCounts"`
+}
+type TaskRunManualRowCount struct {
+	Schema   string `json:"schema"`
+	Table    string `json:"table"`
+	Inserted int64  `json:"inserted"`
+	Updated  int64  `json:"updated"`
+	Deleted  int64  `json:"deleted"`
+}
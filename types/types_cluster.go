@@ -29,12 +29,25 @@ type ClusterEventFileRequested struct {
 	FileHash    string    `json:"fileHash"`
 	FileName    string    `json:"fileName"`
 }
+type ClusterEventRecordLockChanged struct {
+	RelationId uuid.UUID `json:"relationId"`
+	RecordId   int64     `json:"recordId"`
+	Locked     bool      `json:"locked"`    // record was locked or released
+	LoginId    int64     `json:"loginId"`   // login holding the lock, 0 if released
+	LoginName  string    `json:"loginName"` // name of login holding the lock, empty if released
+}
 type ClusterEventJsFunctionCalled struct {
 	ModuleId     uuid.UUID     `json:"moduleId"` // module ID that JS function belongs to, relevant for filtering to direct app access
 	JsFunctionId uuid.UUID     `json:"jsFunctionId"`
 	Arguments    []interface{} `json:"arguments"`
 }
 
+// cluster event payloads used by cluster nodes
+type ClusterEventDataChanged struct {
+	ClientIdOrigin uuid.UUID `json:"clientIdOrigin"` // websocket client that changed the data, nil UUID if not changed by websocket client
+	RelationId     uuid.UUID `json:"relationId"`
+}
+
 // cluster event payloads used by instance functions
 type ClusterEventCollectionUpdated struct {
 	// filled by instance.update_collection()
@@ -59,6 +72,9 @@ type ClusterEventTarget struct {
 	Device  WebsocketClientDevice `json:"device"`  // device to affect ("browser", "fatClient"), 0 = undefined
 	LoginId int64                 `json:"loginId"` // login ID to affect, 0 = undefined
 
+	// exclusion filters, target must not match if filter is defined
+	ClientIdExcluded uuid.UUID `json:"clientIdExcluded"` // websocket client not to affect (originator of change), nil UUID = undefined
+
 	// preferred filters, prioritize target if it matches filter, otherwise send it to others
 	PwaModuleIdPreferred uuid.UUID `json:"pwaModuleIdPreferred"` // client connecting via PWA sub host (direct app access), nil UUID = undefined
 }
@@ -36,6 +36,9 @@ type FileType struct {
 		Listen        string `json:"listen"`
 		Port          int    `json:"port"`
 		TlsMinVersion string `json:"tlsMinVersion"`
+
+		// IP addresses or networks (CIDR) of reverse proxies, whose X-Forwarded-For header is used to get client addresses
+		TrustedProxies []string `json:"trustedProxies"`
 	} `json:"web"`
 }
 
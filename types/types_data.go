@@ -61,9 +61,16 @@ type DataGetExpression struct {
 
 	// expression options
 	Aggregator pgtype.Text `json:"aggregator"` // set AGGREGATE function (min, max, avg, count, ...)
+	DateBucket pgtype.Text `json:"dateBucket"` // truncate date/datetime value to start of period (hour, day, week, month, quarter, year)
 	Distincted bool        `json:"distincted"` // set DISTINCT
 	GroupBy    bool        `json:"groupBy"`    // set GROUP BY
 	ReturnNull bool        `json:"returnNull"` // return NULL (ignores everything else)
+
+	// window function options
+	Window          pgtype.Text    `json:"window"`          // window function (rank, rowNumber, lag, lead, runningSum, ...)
+	WindowOffset    int            `json:"windowOffset"`    // row offset for lag/lead (1 if not set)
+	WindowOrders    []DataGetOrder `json:"windowOrders"`    // window order, data GET orders are used if empty (ranking: expression value)
+	WindowPartition []int          `json:"windowPartition"` // positions of expressions to partition window by
 }
 
 type DataGetOrder struct {
@@ -89,13 +96,21 @@ type DataGet struct {
 	Offset      int                 `json:"offset"`      // result offset
 	GetPerm     bool                `json:"getPerm"`     // get result permissions (SET/DEL) from relation policy, GET is ignored as results are filtered by it already
 	SearchDicts []string            `json:"searchDicts"` // list of fulltext search dictionaries (english, german, ...)
+	QueryId     pgtype.UUID         `json:"queryId"`     // query the request originates from (field, form, collection), for slow query log
+	GetVersions bool                `json:"getVersions"` // get record versions of relation indexes, to check for changes on update (optimistic locking)
+
+	// grouping of expressions with GROUP BY, regular grouping if empty
+	Grouping     string  `json:"grouping"`     // rollup, cube, sets
+	GroupingSets [][]int `json:"groupingSets"` // grouping sets, positions of GROUP BY expressions (empty set for grand total)
 }
 type DataGetResult struct {
-	IndexRecordIds     map[int]interface{} `json:"indexRecordIds"`     // IDs of relation records, key: relation index
-	IndexRecordEncKeys map[int]string      `json:"indexRecordEncKeys"` // record data keys, encrypted with login´s public key, key: relation index
-	IndexesPermNoDel   []int               `json:"indexesPermNoDel"`   // if getPerm, relation indexes of which records may not be deleted
-	IndexesPermNoSet   []int               `json:"indexesPermNoSet"`   // if getPerm, relation indexes of which records may not be updated
-	Values             []interface{}       `json:"values"`             // expression values, same order as requested expressions
+	IndexRecordIds      map[int]interface{} `json:"indexRecordIds"`      // IDs of relation records, key: relation index
+	IndexRecordEncKeys  map[int]string      `json:"indexRecordEncKeys"`  // record data keys, encrypted with login´s public key, key: relation index
+	IndexRecordVersions map[int]interface{} `json:"indexRecordVersions"` // if getVersions, versions of relation records, key: relation index
+	IndexesPermNoDel    []int               `json:"indexesPermNoDel"`    // if getPerm, relation indexes of which records may not be deleted
+	IndexesPermNoSet    []int               `json:"indexesPermNoSet"`    // if getPerm, relation indexes of which records may not be updated
+	Values              []interface{}       `json:"values"`              // expression values, same order as requested expressions
+	Grouping            int32               `json:"grouping"`            // if grouping is used, bit is set for each GROUP BY expression that is aggregated over (bit 0: first GROUP BY expression)
 }
 type DataGetValueFile struct {
 	Id      uuid.UUID `json:"id"`
@@ -132,17 +147,73 @@ type DataSet struct {
 	RecordId    int64              `json:"recordId"`    // record ID to update (0 if new)
 	Attributes  []DataSetAttribute `json:"attributes"`  // attribute values to set
 	EncKeysSet  []DataSetEncKeys   `json:"encKeysSet"`  // data encryption keys to store, encrypted with login´s public key
+	Version     pgtype.Int8        `json:"version"`     // record version when loaded, update fails if record changed since (optimistic locking), NULL = no check
 }
 type DataSetResult struct {
 	IndexRecordIds map[int]int64 `json:"indexRecordIds"` // IDs of relation records, key: relation index
 }
 
+// data LOCK request
+type DataLock struct {
+	RelationId uuid.UUID `json:"relationId"`
+	RecordId   int64     `json:"recordId"`
+	LoginId    int64     `json:"loginId"`   // login holding the lock
+	LoginName  string    `json:"loginName"` // name of login holding the lock
+	DateLock   int64     `json:"dateLock"`
+	Own        bool      `json:"own"` // lock is held by requesting client
+}
+
 // data LOG request
 type DataLog struct {
 	Id         uuid.UUID          `json:"id"`
 	RelationId uuid.UUID          `json:"relationId"`
 	RecordId   int64              `json:"recordId"`
+	Action     string             `json:"action"` // insert, update, delete, undelete
 	DateChange int64              `json:"dateChange"`
 	LoginName  string             `json:"loginName"`
 	Attributes []DataSetAttribute `json:"attributes"`
 }
+type DataLogState struct {
+	RelationId uuid.UUID          `json:"relationId"`
+	RecordId   int64              `json:"recordId"`
+	Date       int64              `json:"date"`       // point in time, state was reconstructed for
+	Attributes []DataSetAttribute `json:"attributes"` // last logged value of each attribute at given point in time
+}
+type DataTrashRecord struct {
+	RecordId    int64              `json:"recordId"`
+	DateDeleted int64              `json:"dateDeleted"`
+	Attributes  []DataSetAttribute `json:"attributes"` // values of readable attributes at time of deletion
+}
+
+// data EXPLAIN request
+type DataExplain struct {
+	Query         string               `json:"query"`         // executed SQL query
+	Plan          DataExplainNode      `json:"plan"`          // root node of executed query plan
+	PlanningTime  float64              `json:"planningTime"`  // in ms
+	ExecutionTime float64              `json:"executionTime"` // in ms
+	Warnings      []DataExplainWarning `json:"warnings"`
+}
+type DataExplainNode struct {
+	NodeType     string            `json:"nodeType"`     // Seq Scan, Index Scan, Hash Join, ...
+	Schema       string            `json:"schema"`       // scanned relation schema (if scan node)
+	Relation     string            `json:"relation"`     // scanned relation name (if scan node)
+	Alias        string            `json:"alias"`        // relation alias within query
+	Index        string            `json:"index"`        // used index name (if index scan)
+	Condition    string            `json:"condition"`    // join/index condition
+	Filter       string            `json:"filter"`       // filter applied to scanned/joined rows
+	CostTotal    float64           `json:"costTotal"`    // planner estimated cost
+	RowsPlanned  float64           `json:"rowsPlanned"`  // planner estimated rows
+	RowsActual   float64           `json:"rowsActual"`   // actual rows per loop
+	RowsFiltered float64           `json:"rowsFiltered"` // actual rows removed by filter per loop
+	Loops        int64             `json:"loops"`        // number of times the node was executed
+	TimeTotal    float64           `json:"timeTotal"`    // actual time per loop, in ms
+	BlocksHit    int64             `json:"blocksHit"`    // shared buffer blocks found in cache
+	BlocksRead   int64             `json:"blocksRead"`   // shared buffer blocks read from disk
+	Plans        []DataExplainNode `json:"plans"`        // child nodes
+}
+type DataExplainWarning struct {
+	RelationId   uuid.UUID   `json:"relationId"`
+	RowsEstimate int64       `json:"rowsEstimate"` // estimated number of relation records
+	Filter       string      `json:"filter"`       // filter applied during sequential scan
+	AttributeIds []uuid.UUID `json:"attributeIds"` // filtered attributes not covered by an index, candidates for new PG index
+}
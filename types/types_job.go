@@ -0,0 +1,36 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Job struct {
+	Id           int64           `json:"id"`
+	QueueName    string          `json:"queueName"`
+	PgFunctionId uuid.UUID       `json:"pgFunctionId"`
+	Args         json.RawMessage `json:"args"`  // JSON array of function arguments
+	State        string          `json:"state"` // waiting, running, done, failed
+	AttemptCount int             `json:"attemptCount"`
+	DateAdded    int64           `json:"dateAdded"`
+	DateNext     int64           `json:"dateNext"` // earliest date of next attempt
+	DateStart    pgtype.Int8     `json:"dateStart"`
+	DateEnd      pgtype.Int8     `json:"dateEnd"`
+	NodeName     pgtype.Text     `json:"nodeName"` // node of last attempt
+	LastError    pgtype.Text     `json:"lastError"`
+}
+
+type JobQueue struct {
+	Name           string `json:"name"`
+	ConcurrencyMax int    `json:"concurrencyMax"` // jobs executed in parallel, across all cluster nodes
+	AttemptMax     int    `json:"attemptMax"`     // attempts before job is given up
+	BackoffBase    int    `json:"backoffBase"`    // seconds to wait after first failed attempt, doubled with each further failure
+	Timeout        int    `json:"timeout"`        // seconds after which job execution is aborted
+
+	// job counts by state
+	CountWaiting int64 `json:"countWaiting"`
+	CountRunning int64 `json:"countRunning"`
+	CountFailed  int64 `json:"countFailed"`
+}
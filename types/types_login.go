@@ -20,6 +20,22 @@ type LoginAccess struct {
 	SearchBar   map[uuid.UUID]Access `json:"searchBar"`   // effective access to specific search bars
 	Widget      map[uuid.UUID]Access `json:"widget"`      // effective access to specific widgets
 }
+type LoginApiKey struct {
+	Id         uuid.UUID   `json:"id"`
+	LoginId    int64       `json:"loginId"`
+	LoginName  string      `json:"loginName"` // name of login the key acts as (read only)
+	Name       string      `json:"name"`      // to identify key user/integration
+	Prefix     string      `json:"prefix"`    // first characters of key, to identify key without revealing it
+	Active     bool        `json:"active"`
+	ReadOnly   bool        `json:"readOnly"`  // key can only be used for GET calls
+	ApiIds     []uuid.UUID `json:"apiIds"`    // APIs that key can be used for
+	ModuleIds  []uuid.UUID `json:"moduleIds"` // modules whose APIs key can be used for
+	IpAllow    []string    `json:"ipAllow"`   // IP addresses or networks (CIDR) that key can be used from, any if empty
+	DateCreate int64       `json:"dateCreate"`
+	DateExpiry pgtype.Int8 `json:"dateExpiry"` // key cannot be used after expiry date, never expires if empty
+	DateUsed   pgtype.Int8 `json:"dateUsed"`   // last time key was used (read only)
+	IpUsed     pgtype.Text `json:"ipUsed"`     // IP address key was last used from (read only)
+}
 type LoginAuthResult struct {
 	// auth types: user, token, fixed token, openId
 	Admin bool   `json:"admin"` // login has instance admin permissions
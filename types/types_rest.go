@@ -0,0 +1,27 @@
+package types
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// spooled REST call, headers are not included as they can contain credentials
+type RestSpool struct {
+	Id                   uuid.UUID   `json:"id"`
+	PgFunctionIdCallback pgtype.UUID `json:"pgFunctionIdCallback"`
+	WebhookId            pgtype.UUID `json:"webhookId"`
+	Method               string      `json:"method"`
+	Url                  string      `json:"url"`
+	Body                 pgtype.Text `json:"body"`
+	SkipVerify           bool        `json:"skipVerify"`
+	AttemptCount         int         `json:"attemptCount"`
+	AttemptMax           int         `json:"attemptMax"`    // attempts before call is given up
+	BackoffBase          int         `json:"backoffBase"`   // seconds to wait after first failed attempt, doubled with each further failure
+	StatusSuccess        string      `json:"statusSuccess"` // HTTP status codes/ranges considered successful, e. g. '200-299,304'
+	DateAdded            int64       `json:"dateAdded"`
+	DateAttempt          pgtype.Int8 `json:"dateAttempt"` // date of last attempt
+	DateNext             int64       `json:"dateNext"`    // earliest date of next attempt
+	LastError            pgtype.Text `json:"lastError"`
+	LastStatus           pgtype.Int4 `json:"lastStatus"`
+	LastResponse         pgtype.Text `json:"lastResponse"` // response body of last failed attempt (truncated)
+}
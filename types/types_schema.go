@@ -43,6 +43,7 @@ type Module struct {
 	ClientEvents          []ClientEvent     `json:"clientEvents"`
 	SearchBars            []SearchBar       `json:"searchBars"`
 	Variables             []Variable        `json:"variables"`
+	Webhooks              []Webhook         `json:"webhooks"`
 	Widgets               []Widget          `json:"widgets"`
 	ArticleIdsHelp        []uuid.UUID       `json:"articleIdsHelp"` // IDs of articles for primary module help, in order
 	Captions              CaptionMap        `json:"captions"`
@@ -85,6 +86,9 @@ type Relation struct {
 	Encryption     bool             `json:"encryption"`     // relation supports encrypted attribute values
 	RetentionCount pgtype.Int4      `json:"retentionCount"` // minimum number of retained change events
 	RetentionDays  pgtype.Int4      `json:"retentionDays"`  // minimum age of retained change events
+	SoftDelete     bool             `json:"softDelete"`     // deleted records are kept in trash bin until restored or purged
+	SoftDeleteDays pgtype.Int4      `json:"softDeleteDays"` // days after which soft deleted records are purged, NULL = keep
+	RecordLock     pgtype.Text      `json:"recordLock"`     // concurrent editing of records: optimistic (fail on changed record), pessimistic (lock on open), NULL = none
 	Attributes     []Attribute      `json:"attributes"`     // read only, all relation attributes
 	Indexes        []PgIndex        `json:"indexes"`        // read only, all relation indexes
 	Policies       []RelationPolicy `json:"policies"`       // read only, all relation policies
@@ -129,6 +133,7 @@ type Attribute struct {
 	Nullable       bool        `json:"nullable"`       // value is nullable
 	Encrypted      bool        `json:"encrypted"`      // value is encrypted (end-to-end for logins)
 	Def            string      `json:"def"`            // default value
+	Generated      pgtype.Text `json:"generated"`      // expression of generated (computed) attribute, refers to attributes of the same relation via (ATTRIBUTE_ID)
 	OnUpdate       string      `json:"onUpdate"`       // relationship attribute, action on 'UPDATE'
 	OnDelete       string      `json:"onDelete"`       // relationship attribute, action on 'DELETE'
 	Captions       CaptionMap  `json:"captions"`
@@ -493,11 +498,18 @@ type Column struct {
 	Index       int         `json:"index"`      // attribute index
 	GroupBy     bool        `json:"groupBy"`    // group by column attribute value?
 	Aggregator  pgtype.Text `json:"aggregator"` // aggregator (SUM, COUNT, etc.)
+	DateBucket  pgtype.Text `json:"dateBucket"` // truncate date/datetime values to start of period (day, week, month, etc.)
 	Distincted  bool        `json:"distincted"` // attribute values are distinct?
+	Window      pgtype.Text `json:"window"`     // window function (rank, runningSum, lag, etc.)
 	SubQuery    bool        `json:"subQuery"`   // column uses sub query?
 	Query       Query       `json:"query"`      // sub query
 	Captions    CaptionMap  `json:"captions"`   // column titles
 
+	// window function options
+	WindowOffset    int                 `json:"windowOffset"`    // row offset for lag/lead (1 if not set)
+	WindowOrders    []ColumnWindowOrder `json:"windowOrders"`    // window order, query orders are used if empty (ranking: column value)
+	WindowPartition []int               `json:"windowPartition"` // positions of columns to partition window by
+
 	// presentation
 	Basis    int         `json:"basis"`    // size basis (usually width)
 	Batch    pgtype.Int4 `json:"batch"`    // index of column batch (multiple columns as one)
@@ -512,6 +524,10 @@ type Column struct {
 	Clipboard     bool `json:"clipboard"`
 	Wrap          bool `json:"wrap"`
 }
+type ColumnWindowOrder struct {
+	ColumnPos int  `json:"columnPos"` // position of column to order window by
+	Ascending bool `json:"ascending"`
+}
 type Role struct {
 	Id                 uuid.UUID            `json:"id"`
 	ModuleId           uuid.UUID            `json:"moduleId"`
@@ -545,13 +561,21 @@ type PgFunction struct {
 	Captions       CaptionMap           `json:"captions"`
 }
 type PgFunctionSchedule struct {
-	Id            uuid.UUID `json:"id"`
-	AtSecond      int       `json:"atSecond"`
-	AtMinute      int       `json:"atMinute"`
-	AtHour        int       `json:"atHour"`
-	AtDay         int       `json:"atDay"`
-	IntervalType  string    `json:"intervalType"`
-	IntervalValue int       `json:"intervalValue"`
+	Id            uuid.UUID                    `json:"id"`
+	AtSecond      int                          `json:"atSecond"`
+	AtMinute      int                          `json:"atMinute"`
+	AtHour        int                          `json:"atHour"`
+	AtDay         int                          `json:"atDay"`
+	IntervalType  string                       `json:"intervalType"` // seconds, minutes, hours, days, weeks, months, years, once, cron
+	IntervalValue int                          `json:"intervalValue"`
+	Cron          pgtype.Text                  `json:"cron"`     // cron expression, used by interval type 'cron'
+	TimeZone      pgtype.Text                  `json:"timeZone"` // IANA time zone to evaluate schedule in, server local time if NULL
+	Blackouts     []PgFunctionScheduleBlackout `json:"blackouts"`
+}
+type PgFunctionScheduleBlackout struct {
+	DateFrom int64 `json:"dateFrom"` // first day of blackout window (unix, date at UTC midnight)
+	DateTo   int64 `json:"dateTo"`   // last day of blackout window (unix, date at UTC midnight)
+	Yearly   bool  `json:"yearly"`   // window repeats every year on the same days (holidays)
 }
 type PgTrigger struct {
 	Id            uuid.UUID `json:"id"`
@@ -636,6 +660,19 @@ type Variable struct {
 	ContentUse string      `json:"contentUse"` // for display as field input, no other purpose
 	Def        pgtype.Text `json:"def"`        // default value
 }
+type Webhook struct {
+	Id         uuid.UUID   `json:"id"`
+	ModuleId   uuid.UUID   `json:"moduleId"`
+	RelationId uuid.UUID   `json:"relationId"` // relation whose record changes trigger the webhook
+	ApiId      pgtype.UUID `json:"apiId"`      // API defining the payload (columns, filters), only record ID is sent if not set
+	Name       string      `json:"name"`
+	Comment    pgtype.Text `json:"comment"` // author comment
+	Url        string      `json:"url"`     // target URL, called with POST
+	OnDelete   bool        `json:"onDelete"`
+	OnInsert   bool        `json:"onInsert"`
+	OnUpdate   bool        `json:"onUpdate"`
+	SkipVerify bool        `json:"skipVerify"` // ignore TLS certificate errors of target
+}
 type Widget struct {
 	Id         uuid.UUID          `json:"id"`
 	ModuleId   uuid.UUID          `json:"moduleId"`
@@ -29,6 +29,10 @@ type Query struct {
 	Orders     []QueryOrder  `json:"orders"`     // default query sort
 	Lookups    []QueryLookup `json:"lookups"`    // import lookups via PG indexes
 	Choices    []QueryChoice `json:"choices"`    // named filter sets, selectable by users
+
+	// grouping of GROUP BY columns, regular grouping if not set
+	Grouping     pgtype.Text `json:"grouping"`     // rollup, cube, sets
+	GroupingSets [][]int     `json:"groupingSets"` // grouping sets, positions of GROUP BY columns (empty set for grand total)
 }
 
 type QueryJoin struct {
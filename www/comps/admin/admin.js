@@ -42,6 +42,12 @@ let MyAdmin = {
 				<span>{{ capApp.navigationLoginTemplates }}</span>
 			</router-link>
 			
+			<!-- API keys -->
+			<router-link class="entry clickable" tag="div" to="/admin/api-keys">
+				<img src="images/lock.png" />
+				<span>{{ capApp.navigationApiKeys }}</span>
+			</router-link>
+			
 			<!-- roles -->
 			<router-link class="entry clickable" tag="div" to="/admin/roles">
 				<img src="images/admin.png" />
@@ -78,6 +84,24 @@ let MyAdmin = {
 				<span>{{ capApp.navigationMailTraffic }}</span>
 			</router-link>
 			
+			<!-- REST spooler -->
+			<router-link class="entry clickable" tag="div" to="/admin/rest-spooler">
+				<img src="images/api.png" />
+				<span>{{ capApp.navigationRestSpooler }}</span>
+			</router-link>
+			
+			<!-- job queue -->
+			<router-link class="entry clickable" tag="div" to="/admin/jobs">
+				<img src="images/tasks.png" />
+				<span>{{ capApp.navigationJobs }}</span>
+			</router-link>
+			
+			<!-- REST API rate limits -->
+			<router-link class="entry clickable" tag="div" to="/admin/api-rates">
+				<img src="images/api.png" />
+				<span>{{ capApp.navigationApiRates }}</span>
+			</router-link>
+			
 			<!-- backups -->
 			<router-link class="entry clickable" tag="div" to="/admin/backups">
 				<img src="images/backup.png" />
@@ -96,6 +120,12 @@ let MyAdmin = {
 				<span>{{ capApp.navigationLogs }}</span>
 			</router-link>
 			
+			<!-- slow queries -->
+			<router-link class="entry clickable" tag="div" to="/admin/slow-queries">
+				<img src="images/database.png" />
+				<span>{{ capApp.navigationSlowQueries }}</span>
+			</router-link>
+			
 			<!-- scheduler -->
 			<router-link class="entry clickable" tag="div" to="/admin/scheduler">
 				<img src="images/clock.png" />
@@ -190,6 +220,8 @@ let MyAdmin = {
 	},
 	computed:{
 		contentTitle:(s) => {
+			if(s.$route.path.includes('api-keys'))        return s.capApp.navigationApiKeys;
+			if(s.$route.path.includes('api-rates'))       return s.capApp.navigationApiRates;
 			if(s.$route.path.includes('backups'))         return s.capApp.navigationBackups;
 			if(s.$route.path.includes('caption-map'))     return s.capApp.navigationCaptionMap;
 			if(s.$route.path.includes('cluster'))         return s.capApp.navigationCluster;
@@ -197,6 +229,7 @@ let MyAdmin = {
 			if(s.$route.path.includes('custom'))          return s.capApp.navigationCustom;
 			if(s.$route.path.includes('docs'))            return s.capApp.navigationDocs;
 			if(s.$route.path.includes('files'))           return s.capApp.navigationFiles;
+			if(s.$route.path.includes('jobs'))            return s.capApp.navigationJobs;
 			if(s.$route.path.includes('license'))         return s.capApp.navigationActivation;
 			if(s.$route.path.includes('logins'))          return s.capApp.navigationLogins;
 			if(s.$route.path.includes('login-sessions'))  return s.capApp.navigationLoginSessions;
@@ -209,8 +242,10 @@ let MyAdmin = {
 			if(s.$route.path.includes('modules'))         return s.capApp.navigationModules;
 			if(s.$route.path.includes('oauth-clients'))   return s.capApp.navigationOauthClients;
 			if(s.$route.path.includes('repo'))            return s.capApp.navigationRepo;
+			if(s.$route.path.includes('rest-spooler'))    return s.capApp.navigationRestSpooler;
 			if(s.$route.path.includes('roles'))           return s.capApp.navigationRoles;
 			if(s.$route.path.includes('scheduler'))       return s.capApp.navigationScheduler;
+			if(s.$route.path.includes('slow-queries'))    return s.capApp.navigationSlowQueries;
 			if(s.$route.path.includes('system-msg'))      return s.capApp.navigationSystemMsg;
 			return '';
 		},
@@ -0,0 +1,288 @@
+import MyInputDateWrap from '../inputDateWrap.js';
+import MyInputSelect   from '../inputSelect.js';
+import {deepIsEqual}   from '../shared/generic.js';
+import {getCaption}    from '../shared/language.js';
+export {MyAdminApiKey as default};
+
+let MyAdminApiKey = {
+	name:'my-admin-api-key',
+	components:{
+		MyInputDateWrap,
+		MyInputSelect
+	},
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+
+		<div class="contentBox admin-api-key scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/lock.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title.replace('{NAME}',inputs.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						v-if="!isNew"
+						@trigger="reset"
+						:active="hasChanges"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="delete.png"
+						v-if="!isNew"
+						@trigger="delAsk"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="inputs.name" v-focus /></td>
+							<td>{{ capApp.nameHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.login }}*</td>
+							<td>
+								<input disabled v-if="!isNew" :value="inputs.loginName" />
+								<my-input-select
+									v-if="isNew"
+									@dropdown-show="loginDropdown = $event"
+									@request-data="getLogins"
+									@updated-text-input="loginInput = $event"
+									@update:selected="inputs.loginId = $event === null ? 0 : $event"
+									:dropdownShow="loginDropdown"
+									:options="loginList"
+									:placeholder="capGen.threeDots"
+									:selected="inputs.loginId !== 0 ? inputs.loginId : null"
+								/>
+							</td>
+							<td>{{ capApp.loginHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.active }}</td>
+							<td><my-bool v-model="inputs.active" /></td>
+							<td></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.readOnly }}</td>
+							<td><my-bool v-model="inputs.readOnly" /></td>
+							<td>{{ capApp.readOnlyHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.dateExpiry }}</td>
+							<td>
+								<my-input-date-wrap
+									@set-unix-from="inputs.dateExpiry = $event"
+									:isDate="true"
+									:isTime="false"
+									:isValid="true"
+									:unixFrom="inputs.dateExpiry"
+								/>
+							</td>
+							<td>{{ capApp.dateExpiryHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.ipAllow }}</td>
+							<td>
+								<div class="column gap">
+									<my-button image="cancel.png"
+										v-for="(ip,i) in inputs.ipAllow"
+										@trigger="inputs.ipAllow.splice(i,1)"
+										:caption="ip"
+										:naked="true"
+									/>
+									<div class="row gap centered">
+										<input v-model="ipLine" @keyup.enter="ipAdd" placeholder="10.0.0.0/8" />
+										<my-button image="add.png"
+											@trigger="ipAdd"
+											:active="ipLine !== ''"
+										/>
+									</div>
+								</div>
+							</td>
+							<td>{{ capApp.ipAllowHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.scopes }}*</td>
+							<td colspan="2">
+								<div class="column gap">
+									<span>{{ capApp.scopesHint }}</span>
+									<div class="column" v-for="m in modulesWithApis" :key="m.id">
+										<my-button
+											@trigger="toggleModuleId(m.id)"
+											:caption="getCaption('moduleTitle',m.id,m.id,m.captions,m.name)"
+											:image="inputs.moduleIds.includes(m.id) ? 'checkbox1.png' : 'checkbox0.png'"
+											:naked="true"
+										/>
+										<my-button
+											v-for="a in m.apis"
+											@trigger="toggleApiId(a.id)"
+											:active="!inputs.moduleIds.includes(m.id)"
+											:caption="a.name + ' (v' + a.version + ')'"
+											:image="inputs.apiIds.includes(a.id) || inputs.moduleIds.includes(m.id) ? 'checkbox1.png' : 'checkbox0.png'"
+											:naked="true"
+											:style="{ marginLeft:'30px' }"
+										/>
+									</div>
+								</div>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		apiKey:{ type:Object, required:false, default:null } // null if new
+	},
+	emits:['close'],
+	data() {
+		return {
+			inputs:{},
+			ipLine:'',
+			loginDropdown:false,
+			loginInput:'',
+			loginList:[]
+		};
+	},
+	computed:{
+		canSave:(s) =>
+			s.hasChanges &&
+			s.inputs.name    !== '' &&
+			s.inputs.loginId !== 0 &&
+			(s.inputs.apiIds.length !== 0 || s.inputs.moduleIds.length !== 0),
+		inputsOrg:(s) => s.isNew ? {
+			id:null,
+			loginId:0,
+			loginName:'',
+			name:'',
+			active:true,
+			readOnly:true,
+			apiIds:[],
+			moduleIds:[],
+			ipAllow:[],
+			dateExpiry:null
+		} : s.apiKey,
+		modulesWithApis:(s) => s.modules.filter(v => v.apis.length !== 0),
+
+		// simple states
+		hasChanges:(s) => !s.deepIsEqual(s.inputsOrg,s.inputs),
+		isNew:     (s) => s.apiKey === null,
+
+		// stores
+		modules:(s) => s.$store.getters['schema/modules'],
+		capApp: (s) => s.$store.getters.captions.admin.apiKey,
+		capGen: (s) => s.$store.getters.captions.generic
+	},
+	created() {
+		this.reset();
+	},
+	mounted() {
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.set);
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// external
+		deepIsEqual,
+		getCaption,
+
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		ipAdd() {
+			if(this.ipLine === '') return;
+			this.inputs.ipAllow.push(this.ipLine);
+			this.ipLine = '';
+		},
+		reset() {
+			this.inputs = JSON.parse(JSON.stringify(this.inputsOrg));
+		},
+		toggleApiId(id) {
+			const pos = this.inputs.apiIds.indexOf(id);
+			if(pos === -1) this.inputs.apiIds.push(id);
+			else           this.inputs.apiIds.splice(pos,1);
+		},
+		toggleModuleId(id) {
+			const pos = this.inputs.moduleIds.indexOf(id);
+			if(pos === -1) this.inputs.moduleIds.push(id);
+			else           this.inputs.moduleIds.splice(pos,1);
+		},
+
+		// backend calls
+		delAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.delete,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:this.del,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		del() {
+			ws.send('loginApiKey','del',{id:this.inputs.id},true).then(
+				this.close,
+				this.$root.genericError
+			);
+		},
+		getLogins() {
+			ws.send('login','get',{
+				byString:this.loginInput,
+				limit:10,
+				orderBy:'name',
+				orderAsc:true
+			},true).then(
+				res => this.loginList = res.payload.logins.map(v => { return { id:v.id, name:v.name }; }),
+				this.$root.genericError
+			);
+		},
+		set() {
+			if(!this.canSave) return;
+
+			ws.send('loginApiKey','set',this.inputs,true).then(
+				res => {
+					// new key is only shown once
+					if(res.payload.key !== '') {
+						this.$store.commit('dialog',{
+							captionBody:res.payload.key,
+							captionTop:this.capApp.dialog.created,
+							image:'lock.png',
+							textDisplay:'textarea',
+							width:800
+						});
+					}
+					this.close();
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
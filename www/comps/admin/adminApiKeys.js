@@ -0,0 +1,99 @@
+import MyAdminApiKey   from './adminApiKey.js';
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminApiKeys as default};
+
+let MyAdminApiKeys = {
+	name:'my-admin-api-keys',
+	components:{ MyAdminApiKey },
+	template:`<div class="admin-api-keys contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/lock.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="add.png"
+					@trigger="idOpen = null;showDialog = true"
+					:caption="capGen.button.new"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+		</div>
+
+		<div class="content" :class="{ 'no-padding':keys.length !== 0 }">
+			<span v-if="keys.length === 0"><i>{{ capApp.nothingThere }}</i></span>
+
+			<table class="generic-table bright shade" v-if="keys.length !== 0">
+				<thead>
+					<tr>
+						<th>{{ capGen.name }}</th>
+						<th>{{ capApp.login }}</th>
+						<th>{{ capApp.prefix }}</th>
+						<th>{{ capGen.active }}</th>
+						<th>{{ capApp.readOnly }}</th>
+						<th>{{ capApp.scopes }}</th>
+						<th>{{ capApp.dateExpiry }}</th>
+						<th>{{ capApp.dateUsed }}</th>
+						<th>{{ capApp.ipUsed }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr class="clickable" v-for="k in keys" @click="idOpen = k.id;showDialog = true" :key="k.id">
+						<td>{{ k.name }}</td>
+						<td>{{ k.loginName }}</td>
+						<td>{{ k.prefix + '...' }}</td>
+						<td><my-bool :modelValue="k.active" :readonly="true" /></td>
+						<td><my-bool :modelValue="k.readOnly" :readonly="true" /></td>
+						<td>{{ k.apiIds.length + k.moduleIds.length }}</td>
+						<td>{{ k.dateExpiry !== null ? getUnixFormat(k.dateExpiry,settings.dateFormat) : '-' }}</td>
+						<td>{{ k.dateUsed !== null ? getUnixFormat(k.dateUsed,settings.dateFormat+' H:i') : '-' }}</td>
+						<td>{{ k.ipUsed !== null ? k.ipUsed : '-' }}</td>
+					</tr>
+				</tbody>
+			</table>
+
+			<my-admin-api-key
+				v-if="showDialog"
+				@close="showDialog = false;get()"
+				:apiKey="idOpen !== null ? keys.find(v => v.id === idOpen) : null"
+			/>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			idOpen:null,
+			keys:[],
+			showDialog:false
+		};
+	},
+	computed:{
+		// stores
+		capApp:  (s) => s.$store.getters.captions.admin.apiKey,
+		capGen:  (s) => s.$store.getters.captions.generic,
+		settings:(s) => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('pageTitle',this.menuTitle);
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// backend calls
+		get() {
+			ws.send('loginApiKey','get',{loginId:0},true).then(
+				res => this.keys = res.payload,
+				this.$root.genericError
+			);
+		}
+	}
+};
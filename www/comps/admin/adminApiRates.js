@@ -0,0 +1,222 @@
+import MyInputLogin    from '../inputLogin.js';
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminApiRates as default};
+
+let MyAdminApiRates = {
+	name:'my-admin-api-rates',
+	components:{MyInputLogin},
+	template:`<div class="admin-api-rates contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/api.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-button image="delete.png"
+					@trigger="resetAsk"
+					:active="buckets.length !== 0"
+					:cancel="true"
+					:caption="capGen.button.reset"
+				/>
+			</div>
+		</div>
+
+		<div class="content" :class="{ 'no-padding':buckets.length !== 0 }">
+			<span v-if="buckets.length === 0"><i>{{ capApp.nothingThere }}</i></span>
+
+			<table class="generic-table bright shade" v-if="buckets.length !== 0">
+				<thead>
+					<tr>
+						<th>{{ capApp.kind }}</th>
+						<th>{{ capGen.name }}</th>
+						<th>{{ capApp.available }}</th>
+						<th>{{ capApp.countAllowed }}</th>
+						<th>{{ capApp.countDenied }}</th>
+						<th>{{ capApp.dateStart }}</th>
+						<th>{{ capApp.dateUpdate }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="b in buckets" :key="b.kind + b.entity">
+						<td>{{ capApp.option.kind[b.kind] }}</td>
+						<td :title="b.entity">{{ b.entityName !== '' ? b.entityName : b.entity }}</td>
+						<td>{{ displayAvailable(b) }}</td>
+						<td>{{ b.countAllowed }}</td>
+						<td :class="{ error:b.countDenied !== 0 }">{{ b.countDenied }}</td>
+						<td>{{ getUnixFormat(Math.floor(b.dateStart / 1000),settings.dateFormat+' H:i') }}</td>
+						<td>{{ getUnixFormat(Math.floor(b.dateUpdate / 1000),settings.dateFormat+' H:i:s') }}</td>
+					</tr>
+				</tbody>
+			</table>
+
+			<!-- custom limits -->
+			<div class="admin-api-rates-custom">
+				<h2>{{ capApp.custom }}</h2>
+				<p>{{ capApp.customHint }}</p>
+				<table class="generic-table bright shade" v-if="customRows.length !== 0">
+					<thead>
+						<tr>
+							<th>{{ capApp.kind }}</th>
+							<th>{{ capGen.name }}</th>
+							<th>{{ capApp.customLimit }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="r in customRows" :key="r.kind + r.entity">
+							<td>{{ capApp.option.kind[r.kind] }}</td>
+							<td v-if="r.kind === 'api'" :title="r.entity">{{ displayApi(r.entity) }}</td>
+							<td v-if="r.kind === 'login'">
+								<my-input-login :modelValue="parseInt(r.entity)" :readonly="true" />
+							</td>
+							<td><input class="short" v-model.number="customInput[r.kind][r.entity]" /></td>
+							<td>
+								<my-button image="delete.png"
+									@trigger="customDel(r.kind,r.entity)"
+									:cancel="true"
+									:captionTitle="capGen.button.delete"
+								/>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+				<div class="row gap centered">
+					<select v-model="customApiId" @change="customAdd('api',customApiId)">
+						<option :value="null">{{ capApp.customAddApi }}</option>
+						<option v-for="a in apis" :value="a.id">{{ a.name + ' (v' + a.version + ')' }}</option>
+					</select>
+					<my-input-login
+						@dropdown-show="showInputDropdown = $event"
+						@update:modelValue="customAdd('login',$event)"
+						:clearInput="true"
+						:dropdownShow="showInputDropdown"
+						:modelValue="null"
+						:placeholder="capApp.customAddLogin"
+					/>
+					<my-button image="save.png"
+						@trigger="setConfig"
+						:active="customChanged"
+						:caption="capGen.button.save"
+					/>
+				</div>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			buckets:[],
+			customApiId:null,
+			customInput:{ api:{}, login:{} }, // custom limits by kind and entity ID
+			dateLoaded:0,
+			showInputDropdown:false
+		};
+	},
+	computed:{
+		apis:(s) => Object.values(s.apiIdMap).filter(v => s.customInput.api[v.id] === undefined),
+		customChanged:(s) => JSON.stringify(s.customInput) !== JSON.stringify(s.customStored),
+		customRows:(s) => {
+			let out = [];
+			for(const kind of ['api','login']) {
+				for(const entity in s.customInput[kind]) {
+					out.push({kind:kind,entity:entity});
+				}
+			}
+			return out;
+		},
+		customStored:(s) => {
+			const v = JSON.parse(s.config.apiRateLimitsCustom);
+			return { api:v.api === undefined ? {} : v.api, login:v.login === undefined ? {} : v.login };
+		},
+
+		// stores
+		capApp:  (s) => s.$store.getters.captions.admin.apiRate,
+		apiIdMap:(s) => s.$store.getters['schema/apiIdMap'],
+		capGen:  (s) => s.$store.getters.captions.generic,
+		config:  (s) => s.$store.getters.config,
+		settings:(s) => s.$store.getters.settings
+	},
+	mounted() {
+		this.customInput = JSON.parse(JSON.stringify(this.customStored));
+		this.get();
+		this.$store.commit('pageTitle',this.menuTitle);
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// actions
+		customAdd(kind,entity) {
+			if(entity === null)
+				return;
+
+			this.customInput[kind][String(entity)] = 0;
+			this.customApiId = null;
+		},
+		customDel(kind,entity) {
+			delete this.customInput[kind][entity];
+		},
+
+		// presentation
+		displayApi(id) {
+			return this.apiIdMap[id] === undefined ? id
+				: `${this.apiIdMap[id].name} (v${this.apiIdMap[id].version})`;
+		},
+		displayAvailable(b) {
+			if(b.limit === 0)
+				return this.capApp.unlimited;
+
+			// buckets refill continuously, project available requests to time of loading
+			const refilled = (this.dateLoaded - b.dateUpdate) / 1000 * b.limit / 60;
+			return `${Math.floor(Math.max(0,Math.min(b.limit,b.tokens + refilled)))} / ${b.limit}`;
+		},
+
+		// backend calls
+		get() {
+			ws.send('apiRate','get',{},true).then(
+				res => {
+					this.buckets    = res.payload;
+					this.dateLoaded = Date.now();
+				},
+				this.$root.genericError
+			);
+		},
+		resetAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.reset,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.reset,
+					exec:this.reset,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		reset() {
+			ws.send('apiRate','reset',{kind:''},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		setConfig() {
+			let config = JSON.parse(JSON.stringify(this.config));
+			config.apiRateLimitsCustom = JSON.stringify(this.customInput);
+
+			ws.send('config','set',config,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		}
+	}
+};
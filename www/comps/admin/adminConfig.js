@@ -77,6 +77,17 @@ let MyAdminConfig = {
 								</div>
 							</td>
 						</tr>
+						<tr>
+							<td>{{ capApp.webhookSecret }}</td>
+							<td>
+								<div class="row gap centered">
+									<input v-model="configInput.webhookSecret" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.webhookSecretDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
 						<tr><td colspan="2"><hr /></td></tr>
 						<tr><td colspan="2"><b>{{ capGen.systemModes }}</b></td></tr>
 						<tr>
@@ -285,6 +296,85 @@ let MyAdminConfig = {
 				</table>
 			</div>
 			
+			<!-- REST APIs -->
+			<div class="contentPart">
+				<div class="contentPartHeader">
+					<img class="icon" src="images/api.png" />
+					<h1>{{ capApp.titleApi }}</h1>
+				</div>
+				
+				<table class="default-inputs">
+					<tbody>
+						<tr>
+							<td>{{ capApp.apiBulkRowsMax }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiBulkRowsMax" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiBulkRowsMaxDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.apiIdempotencyHours }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiIdempotencyHours" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiIdempotencyHoursDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.apiRateLimitLogin }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiRateLimitLogin" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiRateLimitLoginDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.apiRateLimitApi }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiRateLimitApi" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiRateLimitApiDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.apiRateLimitNode }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiRateLimitNode" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiRateLimitNodeDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.apiRateKeepDays }}</td>
+							<td>
+								<div class="row gap centered">
+									<input class="short" v-model="configInput.apiRateKeepDays" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.apiRateKeepDaysDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+			
 			<!-- ICS -->
 			<div class="contentPart">
 				<div class="contentPartHeader">
@@ -0,0 +1,393 @@
+import {getCaption}    from '../shared/language.js';
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminJobs as default};
+
+let MyAdminJobs = {
+	name:'my-admin-jobs',
+	template:`<div class="admin-jobs contentBox grow">
+
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/tasks.png" />
+				<h1>{{ menuTitle + ' (' + total + ')' }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-button image="autoRenew.png"
+					v-if="!noJobs"
+					@trigger="reset"
+					:active="jobIdsSelected.length !== 0"
+					:caption="capApp.button.retry"
+				/>
+				<my-button image="delete.png"
+					v-if="!noJobs"
+					@trigger="del"
+					:active="jobIdsSelected.length !== 0"
+					:cancel="true"
+					:caption="capGen.button.delete"
+				/>
+			</div>
+			<div class="area default-inputs" v-if="!noJobs">
+				<my-button image="triangleLeft.png"
+					@trigger="offsetSet(false)"
+					@trigger-shift="startAtPageFirst"
+					:active="offset-limit >= 0"
+					:naked="true"
+				/>
+
+				<span>{{ String((offset / limit) + 1) + ' / ' + pages  }}</span>
+
+				<my-button image="triangleRight.png"
+					@trigger="offsetSet(true)"
+					@trigger-shift="startAtPageLast"
+					:active="offset+limit < total"
+					:naked="true"
+				/>
+
+				<select v-model.number="limit" @change="startAtPageFirst">
+					<option>10</option>
+					<option>25</option>
+					<option>50</option>
+					<option>100</option>
+					<option>500</option>
+				</select>
+			</div>
+			<div class="area default-inputs">
+				<select v-model="queueName" @change="startAtPageFirst">
+					<option value="">{{ capApp.queueAll }}</option>
+					<option v-for="q in queues" :value="q.name">{{ q.name }}</option>
+				</select>
+				<select v-model="state" @change="startAtPageFirst">
+					<option value="">{{ capApp.stateAll }}</option>
+					<option v-for="s in states" :value="s">{{ capApp.states[s] }}</option>
+				</select>
+				<my-button
+					@trigger="showQueues = !showQueues"
+					:caption="capApp.queues"
+					:image="showQueues ? 'visible1.png' : 'visible0.png'"
+				/>
+			</div>
+		</div>
+
+		<div class="content no-padding">
+
+			<!-- queues -->
+			<div class="content default-inputs" v-if="showQueues">
+				<table class="generic-table bright shade">
+					<thead>
+						<tr>
+							<th>{{ capGen.name }}</th>
+							<th :title="capApp.concurrencyMaxHint">{{ capApp.concurrencyMax }}</th>
+							<th>{{ capApp.attemptMax }}</th>
+							<th :title="capApp.backoffBaseHint">{{ capApp.backoffBase }}</th>
+							<th :title="capApp.timeoutHint">{{ capApp.timeout }}</th>
+							<th>{{ capApp.states.waiting }}</th>
+							<th>{{ capApp.states.running }}</th>
+							<th>{{ capApp.states.failed }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="(q,i) in queuesInput">
+							<td>
+								<input class="short" v-model="q.name" :disabled="i < queues.length" />
+							</td>
+							<td><input class="short" v-model.number="q.concurrencyMax" /></td>
+							<td><input class="short" v-model.number="q.attemptMax" /></td>
+							<td><input class="short" v-model.number="q.backoffBase" /></td>
+							<td><input class="short" v-model.number="q.timeout" /></td>
+							<td>{{ q.countWaiting }}</td>
+							<td>{{ q.countRunning }}</td>
+							<td>{{ q.countFailed }}</td>
+							<td>
+								<div class="row gap">
+									<my-button image="save.png"
+										@trigger="queueSet(q)"
+										:active="i >= queues.length || JSON.stringify(q) !== JSON.stringify(queues[i])"
+										:captionTitle="capGen.button.save"
+									/>
+									<my-button image="delete.png"
+										@trigger="i < queues.length ? queueDel(q.name) : queuesInput.splice(i,1)"
+										:active="q.name !== 'default'"
+										:cancel="true"
+										:captionTitle="capGen.button.delete"
+									/>
+								</div>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+				<div class="row gap centered">
+					<my-button image="add.png"
+						@trigger="queueAdd"
+						:caption="capApp.button.queueAdd"
+					/>
+				</div>
+				<br />
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capApp.keepDays }}</td>
+							<td><input class="short" v-model="configInput.jobsKeepDays" /></td>
+							<td>{{ capApp.keepDaysHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+				<my-button image="save.png"
+					@trigger="setConfig"
+					:active="config.jobsKeepDays !== configInput.jobsKeepDays"
+					:caption="capGen.button.save"
+				/>
+			</div>
+
+			<!-- jobs -->
+			<div class="content">
+				<span v-if="noJobs"><i>{{ capApp.noJobs }}</i></span>
+
+				<table class="generic-table bright shade" v-if="!noJobs">
+					<thead>
+						<tr>
+							<th>
+								<my-button
+									@trigger="toggleJobAll"
+									:image="jobIdsSelected.length === jobs.length ? 'checkbox1.png' : 'checkbox0.png'"
+									:naked="true"
+								/>
+							</th>
+							<th>{{ capGen.id }}</th>
+							<th>{{ capApp.queue }}</th>
+							<th>{{ capApp.pgFunction }}</th>
+							<th>{{ capApp.args }}</th>
+							<th>{{ capApp.state }}</th>
+							<th>{{ capApp.attempts }}</th>
+							<th>{{ capGen.date }}</th>
+							<th>{{ capApp.dateNext }}</th>
+							<th>{{ capApp.duration }}</th>
+							<th>{{ capApp.node }}</th>
+							<th>{{ capApp.lastError }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="j in jobs">
+							<td class="minimum">
+								<my-button
+									@trigger="toggleJobId(j.id)"
+									:active="j.state !== 'running'"
+									:image="jobIdsSelected.includes(j.id) ? 'checkbox1.png' : 'checkbox0.png'"
+									:naked="true"
+								/>
+							</td>
+							<td>{{ j.id }}</td>
+							<td>{{ j.queueName }}</td>
+							<td>{{ displayFunctionName(j.pgFunctionId) }}</td>
+							<td class="minimum">
+								<my-button image="search.png"
+									@trigger="showText(capApp.args,JSON.stringify(j.args,null,2))"
+									:active="j.args.length !== 0"
+								/>
+							</td>
+							<td>
+								<div class="row gap centered">
+									<img class="icon" :src="displayStateImage(j.state)" />
+									<span>{{ capApp.states[j.state] }}</span>
+								</div>
+							</td>
+							<td>{{ j.attemptCount + '/' + displayAttemptMax(j.queueName) }}</td>
+							<td>{{ getUnixFormat(j.dateAdded,settings.dateFormat+' H:i:S') }}</td>
+							<td>{{ j.state === 'waiting' ? getUnixFormat(j.dateNext,settings.dateFormat+' H:i:S') : '-' }}</td>
+							<td>{{ j.dateStart !== null && j.dateEnd !== null ? (j.dateEnd - j.dateStart) + ' s' : '-' }}</td>
+							<td>{{ j.nodeName !== null ? j.nodeName : '-' }}</td>
+							<td>{{ j.lastError !== null ? j.lastError : '-' }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			configInput:{},
+			limit:50,
+			offset:0,
+			queueName:'',
+			showQueues:false,
+			state:'',
+			states:['waiting','running','done','failed'],
+
+			// jobs & queues
+			jobs:[],
+			jobIdsSelected:[],
+			queues:[],
+			queuesInput:[],
+			total:0
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.configInput = JSON.parse(JSON.stringify(this.config));
+		this.get();
+	},
+	computed:{
+		// simple
+		noJobs:(s) => s.total === 0,
+		pages: (s) => Math.ceil(s.total / s.limit),
+
+		// stores
+		moduleIdMap:    (s) => s.$store.getters['schema/moduleIdMap'],
+		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         (s) => s.$store.getters.captions.admin.jobs,
+		capGen:         (s) => s.$store.getters.captions.generic,
+		config:         (s) => s.$store.getters.config,
+		settings:       (s) => s.$store.getters.settings
+	},
+	methods:{
+		// externals
+		getCaption,
+		getUnixFormat,
+
+		// presentation
+		displayAttemptMax(queueName) {
+			const q = this.queues.find(v => v.name === queueName);
+			return q !== undefined ? q.attemptMax : '-';
+		},
+		displayFunctionName(pgFunctionId) {
+			const f = this.pgFunctionIdMap[pgFunctionId];
+			if(f === undefined)
+				return pgFunctionId;
+
+			const m = this.moduleIdMap[f.moduleId];
+			return this.getCaption('moduleTitle',m.id,m.id,m.captions,m.name) + ': '
+				+ this.getCaption('pgFunctionTitle',f.moduleId,f.id,f.captions,f.name);
+		},
+		displayStateImage(state) {
+			switch(state) {
+				case 'done':    return 'images/ok.png';      break;
+				case 'failed':  return 'images/warning.png'; break;
+				case 'running': return 'images/time.png';    break;
+			}
+			return 'images/pageNext.png';
+		},
+
+		// actions
+		queueAdd() {
+			this.queuesInput.push({
+				name:'',
+				concurrencyMax:2,
+				attemptMax:5,
+				backoffBase:30,
+				timeout:240,
+				countWaiting:0,
+				countRunning:0,
+				countFailed:0
+			});
+		},
+		showText(title,text) {
+			this.$store.commit('dialog',{
+				captionBody:text,
+				captionTop:title,
+				image:'tasks.png',
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+		startAtPageFirst() {
+			this.offset = 0;
+			this.get();
+		},
+		startAtPageLast() {
+			this.offset = this.limit * (this.pages-1);
+			this.get();
+		},
+		offsetSet(add) {
+			if(add) this.offset += this.limit;
+			else    this.offset -= this.limit;
+			this.get();
+		},
+		toggleJobAll() {
+			if(this.jobIdsSelected.length === this.jobs.length) {
+				this.jobIdsSelected = [];
+				return;
+			}
+			this.jobIdsSelected = this.jobs.filter(v => v.state !== 'running').map(v => v.id);
+		},
+		toggleJobId(id) {
+			const pos = this.jobIdsSelected.indexOf(id);
+
+			if(pos === -1) this.jobIdsSelected.push(id);
+			else           this.jobIdsSelected.splice(pos,1);
+		},
+
+		// backend calls
+		del() {
+			ws.send('job','del',{ids:this.jobIdsSelected},true).then(
+				() => {
+					this.jobIdsSelected = [];
+					this.offset = 0;
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.sendMultiple([
+				ws.prepare('jobQueue','get',{}),
+				ws.prepare('job','get',{
+					limit:this.limit,
+					offset:this.offset,
+					queueName:this.queueName,
+					state:this.state
+				})
+			],true).then(
+				res => {
+					this.queues         = res[0].payload;
+					this.queuesInput    = JSON.parse(JSON.stringify(this.queues));
+					this.jobs           = res[1].payload.jobs;
+					this.jobIdsSelected = [];
+					this.total          = res[1].payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		queueDel(name) {
+			ws.send('jobQueue','del',{name:name},true).then(
+				() => {
+					if(this.queueName === name)
+						this.queueName = '';
+
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		queueSet(queue) {
+			ws.send('jobQueue','set',queue,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		reset() {
+			ws.send('job','reset',{ids:this.jobIdsSelected},true).then(
+				() => {
+					this.jobIdsSelected = [];
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		setConfig() {
+			ws.send('config','set',this.configInput,true).then(
+				() => {},
+				this.$root.genericError
+			);
+		}
+	}
+};
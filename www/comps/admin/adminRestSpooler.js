@@ -0,0 +1,242 @@
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminRestSpooler as default};
+
+let MyAdminRestSpooler = {
+	name:'my-admin-rest-spooler',
+	template:`<div class="admin-rest-spooler contentBox grow">
+
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/api.png" />
+				<h1>{{ menuTitle + ' (' + total + ')' }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-button image="autoRenew.png"
+					v-if="!noCalls"
+					@trigger="reset"
+					:active="callIdsSelected.length !== 0"
+					:caption="capApp.button.retry"
+				/>
+				<my-button image="delete.png"
+					v-if="!noCalls"
+					@trigger="del"
+					:active="callIdsSelected.length !== 0"
+					:cancel="true"
+					:caption="capGen.button.delete"
+				/>
+			</div>
+			<div class="area default-inputs" v-if="!noCalls">
+				<my-button image="triangleLeft.png"
+					@trigger="offsetSet(false)"
+					@trigger-shift="startAtPageFirst"
+					:active="offset-limit >= 0"
+					:naked="true"
+				/>
+
+				<span>{{ String((offset / limit) + 1) + ' / ' + pages  }}</span>
+
+				<my-button image="triangleRight.png"
+					@trigger="offsetSet(true)"
+					@trigger-shift="startAtPageLast"
+					:active="offset+limit < total"
+					:naked="true"
+				/>
+
+				<select v-model.number="limit" @change="startAtPageFirst">
+					<option>10</option>
+					<option>25</option>
+					<option>50</option>
+					<option>100</option>
+					<option>500</option>
+				</select>
+			</div>
+			<div class="area default-inputs">
+				<div class="row gap centered">
+					<my-bool v-model="failedOnly" @update:modelValue="startAtPageFirst" />
+					<span>{{ capApp.failedOnly }}</span>
+					<input v-model="search" @keyup.enter="startAtPageFirst" :placeholder="capGen.threeDots" />
+				</div>
+			</div>
+		</div>
+
+		<div class="content default-inputs" :class="{ 'no-padding':!noCalls }">
+			<span v-if="noCalls"><i>{{ capApp.noCallsInSpool }}</i></span>
+
+			<table class="generic-table bright shade" v-if="!noCalls">
+				<thead>
+					<tr>
+						<th>
+							<my-button
+								@trigger="toggleCallAll"
+								:image="callIdsSelected.length === calls.length ? 'checkbox1.png' : 'checkbox0.png'"
+								:naked="true"
+							/>
+						</th>
+						<th>{{ capApp.method }}</th>
+						<th>{{ capApp.url }}</th>
+						<th>{{ capApp.body }}</th>
+						<th>{{ capGen.date }}</th>
+						<th>{{ capApp.attempts }}</th>
+						<th>{{ capApp.dateNext }}</th>
+						<th>{{ capApp.statusSuccess }}</th>
+						<th>{{ capApp.lastStatus }}</th>
+						<th>{{ capApp.lastError }}</th>
+						<th>{{ capApp.lastResponse }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="c in calls">
+						<td class="minimum">
+							<my-button
+								@trigger="toggleCallId(c.id)"
+								:image="callIdsSelected.includes(c.id) ? 'checkbox1.png' : 'checkbox0.png'"
+								:naked="true"
+							/>
+						</td>
+						<td>{{ c.method }}</td>
+						<td>{{ c.url }}</td>
+						<td class="minimum">
+							<my-button image="search.png"
+								@trigger="showText(capApp.body,c.body)"
+								:active="c.body !== null && c.body !== ''"
+							/>
+						</td>
+						<td>{{ getUnixFormat(c.dateAdded,settings.dateFormat+' H:i') }}</td>
+						<td>{{ c.attemptCount + '/' + c.attemptMax }}</td>
+						<td>{{ displayDateNext(c) }}</td>
+						<td>{{ c.statusSuccess }}</td>
+						<td>{{ c.lastStatus !== null ? c.lastStatus : '-' }}</td>
+						<td>{{ c.lastError !== null ? c.lastError : '-' }}</td>
+						<td class="minimum">
+							<my-button image="search.png"
+								@trigger="showText(capApp.lastResponse,c.lastResponse)"
+								:active="c.lastResponse !== null && c.lastResponse !== ''"
+							/>
+						</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			failedOnly:false,
+			limit:50,
+			offset:0,
+			search:'',
+
+			// calls
+			calls:[],
+			callIdsSelected:[],
+			total:0
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.get();
+	},
+	computed:{
+		// simple
+		noCalls:(s) => s.total === 0,
+		pages:  (s) => Math.ceil(s.total / s.limit),
+
+		// stores
+		capApp:  (s) => s.$store.getters.captions.admin.restSpooler,
+		capGen:  (s) => s.$store.getters.captions.generic,
+		settings:(s) => s.$store.getters.settings
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// presentation
+		displayDateNext(call) {
+			if(call.attemptCount >= call.attemptMax) return this.capApp.givenUp;
+			if(call.dateNext === 0)                  return '-';
+			return this.getUnixFormat(call.dateNext,this.settings.dateFormat+' H:i:s');
+		},
+
+		// actions
+		showText(title,text) {
+			this.$store.commit('dialog',{
+				captionBody:text,
+				captionTop:title,
+				image:'api.png',
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+		startAtPageFirst() {
+			this.offset = 0;
+			this.get();
+		},
+		startAtPageLast() {
+			this.offset = this.limit * (this.pages-1);
+			this.get();
+		},
+		offsetSet(add) {
+			if(add) this.offset += this.limit;
+			else    this.offset -= this.limit;
+			this.get();
+		},
+		toggleCallAll() {
+			if(this.callIdsSelected.length === this.calls.length) {
+				this.callIdsSelected = [];
+				return;
+			}
+			this.callIdsSelected = this.calls.map(v => v.id);
+		},
+		toggleCallId(id) {
+			const pos = this.callIdsSelected.indexOf(id);
+
+			if(pos === -1) this.callIdsSelected.push(id);
+			else           this.callIdsSelected.splice(pos,1);
+		},
+
+		// backend calls
+		del() {
+			ws.send('restSpooler','del',{ids:this.callIdsSelected},true).then(
+				() => {
+					this.callIdsSelected = [];
+					this.offset = 0;
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('restSpooler','get',{
+				failedOnly:this.failedOnly,
+				limit:this.limit,
+				offset:this.offset,
+				search:this.search
+			},true).then(
+				res => {
+					this.calls           = res.payload.calls;
+					this.callIdsSelected = [];
+					this.total           = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		reset() {
+			ws.send('restSpooler','reset',{ids:this.callIdsSelected},true).then(
+				() => {
+					this.callIdsSelected = [];
+					this.get();
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -1,11 +1,17 @@
-import {getStringFilled} from '../shared/generic.js';
-import srcBase64Icon     from '../shared/image.js';
-import {getUnixFormat}   from '../shared/time.js';
-import {getCaption}      from '../shared/language.js';
+import MyAdminSchedulerRun  from './adminSchedulerRun.js';
+import MyAdminSchedulerRuns from './adminSchedulerRuns.js';
+import {getStringFilled}    from '../shared/generic.js';
+import srcBase64Icon        from '../shared/image.js';
+import {getUnixFormat}      from '../shared/time.js';
+import {getCaption}         from '../shared/language.js';
 export {MyAdminScheduler as default};
 
 let MyAdminScheduler = {
 	name:'my-admin-scheduler',
+	components:{
+		MyAdminSchedulerRun,
+		MyAdminSchedulerRuns
+	},
 	template:`<div class="admin-scheduler contentBox grow">
 	
 		<div class="top">
@@ -26,9 +32,39 @@ let MyAdminScheduler = {
 					:caption="capGen.button.refresh"
 				/>
 			</div>
+			<div class="area">
+				<my-button
+					@trigger="showOptions = !showOptions"
+					:caption="capGen.settings"
+					:image="showOptions ? 'visible1.png' : 'visible0.png'"
+				/>
+			</div>
 		</div>
 		
 		<div class="content no-padding">
+			
+			<!-- run history & alert options -->
+			<div class="content default-inputs" v-if="showOptions">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capApp.runsKeepDays }}</td>
+							<td><input class="short" v-model="configInput.taskRunsKeepDays" /></td>
+							<td>{{ capApp.runsKeepDaysHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.alertWebhookUrl }}</td>
+							<td><input v-model="configInput.taskAlertWebhookUrl" placeholder="https://..." /></td>
+							<td>{{ capApp.alertWebhookUrlHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+				<my-button image="save.png"
+					@trigger="setConfig"
+					:active="config.taskRunsKeepDays !== configInput.taskRunsKeepDays || config.taskAlertWebhookUrl !== configInput.taskAlertWebhookUrl"
+					:caption="capGen.button.save"
+				/>
+			</div>
 
 			<!-- mirror mode notice -->
 			<p class="message error" v-if="mirrorMode">
@@ -66,11 +102,17 @@ let MyAdminScheduler = {
 									/>
 								</td>
 								<td>
-									<my-button image="clock.png"
-										@trigger="runSystemTask(s.taskName)"
-										:active="!mirrorMode || !tasksDisabledMirrorMode.includes(s.taskName) ? schedulersInput[i].active : false"
-										:caption="capApp.button.runNow"
-									/>
+									<div class="row gap">
+										<my-button image="clock.png"
+											@trigger="runSystemTask(s.taskName)"
+											:active="!mirrorMode || !tasksDisabledMirrorMode.includes(s.taskName) ? schedulersInput[i].active : false"
+											:caption="capApp.button.runNow"
+										/>
+										<my-button image="log.png"
+											@trigger="runsShow(displayName(s.taskName),schedulers[i])"
+											:captionTitle="capApp.button.runs"
+										/>
+									</div>
 								</td>
 							</tr>
 						</template>
@@ -115,12 +157,18 @@ let MyAdminScheduler = {
 										/>
 									</td>
 									<td>
-										<my-button image="clock.png"
-											@trigger="runSystemTask(s.taskName)"
-											:active="schedulers[i].active"
-											:caption="capApp.button.runNow"
-											:captionTitle="capApp.button.runNowHint"
-										/>
+										<div class="row gap">
+											<my-button image="clock.png"
+												@trigger="runSystemTask(s.taskName)"
+												:active="schedulers[i].active"
+												:caption="capApp.button.runNow"
+												:captionTitle="capApp.button.runNowHint"
+											/>
+											<my-button image="log.png"
+												@trigger="runsShow(displayName(s.taskName),schedulers[i])"
+												:captionTitle="capApp.button.runs"
+											/>
+										</div>
 									</td>
 								</tr>
 								<tr v-if="schedulersExpanded.includes(i)" v-for="meta in s.nodeMeta">
@@ -136,6 +184,33 @@ let MyAdminScheduler = {
 				</table>
 			</div>
 			
+			<!-- task dependencies, from upstream to downstream tasks -->
+			<div class="content" v-if="dependencyLevels.length !== 0">
+				<my-label image="link.png" :caption="capApp.dependencies" :large="true" />
+				<br />
+				
+				<div class="admin-scheduler-dag">
+					<template v-for="(ids,i) in dependencyLevels">
+						<img class="admin-scheduler-dag-arrow" src="images/arrowRight.png" v-if="i !== 0" />
+						<div class="admin-scheduler-dag-level">
+							<div class="admin-scheduler-dag-task shade" v-for="id in ids">
+								<div class="row gap centered">
+									<span class="name">{{ scheduleIdMapName[id] }}</span>
+									<my-button image="log.png"
+										@trigger="runsShow(scheduleIdMapName[id],schedulers.find(v => v.scheduleId === id))"
+										:captionTitle="capApp.button.runs"
+										:naked="true"
+									/>
+								</div>
+								<span v-if="scheduleIdMapUpstream[id].length !== 0">
+									{{ capApp.upstreamAfter.replace('{NAMES}',scheduleIdMapUpstream[id].map(v => scheduleIdMapName[v]).join(', ')) }}
+								</span>
+							</div>
+						</div>
+					</template>
+				</div>
+			</div>
+			
 			<!-- module schedules -->
 			<div class="content" v-if="hasAppSchedules">
 				<my-label image="builder.png" :caption="capApp.functions" :large="true" />
@@ -165,30 +240,66 @@ let MyAdminScheduler = {
 							<td>{{ displayTime(s.dateAttempt) }}</td>
 							<td>{{ displayTime(s.dateSuccess) }}</td>
 							<td>
-								<my-button image="clock.png"
-									@trigger="runPgFunction(s.pgFunctionId,s.pgFunctionScheduleId)"
-									:caption="capApp.button.runNow"
-								/>
+								<div class="row gap">
+									<my-button image="clock.png"
+										@trigger="runPgFunction(s.pgFunctionId,s.pgFunctionScheduleId)"
+										:caption="capApp.button.runNow"
+									/>
+									<my-button image="settingsPlay.png"
+										@trigger="runManual = s"
+										:captionTitle="capApp.button.runManual"
+									/>
+									<my-button image="log.png"
+										@trigger="runsShow(displayFunctionName(s.pgFunctionId),s)"
+										:captionTitle="capApp.button.runs"
+									/>
+								</div>
 							</td>
 						</tr>
 					</tbody>
 				</table>
 			</div>
 		</div>
+		
+		<!-- manual run of PG function schedule -->
+		<my-admin-scheduler-run
+			v-if="runManual !== null"
+			@close="runManual = null"
+			@ran="get"
+			:name="displayFunctionName(runManual.pgFunctionId)"
+			:pgFunctionId="runManual.pgFunctionId"
+			:pgFunctionScheduleId="runManual.pgFunctionScheduleId"
+		/>
+		
+		<!-- run history of schedule -->
+		<my-admin-scheduler-runs
+			v-if="runsSchedule !== null"
+			@changed="get"
+			@close="runsSchedule = null"
+			:name="runsName"
+			:schedule="runsSchedule"
+			:scheduleIdMapName="scheduleIdMapName"
+		/>
 	</div>`,
 	props:{
 		menuTitle:{ type:String, required:true }
 	},
 	data() {
 		return {
+			configInput:{},
+			runManual:null,        // PG function schedule to run manually
+			runsName:'',
+			runsSchedule:null,     // schedule to show run history for
+			showOptions:false,
 			schedulers:[],
 			schedulersInput:[],    // changes to schedulers
 			schedulersExpanded:[], // indexes of schedules that show all nodes
-			tasksDisabledMirrorMode:['adminMails','backupRun','mailAttach','mailRetrieve','mailSend','restExecute']
+			tasksDisabledMirrorMode:['adminMails','backupRun','jobRun','mailAttach','mailRetrieve','mailSend','restExecute']
 		};
 	},
 	mounted() {
 		this.get();
+		this.configInput = JSON.parse(JSON.stringify(this.config));
 		this.$store.commit('pageTitle',this.menuTitle);
 		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
 	},
@@ -204,6 +315,50 @@ let MyAdminScheduler = {
 			return false;
 		},
 
+		dependencyLevels:(s) => {
+			// schedules with dependencies, grouped by their depth in the dependency graph
+			// depth is the longest path from a schedule without upstream schedules
+			let idMapLevel = {};
+			const getLevel = (id) => {
+				if(idMapLevel[id] === undefined) {
+					idMapLevel[id] = 0;
+					for(const idUp of s.scheduleIdMapUpstream[id])
+						idMapLevel[id] = Math.max(idMapLevel[id], getLevel(idUp) + 1);
+				}
+				return idMapLevel[id];
+			};
+			
+			let levels = [];
+			for(const e of s.schedulers) {
+				const isUpstream = s.schedulers.some(v => v.scheduleIdsUpstream.includes(e.scheduleId));
+				if(e.scheduleIdsUpstream.length === 0 && !isUpstream)
+					continue;
+				
+				const level = getLevel(e.scheduleId);
+				while(levels.length <= level) {
+					levels.push([]);
+				}
+				levels[level].push(e.scheduleId);
+			}
+			return levels;
+		},
+		scheduleIdMapName:(s) => {
+			let map = {};
+			for(const e of s.schedulers) {
+				map[e.scheduleId] = e.taskName !== ''
+					? s.displayName(e.taskName)
+					: `${s.displayModuleName(e.pgFunctionId)}: ${s.displayFunctionName(e.pgFunctionId)}`;
+			}
+			return map;
+		},
+		scheduleIdMapUpstream:(s) => {
+			let map = {};
+			for(const e of s.schedulers) {
+				map[e.scheduleId] = e.scheduleIdsUpstream;
+			}
+			return map;
+		},
+
 		// simple
 		hasChanges:          (s) => JSON.stringify(s.schedulers) !== JSON.stringify(s.schedulersInput),
 		pgFunctionSchedulers:(s) => s.schedulers.filter(v => v.taskName === '' && v.intervalType !== 'once'),
@@ -213,6 +368,7 @@ let MyAdminScheduler = {
 		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
 		capApp:         (s) => s.$store.getters.captions.admin.scheduler,
 		capGen:         (s) => s.$store.getters.captions.generic,
+		config:         (s) => s.$store.getters.config,
 		mirrorMode:     (s) => s.$store.getters.mirrorMode,
 		settings:       (s) => s.$store.getters.settings
 	},
@@ -255,6 +411,9 @@ let MyAdminScheduler = {
 			let parts    = [];
 			let typeName = '';
 			
+			if(s.intervalType === 'cron')
+				parts.push(this.capApp.scheduleLineCron.replace('{CRON}',s.cron));
+			
 			switch(s.intervalType) {
 				case 'days':    typeName = this.capApp.intervalTypeDays;    break;
 				case 'hours':   typeName = this.capApp.intervalTypeHours;   break;
@@ -265,10 +424,11 @@ let MyAdminScheduler = {
 				case 'years':   typeName = this.capApp.intervalTypeYears;   break;
 			}
 			
-			parts.push(this.capApp.scheduleLine
-				.replace('{TYPE}',typeName)
-				.replace('{VALUE}',s.intervalValue)
-			);
+			if(s.intervalType !== 'cron')
+				parts.push(this.capApp.scheduleLine
+					.replace('{TYPE}',typeName)
+					.replace('{VALUE}',s.intervalValue)
+				);
 			
 			switch(s.intervalType) {
 				case 'months': parts.push(this.capApp.scheduleLineDayMonths.replace('{DAY}',s.atDay)); break;
@@ -283,8 +443,18 @@ let MyAdminScheduler = {
 					.replace('{SS}',this.getStringFilled(s.atSecond,2,'0'))
 				);
 			
+			if(s.timeZone !== null)
+				parts.push(this.capApp.scheduleLineTimeZone.replace('{ZONE}',s.timeZone));
+			
+			if(s.blackouts.length !== 0)
+				parts.push(this.capApp.scheduleLineBlackouts.replace('{COUNT}',s.blackouts.length));
+			
 			return parts.join(', ');
 		},
+		runsShow(name,schedule) {
+			this.runsName     = name;
+			this.runsSchedule = schedule;
+		},
 		expandScheduler(i) {
 			let pos = this.schedulersExpanded.indexOf(i);
 			
@@ -298,10 +468,19 @@ let MyAdminScheduler = {
 				res => {
 					this.schedulers      = res.payload;
 					this.schedulersInput = JSON.parse(JSON.stringify(this.schedulers));
+					
+					if(this.runsSchedule !== null)
+						this.runsSchedule = this.schedulers.find(v => v.scheduleId === this.runsSchedule.scheduleId) || null;
 				},
 				this.$root.genericError
 			);
 		},
+		setConfig() {
+			ws.send('config','set',this.configInput,true).then(
+				() => {},
+				this.$root.genericError
+			);
+		},
 		runPgFunction(pgFunctionId,pgFunctionScheduleId) {
 			ws.send('task','run',{
 				clusterMasterOnly:true,
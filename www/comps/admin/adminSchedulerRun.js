@@ -0,0 +1,150 @@
+export {MyAdminSchedulerRun as default};
+
+let MyAdminSchedulerRun = {
+	name:'my-admin-scheduler-run',
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+
+		<div class="contentBox admin-scheduler-runs scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/settingsPlay.png" />
+					<h1 class="title">{{ capApp.runManualTitle.replace('{NAME}',name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button
+						@trigger="run"
+						:active="argsValid && !running"
+						:caption="dryRun ? capApp.button.runDry : capApp.button.runNow"
+						:image="dryRun ? 'visible1.png' : 'settingsPlay.png'"
+					/>
+				</div>
+			</div>
+
+			<div class="content default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capApp.runArgs }}</td>
+							<td>
+								<textarea class="admin-scheduler-run-args"
+									v-model="argsInput"
+									:class="{ error:!argsValid }"
+									:placeholder="'[1, &quot;text&quot;, null]'"
+								></textarea>
+							</td>
+							<td>{{ capApp.runArgsHint.replace('{ARGS}',pgFunction.codeArgs !== '' ? pgFunction.codeArgs : '-') }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.runDryRun }}</td>
+							<td><my-bool v-model="dryRun" /></td>
+							<td>{{ capApp.runDryRunHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+
+				<template v-if="result !== null">
+					<br />
+					<p class="message" :class="{ error:result.error !== null }">
+						{{ result.error !== null ? result.error : (result.dryRun ? capApp.runResultDry : capApp.runResultDone).replace('{MS}',result.durationMs) }}
+					</p>
+
+					<table class="generic-table-vertical" v-if="result.error === null">
+						<tbody>
+							<tr>
+								<td>{{ capApp.runReturnValue }}</td>
+								<td>{{ result.returnValue !== null && result.returnValue !== '' ? result.returnValue : '-' }}</td>
+							</tr>
+						</tbody>
+					</table>
+					<br />
+
+					<span v-if="result.rowCounts.length === 0"><i>{{ capApp.runRowCountsNone }}</i></span>
+					<table class="generic-table bright" v-else>
+						<thead>
+							<tr>
+								<th>{{ capApp.runTable }}</th>
+								<th>{{ capApp.runInserted }}</th>
+								<th>{{ capApp.runUpdated }}</th>
+								<th>{{ capApp.runDeleted }}</th>
+							</tr>
+						</thead>
+						<tbody>
+							<tr v-for="c in result.rowCounts">
+								<td>{{ c.schema + '.' + c.table }}</td>
+								<td>{{ c.inserted }}</td>
+								<td>{{ c.updated }}</td>
+								<td>{{ c.deleted }}</td>
+							</tr>
+						</tbody>
+					</table>
+					<br />
+
+					<template v-if="result.notices.length !== 0">
+						<my-label image="code.png" :caption="capApp.runOutput" />
+						<textarea class="admin-scheduler-run-notices" readonly="readonly" :value="result.notices.join('\\n')"></textarea>
+					</template>
+				</template>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		name:                { type:String, required:true },
+		pgFunctionId:        { type:String, required:true },
+		pgFunctionScheduleId:{ type:String, required:true }
+	},
+	emits:['close','ran'],
+	data() {
+		return {
+			argsInput:'[]',
+			dryRun:true,    // default to safe option
+			result:null,    // result of last run
+			running:false
+		};
+	},
+	computed:{
+		args:(s) => {
+			try {
+				const args = JSON.parse(s.argsInput);
+				return Array.isArray(args) ? args : null;
+			}
+			catch(e) {
+				return null;
+			}
+		},
+
+		// simple
+		argsValid: (s) => s.args !== null,
+		pgFunction:(s) => s.pgFunctionIdMap[s.pgFunctionId],
+
+		// stores
+		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         (s) => s.$store.getters.captions.admin.scheduler
+	},
+	methods:{
+		// backend calls
+		run() {
+			this.running = true;
+			ws.send('task','runManual',{
+				args:this.args,
+				dryRun:this.dryRun,
+				pgFunctionScheduleId:this.pgFunctionScheduleId
+			},true).then(
+				res => {
+					this.result = res.payload;
+
+					if(!this.result.dryRun)
+						this.$emit('ran');
+				},
+				this.$root.genericError
+			).finally(() => this.running = false);
+		}
+	}
+};
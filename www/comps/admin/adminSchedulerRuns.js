@@ -0,0 +1,289 @@
+import {getCaption}    from '../shared/language.js';
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminSchedulerRuns as default};
+
+let MyAdminSchedulerRuns = {
+	name:'my-admin-scheduler-runs',
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+
+		<div class="contentBox admin-scheduler-runs scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/log.png" />
+					<h1 class="title">{{ capApp.runsTitle.replace('{NAME}',name) + ' (' + total + ')' }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="hasChanges"
+						:caption="capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						@trigger="get"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+				<div class="area default-inputs" v-if="total !== 0">
+					<my-button image="triangleLeft.png"
+						@trigger="offsetSet(false)"
+						:active="offset-limit >= 0"
+						:naked="true"
+					/>
+					<span>{{ String((offset / limit) + 1) + ' / ' + pages  }}</span>
+					<my-button image="triangleRight.png"
+						@trigger="offsetSet(true)"
+						:active="offset+limit < total"
+						:naked="true"
+					/>
+				</div>
+			</div>
+
+			<div class="content default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capApp.alertFailCount }}</td>
+							<td><input class="short" v-model.number="alertFailCount" /></td>
+							<td>{{ capApp.alertFailCountHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.alertDuration }}</td>
+							<td><input class="short" v-model.number="alertDuration" /></td>
+							<td>{{ capApp.alertDurationHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.upstream }}</td>
+							<td>
+								<div class="column gap">
+									<div class="row gap centered" v-for="(id,i) in scheduleIdsUpstream">
+										<span>{{ scheduleIdMapName[id] !== undefined ? scheduleIdMapName[id] : id }}</span>
+										<my-button image="delete.png"
+											@trigger="scheduleIdsUpstream.splice(i,1)"
+											:naked="true"
+										/>
+									</div>
+									<select @change="upstreamAdd(parseInt($event.target.value)); $event.target.value = ''">
+										<option value="">{{ capApp.upstreamAdd }}</option>
+										<template v-for="(name,id) in scheduleIdMapName">
+											<option
+												v-if="parseInt(id) !== schedule.scheduleId && !scheduleIdsUpstream.includes(parseInt(id))"
+												:value="id"
+											>{{ name }}</option>
+										</template>
+									</select>
+								</div>
+							</td>
+							<td>{{ capApp.upstreamHint }}</td>
+						</tr>
+						<tr v-if="schedule.taskName === ''">
+							<td>{{ capApp.runRoles }}</td>
+							<td>
+								<div class="column gap">
+									<div class="row gap centered" v-for="(id,i) in roleIds">
+										<span>{{ displayRole(id) }}</span>
+										<my-button image="delete.png"
+											@trigger="roleIds.splice(i,1)"
+											:naked="true"
+										/>
+									</div>
+									<select @change="roleAdd($event.target.value); $event.target.value = ''">
+										<option value="">{{ capApp.runRolesAdd }}</option>
+										<optgroup
+											v-for="m in modules.filter(v => !v.hidden && v.roles.length !== 0)"
+											:label="m.name"
+										>
+											<option
+												v-for="r in m.roles.filter(v => v.name !== 'everyone' && !roleIds.includes(v.id))"
+												:value="r.id"
+											>{{ displayRole(r.id) }}</option>
+										</optgroup>
+									</select>
+								</div>
+							</td>
+							<td>{{ capApp.runRolesHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+				<br />
+
+				<span v-if="total === 0"><i>{{ capApp.runsNothingThere }}</i></span>
+
+				<table class="generic-table bright" v-if="total !== 0">
+					<thead>
+						<tr>
+							<th>{{ capApp.runStart }}</th>
+							<th>{{ capApp.runDuration }}</th>
+							<th>{{ capApp.runNode }}</th>
+							<th>{{ capApp.runLogin }}</th>
+							<th>{{ capApp.runOutcome }}</th>
+							<th>{{ capApp.runError }}</th>
+							<th>{{ capApp.runOutput }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="r in runs">
+							<td>{{ getUnixFormat(r.dateStart,settings.dateFormat+' H:i:S') }}</td>
+							<td>{{ r.durationMs !== null ? r.durationMs + ' ms' : '-' }}</td>
+							<td>{{ r.nodeName !== null ? r.nodeName : '-' }}</td>
+							<td :title="r.args !== null ? capApp.runArgs + ': ' + r.args : ''">{{ r.loginName !== null ? r.loginName : '-' }}</td>
+							<td>
+								<div class="row gap centered">
+									<img class="icon" :src="displayOutcomeImage(r)" />
+									<span>{{ displayOutcome(r) }}</span>
+								</div>
+							</td>
+							<td>{{ r.error !== null ? r.error : '-' }}</td>
+							<td v-if="r.output === null">-</td>
+							<td v-else><my-button image="code.png" @trigger="showOutput(r.output)" /></td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		name:             { type:String, required:true },
+		schedule:         { type:Object, required:true },
+		scheduleIdMapName:{ type:Object, required:true } // names of all schedules, to choose upstream schedules from
+	},
+	emits:['changed','close'],
+	data() {
+		return {
+			// inputs
+			alertDuration:this.schedule.alertDuration,
+			alertFailCount:this.schedule.alertFailCount,
+			roleIds:JSON.parse(JSON.stringify(this.schedule.roleIds)),
+			scheduleIdsUpstream:JSON.parse(JSON.stringify(this.schedule.scheduleIdsUpstream)),
+			limit:25,
+			offset:0,
+
+			// entries
+			runs:[],
+			total:0
+		};
+	},
+	mounted() {
+		this.get();
+	},
+	computed:{
+		// simple
+		hasChanges:        (s) => s.hasChangesAlerts || s.hasChangesRoles || s.hasChangesUpstream,
+		hasChangesAlerts:  (s) => s.alertDuration !== s.schedule.alertDuration || s.alertFailCount !== s.schedule.alertFailCount,
+		hasChangesRoles:   (s) => JSON.stringify(s.roleIds) !== JSON.stringify(s.schedule.roleIds),
+		hasChangesUpstream:(s) => JSON.stringify(s.scheduleIdsUpstream) !== JSON.stringify(s.schedule.scheduleIdsUpstream),
+		pages:             (s) => Math.ceil(s.total / s.limit),
+
+		// stores
+		modules:  (s) => s.$store.getters['schema/modules'],
+		roleIdMap:(s) => s.$store.getters['schema/roleIdMap'],
+		capApp:   (s) => s.$store.getters.captions.admin.scheduler,
+		capGen:   (s) => s.$store.getters.captions.generic,
+		settings: (s) => s.$store.getters.settings
+	},
+	methods:{
+		// externals
+		getCaption,
+		getUnixFormat,
+
+		// presentation
+		displayRole(id) {
+			const r = this.roleIdMap[id];
+			if(r === undefined)
+				return id;
+			
+			const m = this.modules.find(v => v.id === r.moduleId);
+			return this.getCaption('moduleTitle',m.id,m.id,m.captions,m.name) + ': '
+				+ this.getCaption('roleTitle',m.id,r.id,r.captions,r.name);
+		},
+		displayOutcome(run) {
+			if(run.skipped)          return this.capApp.runOutcomeSkipped;
+			if(run.success === null) return this.capApp.runOutcomeRunning;
+			return run.success ? this.capApp.runOutcomeSuccess : this.capApp.runOutcomeFailure;
+		},
+		displayOutcomeImage(run) {
+			if(run.skipped)          return 'images/pageNext.png';
+			if(run.success === null) return 'images/time.png';
+			return run.success ? 'images/ok.png' : 'images/warning.png';
+		},
+
+		// actions
+		roleAdd(id) {
+			if(id !== '' && !this.roleIds.includes(id))
+				this.roleIds.push(id);
+		},
+		upstreamAdd(id) {
+			if(!isNaN(id) && !this.scheduleIdsUpstream.includes(id))
+				this.scheduleIdsUpstream.push(id);
+		},
+		offsetSet(add) {
+			if(add) this.offset += this.limit;
+			else    this.offset -= this.limit;
+			this.get();
+		},
+		showOutput(output) {
+			this.$store.commit('dialog',{
+				captionTop:this.capApp.runOutput,
+				captionBody:output,
+				image:'code.png',
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+
+		// backend calls
+		get() {
+			ws.send('scheduler','getRuns',{
+				limit:this.limit,
+				offset:this.offset,
+				scheduleId:this.schedule.scheduleId
+			},true).then(
+				res => {
+					this.runs  = res.payload.runs;
+					this.total = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			let requests = [];
+			if(this.hasChangesAlerts)
+				requests.push(ws.prepare('scheduler','setAlerts',{
+					alertDuration:this.alertDuration,
+					alertFailCount:this.alertFailCount,
+					scheduleId:this.schedule.scheduleId
+				}));
+			
+			if(this.hasChangesUpstream)
+				requests.push(ws.prepare('scheduler','setDependencies',{
+					scheduleId:this.schedule.scheduleId,
+					scheduleIdsUpstream:this.scheduleIdsUpstream
+				}));
+			
+			if(this.hasChangesRoles)
+				requests.push(ws.prepare('scheduler','setRoles',{
+					roleIds:this.roleIds,
+					scheduleId:this.schedule.scheduleId
+				}));
+			
+			ws.sendMultiple(requests,true).then(
+				() => {
+					this.$emit('changed');
+
+					ws.send('task','informChanged',{},false).then(
+						() => {},
+						this.$root.genericError
+					);
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -0,0 +1,219 @@
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminSlowQueries as default};
+
+let MyAdminSlowQueries = {
+	name:'my-admin-slow-queries',
+	template:`<div class="admin-slow-queries contentBox grow">
+
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/database.png" />
+				<h1>{{ menuTitle + ' (' + total + ')' }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+			<div class="area default-inputs" v-if="!noEntries">
+				<my-button image="triangleLeft.png"
+					@trigger="offsetSet(false)"
+					@trigger-shift="startAtPageFirst"
+					:active="offset-limit >= 0"
+					:naked="true"
+				/>
+
+				<span>{{ String((offset / limit) + 1) + ' / ' + pages  }}</span>
+
+				<my-button image="triangleRight.png"
+					@trigger="offsetSet(true)"
+					@trigger-shift="startAtPageLast"
+					:active="offset+limit < total"
+					:naked="true"
+				/>
+			</div>
+			<div class="area default-inputs">
+				<div class="row gap default-inputs">
+					<my-button
+						@trigger="showOptions = !showOptions"
+						:caption="capGen.settings"
+						:image="showOptions ? 'visible1.png' : 'visible0.png'"
+					/>
+					<select class="short" v-model="orderByDuration" @change="startAtPageFirst">
+						<option :value="false">{{ capApp.orderByDate }}</option>
+						<option :value="true">{{ capApp.orderByDuration }}</option>
+					</select>
+					<select class="short" v-model.number="limit" @change="startAtPageFirst">
+						<option>10</option>
+						<option>25</option>
+						<option>50</option>
+						<option>100</option>
+						<option>500</option>
+					</select>
+				</div>
+			</div>
+		</div>
+
+		<div class="content default-inputs" :class="{ 'no-padding':!noEntries }">
+
+			<!-- options -->
+			<div v-if="showOptions" class="admin-slow-queries-settings">
+				<div class="row gap centered default-inputs">
+					<span>{{ capApp.thresholdMs }}</span>
+					<input class="short" v-model="configInput.slowQueryThresholdMs" />
+					<span>{{ capApp.keepDays }}</span>
+					<input class="short" v-model="configInput.slowQueryKeepDays" />
+					<my-button image="save.png"
+						@trigger="setConfig"
+						:caption="capGen.button.save"
+						:active="config.slowQueryThresholdMs !== configInput.slowQueryThresholdMs || config.slowQueryKeepDays !== configInput.slowQueryKeepDays"
+					/>
+				</div>
+				<p>{{ capApp.thresholdMsHint }}</p>
+			</div>
+
+			<span v-if="noEntries"><i>{{ capApp.nothingThere }}</i></span>
+
+			<table class="generic-table bright" v-if="!noEntries">
+				<thead>
+					<tr>
+						<th>{{ capGen.date }}</th>
+						<th>{{ capApp.duration }}</th>
+						<th>{{ capApp.action }}</th>
+						<th>{{ capApp.login }}</th>
+						<th>{{ capApp.relation }}</th>
+						<th>{{ capApp.origin }}</th>
+						<th>{{ capApp.node }}</th>
+						<th>SQL</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="e in entries">
+						<td>{{ getUnixFormat(Math.floor(e.date / 1000),settings.dateFormat+' H:i:s') }}</td>
+						<td>{{ e.durationMs + ' ms' }}</td>
+						<td>{{ capApp.option.action[e.action] }}</td>
+						<td>{{ e.loginName !== null ? e.loginName : '-' }}</td>
+						<td>{{ displayRelations(e.relationIds) }}</td>
+						<td>{{ displayOrigin(e) }}</td>
+						<td>{{ e.nodeName !== null ? e.nodeName : '-' }}</td>
+						<td v-if="e.sql === null">-</td>
+						<td v-else><my-button image="code.png" @trigger="showSql(e.sql)" /></td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			configInput:{},
+			limit:50,
+			offset:0,
+			orderByDuration:false,
+			showOptions:false,
+
+			// entries
+			entries:[],
+			total:0
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.configInput = JSON.parse(JSON.stringify(this.config));
+		this.get();
+	},
+	computed:{
+		// simple
+		noEntries:(s) => s.total === 0,
+		pages:    (s) => Math.ceil(s.total / s.limit),
+
+		// stores
+		apiIdMap:       (s) => s.$store.getters['schema/apiIdMap'],
+		collectionIdMap:(s) => s.$store.getters['schema/collectionIdMap'],
+		formIdMap:      (s) => s.$store.getters['schema/formIdMap'],
+		moduleIdMap:    (s) => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap:  (s) => s.$store.getters['schema/relationIdMap'],
+		capApp:         (s) => s.$store.getters.captions.admin.slowQuery,
+		capGen:         (s) => s.$store.getters.captions.generic,
+		config:         (s) => s.$store.getters.config,
+		settings:       (s) => s.$store.getters.settings
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// presentation
+		displayOrigin(e) {
+			if(e.apiId !== null && this.apiIdMap[e.apiId] !== undefined)
+				return `${this.capApp.option.origin.api}: ${this.apiIdMap[e.apiId].name} (v${this.apiIdMap[e.apiId].version})`;
+
+			if(e.collectionId !== null && this.collectionIdMap[e.collectionId] !== undefined)
+				return `${this.capApp.option.origin.collection}: ${this.collectionIdMap[e.collectionId].name}`;
+
+			if(e.formId !== null && this.formIdMap[e.formId] !== undefined)
+				return `${this.capApp.option.origin[e.fieldId !== null ? 'field' : 'form']}: ${this.formIdMap[e.formId].name}`;
+
+			return '-';
+		},
+		displayRelations(ids) {
+			let out = [];
+			for(const id of ids) {
+				const r = this.relationIdMap[id];
+				if(r !== undefined)
+					out.push(`${this.moduleIdMap[r.moduleId].name}.${r.name}`);
+			}
+			return out.length !== 0 ? out.join(', ') : '-';
+		},
+
+		// actions
+		offsetSet(add) {
+			if(add) this.offset += this.limit;
+			else    this.offset -= this.limit;
+			this.get();
+		},
+		showSql(sql) {
+			this.$store.commit('dialog',{
+				captionTop:'SQL',
+				captionBody:sql,
+				image:'database.png',
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+		startAtPageFirst() {
+			this.offset = 0;
+			this.get();
+		},
+		startAtPageLast() {
+			this.offset = this.limit * (this.pages-1);
+			this.get();
+		},
+
+		// backend calls
+		get() {
+			ws.send('log','getSlow',{
+				limit:this.limit,
+				offset:this.offset,
+				orderByDuration:this.orderByDuration
+			},true).then(
+				res => {
+					this.entries = res.payload.entries;
+					this.total   = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		setConfig() {
+			ws.send('config','set',this.configInput,true).then(
+				() => {},
+				this.$root.genericError
+			);
+		}
+	}
+};
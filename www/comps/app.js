@@ -214,10 +214,15 @@ export default {
 			showHoverNav:false,   // alternative hover menu for module navigation
 			showSettings:false,   // login settings
 			timerSystemMsg:null,  // timer checking for system message start/stop
+			timerWatch:null,      // timer to inform backend about watched relations, collects changes of many fields
 			wsConnected:false     // connection to backend has been established (websocket)
 		};
 	},
 	watch:{
+		appReady(v) {
+			// backend forgets watched relations when connection is lost
+			if(v) this.wsRelationsWatchedSet();
+		},
 		css:{
 			handler(v) {
 				let e = document.getElementById('app-custom-css');
@@ -254,6 +259,10 @@ export default {
 			},
 			immediate:true
 		},
+		relationIdsWatched(v,vOld) {
+			if(v.join() !== vOld.join())
+				this.wsRelationsWatchedSet();
+		},
 		$route:{
 			handler(v) {
 				this.$store.commit('isAtModule',typeof v.meta.atModule !== 'undefined');
@@ -420,6 +429,7 @@ export default {
 		moduleIdMapMeta:    (s) => s.$store.getters.moduleIdMapMeta,
 		patternStyle:       (s) => s.$store.getters.patternStyle,
 		popUpFormGlobal:    (s) => s.$store.getters.popUpFormGlobal,
+		relationIdsWatched: (s) => s.$store.getters.relationIdsWatched,
 		settings:           (s) => s.$store.getters.settings,
 		systemMsgActive:    (s) => s.$store.getters.systemMsgActive,
 		systemMsgDate0:     (s) => s.$store.getters.systemMsgDate0,
@@ -511,6 +521,20 @@ export default {
 			this.wsConnected = true;
 			this.stateChange();
 		},
+		wsRelationsWatchedSet() {
+			// watched relations are kept by the backend per connection, inform it after changes or (re)login
+			if(this.timerWatch !== null)
+				clearTimeout(this.timerWatch);
+			
+			this.timerWatch = setTimeout(() => {
+				this.timerWatch = null;
+				
+				if(this.appReady)
+					ws.send('data','watch',{relationIds:this.relationIdsWatched},false,true).then(
+						() => {}, this.genericError
+					);
+			},300);
+		},
 		wsBackendRequest(res) {
 			switch(res.ressource) {
 				// affects admins only
@@ -536,6 +560,9 @@ export default {
 				case 'collectionChanged':
 					this.updateCollections(res.payload);
 				break;
+				case 'dataChanged':
+					this.$store.commit('dataChangedLast',{relationId:res.payload});
+				break;
 				case 'configChanged':
 					if(this.isAdmin) {
 						ws.sendMultiple([
@@ -562,6 +589,9 @@ export default {
 						);
 					}
 				break;
+				case 'recordLockChanged':
+					this.$store.commit('recordLockLast',res.payload);
+				break;
 			}
 		},
 		wsBlocking(state) {
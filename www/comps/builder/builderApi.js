@@ -14,6 +14,7 @@ import {
 	MyBuilderColumns,
 	MyBuilderColumnTemplates
 } from './builderColumns.js';
+import {getSqlExplainApi} from '../shared/builder.js';
 import {
 	copyValueDialog,
 	getNilUuid
@@ -373,6 +374,11 @@ let MyBuilderApi = {
 						:caption="capApp.button.versionNew"
 						:captionTitle="capApp.button.versionNewHint"
 					/>
+					<my-button image="database.png"
+						@trigger="getSqlExplainApi(id)"
+						:active="!hasChanges"
+						:caption="capGen.sqlExplain"
+					/>
 				</div>
 				<div class="area nowrap">
 					<my-button image="visible1.png"
@@ -443,17 +449,23 @@ let MyBuilderApi = {
 					@index-removed="removeIndex($event)"
 					@set-filters="filters = $event"
 					@set-fixed-limit="fixedLimit = $event"
+					@set-grouping="grouping = $event"
+					@set-grouping-sets="groupingSets = $event"
 					@set-joins="joins = $event"
 					@set-lookups="lookups = $event"
 					@set-orders="orders = $event"
 					@set-relation-id="relationId = $event"
 					:allowChoices="false"
+					:allowGrouping="true"
 					:allowLookups="true"
 					:allowOrders="true"
 					:builderLanguage="builderLanguage"
+					:columns="columns"
 					:filters="filters"
 					:filtersDisable="filtersDisable"
 					:fixedLimit="fixedLimit"
+					:grouping="grouping"
+					:groupingSets="groupingSets"
 					:joins="joins"
 					:lookups="lookups"
 					:moduleId="module.id"
@@ -493,6 +505,7 @@ let MyBuilderApi = {
 						@set="(...args) => columnSet(args[0],args[1])"
 						:builderLanguage="builderLanguage"
 						:column="columnShow"
+						:columns="columns"
 						:hasCaptions="true"
 						:moduleId="module.id"
 						:onlyData="true"
@@ -618,6 +631,8 @@ let MyBuilderApi = {
 			orders:[],
 			lookups:[],
 			fixedLimit:0,
+			grouping:null,
+			groupingSets:[],
 			
 			// API inputs
 			columns:[],
@@ -665,6 +680,8 @@ let MyBuilderApi = {
 			|| s.version                 !== s.api.version
 			|| s.relationId              !== s.api.query.relationId
 			|| s.fixedLimit              !== s.api.query.fixedLimit
+			|| s.grouping                !== s.api.query.grouping
+			|| JSON.stringify(s.groupingSets) !== JSON.stringify(s.api.query.groupingSets)
 			|| JSON.stringify(s.joins)   !== JSON.stringify(s.api.query.joins)
 			|| JSON.stringify(s.filters) !== JSON.stringify(s.api.query.filters)
 			|| JSON.stringify(s.orders)  !== JSON.stringify(s.api.query.orders)
@@ -718,6 +735,7 @@ let MyBuilderApi = {
 		// externals
 		copyValueDialog,
 		getNilUuid,
+		getSqlExplainApi,
 		
 		// actions
 		columnSet(name,value) {
@@ -750,6 +768,8 @@ let MyBuilderApi = {
 			this.version    = this.api.version;
 			this.relationId = this.api.query.relationId;
 			this.fixedLimit = this.api.query.fixedLimit;
+			this.grouping   = this.api.query.grouping;
+			this.groupingSets = JSON.parse(JSON.stringify(this.api.query.groupingSets));
 			this.joins      = JSON.parse(JSON.stringify(this.api.query.joins));
 			this.filters    = JSON.parse(JSON.stringify(this.api.query.filters));
 			this.orders     = JSON.parse(JSON.stringify(this.api.query.orders));
@@ -823,7 +843,9 @@ let MyBuilderApi = {
 						filters:this.filters,
 						orders:this.orders,
 						lookups:this.lookups,
-						fixedLimit:this.fixedLimit
+						fixedLimit:this.fixedLimit,
+						grouping:this.grouping,
+						groupingSets:this.groupingSets
 					},
 					hasDelete:this.hasDelete,
 					hasGet:this.hasGet,
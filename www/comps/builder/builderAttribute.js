@@ -274,6 +274,30 @@ let MyBuilderAttribute = {
 							<td v-else>{{ capApp.lengthFractHintMax }}</td>
 						</tr>
 						
+						<!-- generated -->
+						<tr v-if="canGenerate">
+							<td>{{ capApp.generated }}</td>
+							<td><my-bool v-model="isGenerated" :readonly="readonly || !isNew" /></td>
+							<td>{{ capApp.generatedHint }}</td>
+						</tr>
+						<tr v-if="canGenerate && isGenerated">
+							<td>{{ capApp.generatedExpr }}</td>
+							<td>
+								<div class="column gap">
+									<textarea ref="generated" placeholder="..."
+										v-model="values.generated"
+										:disabled="readonly"
+									></textarea>
+									<select @change="insertGeneratedAttribute($event.target.value);$event.target.value = ''" :disabled="readonly">
+										<option value="">{{ capApp.generatedInsert }}</option>
+										<option v-for="a in attributesGeneratedInput" :value="a.id">{{ a.name }}</option>
+									</select>
+									<span v-if="values.generated !== ''">{{ generatedPreview }}</span>
+								</div>
+							</td>
+							<td>{{ capApp.generatedExprHint }}</td>
+						</tr>
+						
 						<!-- encrypted -->
 						<tr v-if="canEncrypt">
 							<td>{{ capApp.encrypted }}</td>
@@ -289,7 +313,7 @@ let MyBuilderAttribute = {
 						</tr>
 						
 						<!-- defaults -->
-						<tr v-if="!isId && !isFiles && !isRelationship">
+						<tr v-if="!isId && !isFiles && !isRelationship && !isGenerated">
 							<td>{{ capApp.defaults }}</td>
 							<td>
 								<div class="column gap">
@@ -346,6 +370,14 @@ let MyBuilderAttribute = {
 			get()  { return this.values.content === 'double precision'; },
 			set(v) { this.values.content = v ? 'double precision' : 'real'; }
 		},
+		isGenerated:{
+			get()  { return this.values.generated !== null; },
+			set(v) {
+				this.values.generated = v ? '' : null;
+				this.values.def       = '';
+				this.defaultsOption   = 'fixed';
+			}
+		},
 		usedFor:{
 			get() { return this.getAttributeContentUse(this.values.content, this.values.contentUse); },
 			set(v) {
@@ -446,6 +478,15 @@ let MyBuilderAttribute = {
 			}
 		},
 		
+		attributesGeneratedInput:(s) => {
+			// generated attributes can only refer to regular attributes of the same relation
+			return s.relation.attributes.filter(a => a.id !== s.attributeId && a.generated === null
+				&& !a.encrypted && !s.isAttributeFiles(a.content));
+		},
+		generatedPreview:(s) => {
+			return s.values.generated.replace(/\(([a-z0-9\-]{36})\)/g,(match,id) =>
+				s.attributeIdMap[id] !== undefined ? `"${s.attributeIdMap[id].name}"` : match);
+		},
 		lengthTitle:(s) => {
 			if(s.isString)  return s.capApp.lengthText;
 			if(s.isNumeric) return s.capApp.lengthNumeric;
@@ -460,8 +501,9 @@ let MyBuilderAttribute = {
 		},
 		
 		// simple
-		canEncrypt:    (s) => s.relation.encryption && s.values.content === 'text',
-		canSave:       (s) => !s.readonly && s.hasChanges && !s.nameTaken,
+		canEncrypt:    (s) => s.relation.encryption && s.values.content === 'text' && !s.isGenerated,
+		canGenerate:   (s) => !s.isId && !s.isFiles && !s.isRelationship && !s.values.encrypted,
+		canSave:       (s) => !s.readonly && s.hasChanges && !s.nameTaken && (!s.isGenerated || s.values.generated.trim() !== ''),
 		hasChanges:    (s) => s.values.name !== '' && JSON.stringify(s.values) !== JSON.stringify(s.valuesOrg),
 		hasLength:     (s) => ['decimal','files','richtext','text','textarea'].includes(s.usedFor),
 		hasLengthFract:(s) => ['decimal'].includes(s.usedFor),
@@ -539,6 +581,14 @@ let MyBuilderAttribute = {
 			if(!this.isRelationship && this.values.relationshipId !== null)
 				this.values.relationshipId = null;
 		},
+		insertGeneratedAttribute(id) {
+			if(id === '') return;
+			
+			const input = this.$refs.generated;
+			const pos   = input.selectionStart;
+			this.values.generated = this.values.generated.substring(0,pos)
+				+ `(${id})` + this.values.generated.substring(input.selectionEnd);
+		},
 		handleHotkeys(e) {
 			if(e.ctrlKey && e.key === 's' && this.canSave) {
 				this.set();
@@ -566,6 +616,7 @@ let MyBuilderAttribute = {
 					nullable:true,
 					encrypted:false,
 					def:'',
+					generated:null,
 					onUpdate:'NO ACTION',
 					onDelete:'NO ACTION',
 					captions:{
@@ -630,13 +681,18 @@ let MyBuilderAttribute = {
 		delCheck() {
 			ws.send('attribute','delCheck',{id:this.attributeId},true).then(
 				res => {
+					// generated attributes refer to attributes of the same relation
+					const atrsGenerated = this.relation.attributes.filter(a => a.generated !== null
+						&& a.generated.includes(`(${this.attributeId})`));
+					
 					const noDependencies =
 						res.payload.apiIds.length         === 0 &&
 						res.payload.collectionIds.length  === 0 &&
 						res.payload.formIds.length        === 0 &&
 						res.payload.pgIndexIds.length     === 0 &&
 						res.payload.loginFormNames.length === 0 &&
-						res.payload.fields.length         === 0;
+						res.payload.fields.length         === 0 &&
+						atrsGenerated.length              === 0;
 					
 					if(noDependencies)
 						return this.delAsk();
@@ -675,6 +731,9 @@ let MyBuilderAttribute = {
 						
 						dependencies.push(`${this.moduleIdMap[form.moduleId].name}: ${this.capGen.field} <a href="${url}">'${label}'</a> (${this.capGen.form}: ${form.name})`);
 					}
+					for(let a of atrsGenerated) {
+						dependencies.push(`${this.module.name}: ${this.capGen.attribute} '${this.relation.name}.${a.name}'`);
+					}
 					for(let name of res.payload.loginFormNames) {
 						dependencies.push(`${this.capGen.loginForm}: '${name}'`);
 					}
@@ -710,6 +769,9 @@ let MyBuilderAttribute = {
 			if(this.values.encrypted && !this.canEncrypt)
 				this.values.encrypted = false;
 			
+			if(this.values.generated !== null && !this.canGenerate)
+				this.values.generated = null;
+			
 			ws.sendMultiple([
 				ws.prepare('attribute','set',this.values),
 				ws.prepare('schema','check',{ moduleId:this.module.id })
@@ -2,7 +2,10 @@ import MyBuilderQuery                  from './builderQuery.js';
 import MyBuilderCollectionInput        from './builderCollectionInput.js';
 import MyBuilderColumnOptions          from './builderColumnOptions.js';
 import MyBuilderIconInput              from './builderIconInput.js';
-import {getItemTitleColumn}            from '../shared/builder.js';
+import {
+	getItemTitleColumn,
+	getSqlExplain
+} from '../shared/builder.js';
 import {getCollectionConsumerTemplate} from '../shared/collection.js';
 import MyTabs                          from '../tabs.js';
 import {
@@ -59,6 +62,10 @@ let MyBuilderCollection = {
 						:caption="capGen.preview"
 						:image="showPreview ? 'checkbox1.png' : 'checkbox0.png'"
 					/>
+					<my-button image="database.png"
+						@trigger="getSqlExplain({relationId,joins,filters,orders,fixedLimit},columns)"
+						:caption="capGen.sqlExplain"
+					/>
 				</div>
 				<div class="area nowrap">
 					<my-button image="visible1.png"
@@ -196,6 +203,7 @@ let MyBuilderCollection = {
 						@set="(...args) => columnSet(args[0],args[1])"
 						:builderLanguage="builderLanguage"
 						:column="columnShow"
+						:columns="columns"
 						:hasCaptions="true"
 						:moduleId="module.id"
 						:onlyData="true"
@@ -348,6 +356,7 @@ let MyBuilderCollection = {
 		getCollectionConsumerTemplate,
 		getItemTitleColumn,
 		getNilUuid,
+		getSqlExplain,
 		
 		// actions
 		collectionAdd() {
@@ -8,6 +8,7 @@ import {
 	isAttributeString,
 	isAttributeUuid
 } from '../shared/attribute.js';
+import {getItemTitleColumn} from '../shared/builder.js';
 import {
 	getCaptionByIndexAttributeId
 } from '../shared/query.js';
@@ -188,6 +189,105 @@ let MyBuilderColumnOptions = {
 					</select>
 				</td>
 			</tr>
+			<tr v-if="isDate">
+				<td>{{ capApp.dateBucket }}</td>
+				<td>
+					<div class="row gap centered">
+						<select
+							@input="set('dateBucket',$event.target.value)"
+							:value="column.dateBucket"
+						>
+							<option value="">-</option>
+							<option v-for="b in dateBuckets" :value="b">{{ capApp.option.dateBucket[b] }}</option>
+						</select>
+						<my-button image="question.png"
+							@trigger="showHelp(capApp.dateBucketHelp)"
+							:naked="true"
+						/>
+					</div>
+				</td>
+			</tr>
+			<tr v-if="!isSubQuery">
+				<td>{{ capApp.window }}</td>
+				<td>
+					<div class="row gap centered">
+						<select
+							@input="set('window',$event.target.value)"
+							:value="column.window"
+						>
+							<option value="">-</option>
+							<option v-for="w in windows" :value="w">{{ capApp.option.window[w] }}</option>
+						</select>
+						<my-button image="question.png"
+							@trigger="showHelp(capApp.windowHelp)"
+							:naked="true"
+						/>
+					</div>
+				</td>
+			</tr>
+			<template v-if="!isSubQuery && column.window !== null && columns.length !== 0">
+				<tr v-if="['lag','lead'].includes(column.window)">
+					<td>{{ capApp.windowOffset }}</td>
+					<td>
+						<input class="short"
+							@change="setInt('windowOffset',$event.target.value,false)"
+							:placeholder="1"
+							:value="column.windowOffset !== 0 ? column.windowOffset : ''"
+						/>
+					</td>
+				</tr>
+				<tr>
+					<td>{{ capApp.windowPartition }}</td>
+					<td>
+						<div class="column gap">
+							<span v-if="windowColumnPositions.length === 0"><i>-</i></span>
+							<my-button-check
+								v-for="p in windowColumnPositions"
+								@update:modelValue="windowPartitionToggle(p)"
+								:caption="getItemTitleColumn(columns[p],true)"
+								:modelValue="column.windowPartition.includes(p)"
+							/>
+						</div>
+					</td>
+				</tr>
+				<tr>
+					<td>{{ capApp.windowOrders }}</td>
+					<td>
+						<div class="column gap">
+							<div class="row gap centered" v-for="(o,i) in column.windowOrders">
+								<select
+									@change="windowOrderSet(i,'columnPos',parseInt($event.target.value))"
+									:value="o.columnPos"
+								>
+									<option v-for="p in windowColumnPositions" :value="p">
+										{{ getItemTitleColumn(columns[p],true) }}
+									</option>
+								</select>
+								<select
+									@change="windowOrderSet(i,'ascending',$event.target.value === 'true')"
+									:value="o.ascending ? 'true' : 'false'"
+								>
+									<option value="true">{{ capGen.option.sortAsc }}</option>
+									<option value="false">{{ capGen.option.sortDesc }}</option>
+								</select>
+								<my-button image="cancel.png"
+									@trigger="windowOrderRemove(i)"
+									:naked="true"
+								/>
+							</div>
+							<div class="row gap centered">
+								<my-button image="add.png"
+									@trigger="windowOrderAdd"
+									:active="windowColumnPositions.length !== 0"
+									:caption="capGen.button.add"
+									:naked="true"
+								/>
+								<span v-if="column.windowOrders.length === 0"><i>{{ capApp.windowOrdersHint }}</i></span>
+							</div>
+						</div>
+					</td>
+				</tr>
+			</template>
 			<tr>
 				<td>{{ capGen.options }}</td>
 				<td>
@@ -224,6 +324,7 @@ let MyBuilderColumnOptions = {
 	props:{
 		builderLanguage:{ type:String,  required:true },
 		column:         { type:Object,  required:true },
+		columns:        { type:Array,   required:false, default:() => [] }, // all columns of parent, for window options
 		hasCaptions:    { type:Boolean, required:true },
 		moduleId:       { type:String,  required:true },
 		onlyData:       { type:Boolean, required:true }  // no display/formatting options
@@ -255,9 +356,12 @@ let MyBuilderColumnOptions = {
 		},
 		
 		// simple
+		dateBuckets:(s) => ['hour','day','week','month','quarter','year'],
+		windows:    (s) => ['rank','denseRank','percentRank','rowNumber','lag','lead','runningSum','runningAvg','runningCount','runningMin','runningMax'],
 		isBarcode: (s) => s.isString  && s.attribute.contentUse === 'barcode',
 		isBoolean: (s) => s.isAttributeBoolean(s.attribute.content),
 		isColor:   (s) => s.isString  && s.attribute.contentUse === 'color',
+		isDate:    (s) => s.isInteger && ['date','datetime'].includes(s.attribute.contentUse),
 		isDrawing: (s) => s.isString  && s.attribute.contentUse === 'drawing',
 		isFiles:   (s) => s.isAttributeFiles(s.attribute.content),
 		isInteger: (s) => s.isAttributeInteger(s.attribute.content),
@@ -265,6 +369,10 @@ let MyBuilderColumnOptions = {
 		isSubQuery:(s) => s.column.subQuery,
 		isUuid:    (s) => s.isAttributeUuid(s.attribute.content),
 		
+		// positions of other columns, that windows can be partitioned/ordered by
+		windowColumnPositions:(s) => s.columns.map((c,i) => i).filter(i => s.columns[i].id !== s.column.id
+			&& !s.columns[i].subQuery && s.columns[i].window === null),
+		
 		// stores
 		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
 		capApp:        (s) => s.$store.getters.captions.builder.form,
@@ -273,6 +381,7 @@ let MyBuilderColumnOptions = {
 	methods:{
 		// externals
 		getCaptionByIndexAttributeId,
+		getItemTitleColumn,
 		getIndexAttributeIdsByJoins,
 		isAttributeBoolean,
 		isAttributeFiles,
@@ -285,6 +394,35 @@ let MyBuilderColumnOptions = {
 			if(val === '') val = null;
 			this.$emit('set',name,val);
 		},
+		showHelp(help) {
+			this.$store.commit('dialog',{
+				captionBody:help,
+				captionTop:this.capGen.contextHelp,
+				image:'question.png'
+			});
+		},
+		windowOrderAdd() {
+			let orders = JSON.parse(JSON.stringify(this.column.windowOrders));
+			orders.push({ columnPos:this.windowColumnPositions[0], ascending:true });
+			this.set('windowOrders',orders);
+		},
+		windowOrderRemove(i) {
+			let orders = JSON.parse(JSON.stringify(this.column.windowOrders));
+			orders.splice(i,1);
+			this.set('windowOrders',orders);
+		},
+		windowOrderSet(i,name,value) {
+			let orders = JSON.parse(JSON.stringify(this.column.windowOrders));
+			orders[i][name] = value;
+			this.set('windowOrders',orders);
+		},
+		windowPartitionToggle(pos) {
+			let partition = JSON.parse(JSON.stringify(this.column.windowPartition));
+			const i = partition.indexOf(pos);
+			if(i === -1) partition.push(pos);
+			else         partition.splice(i,1);
+			this.set('windowPartition',partition.sort((a,b) => a - b));
+		},
 		setInt(name,val,allowNull) {
 			if(val !== '')
 				return this.$emit('set',name,parseInt(val));
@@ -317,7 +317,12 @@ export let MyBuilderColumnTemplates = {
 				display:'default',
 				groupBy:false,
 				aggregator:null,
+				dateBucket:null,
 				distincted:false,
+				window:null,
+				windowOffset:0,
+				windowOrders:[],
+				windowPartition:[],
 				subQuery:subQuery,
 				query:this.getQueryTemplate(),
 				hidden:false,
@@ -21,6 +21,7 @@ import {
 	getFieldHasQuery,
 	getFormEntityMapRef,
 	getItemTitleColumn,
+	getSqlExplain,
 	getSqlPreview
 } from '../shared/builder.js';
 import {
@@ -455,20 +456,26 @@ let MyBuilderForm = {
 							@set-choices="fieldQuerySet('choices',$event)"
 							@set-filters="fieldQuerySet('filters',$event)"
 							@set-fixed-limit="fieldQuerySet('fixedLimit',$event)"
+							@set-grouping="fieldQuerySet('grouping',$event)"
+							@set-grouping-sets="fieldQuerySet('groupingSets',$event)"
 							@set-joins="fieldQuerySet('joins',$event)"
 							@set-lookups="fieldQuerySet('lookups',$event)"
 							@set-orders="fieldQuerySet('orders',$event)"
 							@set-relation-id="fieldQuerySet('relationId',$event)"
+							:allowGrouping="['chart','list'].includes(fieldShow.content)"
 							:allowLookups="fieldShow.content === 'list' && fieldShow.csvImport"
 							:allowOrders="true"
 							:builderLanguage="builderLanguage"
 							:choices="fieldShow.query.choices"
+							:columns="fieldShow.columns"
 							:entityIdMapRef="entityIdMapRef"
 							:fieldIdMap="fieldIdMap"
 							:filters="fieldShow.query.filters"
 							:filtersDisable="['formState','getter','globalSearch']"
 							:fixedLimit="fieldShow.query.fixedLimit"
 							:formId="id"
+							:grouping="fieldShow.query.grouping"
+							:groupingSets="fieldShow.query.groupingSets"
 							:joins="fieldShow.query.joins"
 							:moduleId="module.id"
 							:orders="fieldShow.query.orders"
@@ -483,6 +490,10 @@ let MyBuilderForm = {
 								@trigger="getSqlPreview(fieldShow.query,fieldShow.columns)"
 								:caption="capGen.sqlPreview"
 							/>
+							<my-button image="database.png"
+								@trigger="getSqlExplain(fieldShow.query,fieldShow.columns)"
+								:caption="capGen.sqlExplain"
+							/>
 						</div>
 
 						<!-- column templates query fields -->
@@ -534,6 +545,7 @@ let MyBuilderForm = {
 					@set="(...args) => fieldColumnPropertySet(args[0],args[1])"
 					:builderLanguage="builderLanguage"
 					:column="columnShow"
+					:columns="fieldShow.columns"
 					:hasCaptions="fieldShow.content === 'list'"
 					:moduleId="module.id"
 					:onlyData="false"
@@ -794,6 +806,7 @@ let MyBuilderForm = {
 		getJoinIndexMap,
 		getNilUuid,
 		getQueryTemplate,
+		getSqlExplain,
 		getSqlPreview,
 		isAttributeBoolean,
 		isAttributeRelationship,
@@ -403,6 +403,9 @@ let MyBuilderNew = {
 						encryption:this.inputs.encryption,
 						retentionCount:null,
 						retentionDays:null,
+						softDelete:false,
+						softDeleteDays:null,
+						recordLock:null,
 						policies:[]
 					};
 				break;
@@ -1,6 +1,7 @@
 import MyBuilderCaption    from './builderCaption.js';
 import MyBuilderPgTriggers from './builderPgTriggers.js';
 import MyCodeEditor        from '../codeEditor.js';
+import MyInputDateWrap     from '../inputDateWrap.js';
 import MyTabs              from '../tabs.js';
 import {
 	getAttributeIcon,
@@ -19,18 +20,28 @@ export {MyBuilderPgFunction as default};
 
 let MyBuilderPgFunctionItemSchedule = {
 	name:'my-builder-pg-function-item-schedule',
+	components:{ MyInputDateWrap },
 	template:`<div class="schedule">
 		
 		<div class="line">
 			<!-- interval at which to run -->
-			<select class="dynamic" v-model="runOnce" :disabled="readonly">
+			<select class="dynamic" v-model="runType" :disabled="readonly">
 				<optgroup :label="capApp.runType">
-					<option :value="true">{{ capApp.runOnce }}</option>
-					<option :value="false">{{ capApp.runRegular }}</option>
+					<option value="once">{{ capApp.runOnce }}</option>
+					<option value="regular">{{ capApp.runRegular }}</option>
+					<option value="cron">{{ capApp.runCron }}</option>
 				</optgroup>
 			</select>
 			
-			<template v-if="intervalType !== 'once'">
+			<!-- cron expression -->
+			<input class="cron" placeholder="0 6 1W * *"
+				v-if="intervalType === 'cron'"
+				v-model="cron"
+				:disabled="readonly"
+				:title="capApp.cronHint"
+			/>
+			
+			<template v-if="!['cron','once'].includes(intervalType)">
 				<span>{{ capApp.intervalEvery }}</span>
 				<input class="dynamic" v-model.number="intervalValue" :disabled="readonly" />
 				
@@ -71,6 +82,54 @@ let MyBuilderPgFunctionItemSchedule = {
 				:naked="true"
 			/>
 		</div>
+		
+		<!-- time zone to evaluate schedule in -->
+		<div class="line" v-if="intervalType !== 'once'">
+			<span>{{ capApp.timeZone }}</span>
+			<input class="time-zone" list="builder-function-time-zones"
+				v-model="timeZone"
+				:disabled="readonly"
+				:placeholder="capApp.timeZoneServer"
+				:title="capApp.timeZoneHint"
+			/>
+			<datalist id="builder-function-time-zones">
+				<option v-for="z in timeZones" :value="z" />
+			</datalist>
+		</div>
+		
+		<!-- blackout windows, days without executions -->
+		<div class="line" :title="capApp.blackoutsHint">
+			<span>{{ capApp.blackouts }}</span>
+			<my-button image="add.png"
+				@trigger="blackoutAdd"
+				:active="!readonly"
+				:captionTitle="capApp.blackoutAdd"
+				:naked="true"
+			/>
+		</div>
+		<div class="line" v-for="(b,i) in blackouts">
+			<my-input-date-wrap
+				@set-unix-from="blackoutSet(i,'dateFrom',$event)"
+				@set-unix-to="blackoutSet(i,'dateTo',$event)"
+				:isDate="true"
+				:isRange="true"
+				:isReadonly="readonly"
+				:isTime="false"
+				:unixFrom="b.dateFrom"
+				:unixTo="b.dateTo"
+			/>
+			<my-bool
+				@update:modelValue="blackoutSet(i,'yearly',$event)"
+				:modelValue="b.yearly"
+				:readonly="readonly"
+			/>
+			<span>{{ capApp.blackoutYearly }}</span>
+			<my-button image="delete.png"
+				@trigger="blackoutRemove(i)"
+				:active="!readonly"
+				:naked="true"
+			/>
+		</div>
 	</div>`,
 	props:{
 		modelValue:{ type:Object,  required:true },
@@ -103,18 +162,57 @@ let MyBuilderPgFunctionItemSchedule = {
 			get()  { return this.modelValue.intervalValue; },
 			set(v) { this.update('intervalValue',v); }
 		},
-		runOnce:{
-			get()  { return this.intervalType === 'once'; },
+		blackouts:{
+			get()  { return this.modelValue.blackouts; },
+			set(v) { this.update('blackouts',v); }
+		},
+		cron:{
+			get()  { return this.modelValue.cron !== null ? this.modelValue.cron : ''; },
+			set(v) { this.update('cron',v); }
+		},
+		runType:{
+			get() {
+				return ['cron','once'].includes(this.intervalType) ? this.intervalType : 'regular';
+			},
 			set(v) {
-				if(v) this.update('intervalType','once');
-				else  this.update('intervalType','days');
+				switch(v) {
+					case 'cron':    this.update('intervalType','cron'); break;
+					case 'once':    this.update('intervalType','once'); break;
+					case 'regular': this.update('intervalType','days'); break;
+				}
 			}
 		},
+		timeZone:{
+			get()  { return this.modelValue.timeZone !== null ? this.modelValue.timeZone : ''; },
+			set(v) { this.update('timeZone',v !== '' ? v : null); }
+		},
+		
+		// simple
+		timeZones:(s) => typeof Intl.supportedValuesOf === 'function' ? Intl.supportedValuesOf('timeZone') : [],
 		
 		// stores
 		capApp:(s) => s.$store.getters.captions.builder.function
 	},
 	methods:{
+		blackoutAdd() {
+			// dates at UTC midnight
+			let today = new Date();
+			let unix  = Math.floor(Date.UTC(today.getFullYear(),today.getMonth(),today.getDate()) / 1000);
+			
+			let v = JSON.parse(JSON.stringify(this.blackouts));
+			v.push({ dateFrom:unix, dateTo:unix, yearly:false });
+			this.blackouts = v;
+		},
+		blackoutRemove(i) {
+			let v = JSON.parse(JSON.stringify(this.blackouts));
+			v.splice(i,1);
+			this.blackouts = v;
+		},
+		blackoutSet(i,name,value) {
+			let v = JSON.parse(JSON.stringify(this.blackouts));
+			v[i][name] = value;
+			this.blackouts = v;
+		},
 		update(name,value) {
 			let v = JSON.parse(JSON.stringify(this.modelValue));
 			v[name] = value;
@@ -635,7 +733,7 @@ let MyBuilderPgFunction = {
 				'abort_show_message','clean_up_e2ee_keys','file_export','file_export_text','file_import',
 				'file_import_text','file_link','file_text_read','file_text_write','file_unlink','files_get',
 				'get_e2ee_data_key_enc','get_language_code','get_name','get_public_hostname','get_role_ids',
-				'get_user_id','has_role','has_role_any','log_error','log_info','log_warning','mail_delete',
+				'get_user_id','has_role','has_role_any','job_enqueue','log_error','log_info','log_warning','mail_delete',
 				'mail_delete_after_attach','mail_get_next','mail_send','rest_call','update_collection',
 				'user_meta_set','user_sync_all'
 			],
@@ -767,7 +865,10 @@ let MyBuilderPgFunction = {
 				atHour:12,
 				atDay:1,
 				intervalType:'days',
-				intervalValue:3
+				intervalValue:3,
+				cron:null,
+				timeZone:null,
+				blackouts:[]
 			});
 		},
 		reset() {
@@ -3,6 +3,7 @@ import {getNilUuid}     from '../shared/generic.js';
 import {
 	builderOptionGet,
 	builderOptionSet,
+	getItemTitleColumn,
 	getItemTitleRelation
 } from '../shared/builder.js';
 import {
@@ -868,22 +869,69 @@ const MyBuilderQuery = {
 				v-model.number="fixedLimitInput"
 			/>
 		</div>
+		
+		<!-- grouping -->
+		<div class="query-component" v-if="expertMode && allowGrouping && joins.length !== 0">
+			<div class="query-title">
+				<my-button
+					:active="false"
+					:caption="capApp.grouping"
+					:large="true"
+					:naked="true"
+				/>
+				<div class="row centered gap default-inputs">
+					<my-button image="question.png" @trigger="showGroupingHelp" />
+					<select v-model="groupingInput">
+						<option :value="null">{{ capApp.option.grouping.none }}</option>
+						<option v-for="g in ['rollup','cube','sets']" :value="g">{{ capApp.option.grouping[g] }}</option>
+					</select>
+				</div>
+			</div>
+			
+			<span v-if="groupingInput !== null && groupByColumnPositions.length === 0"><i>{{ capApp.groupingNoColumns }}</i></span>
+			<template v-if="groupingInput === 'sets' && groupByColumnPositions.length !== 0">
+				<div class="row gap centered wrap" v-for="(set,i) in groupingSetsInput">
+					<span>{{ capApp.groupingSet.replace('{NR}',i+1) }}</span>
+					<my-button-check
+						v-for="p in groupByColumnPositions"
+						@update:modelValue="groupingSetToggle(i,p)"
+						:caption="getItemTitleColumn(columns[p],true)"
+						:modelValue="set.includes(p)"
+					/>
+					<my-button image="cancel.png"
+						@trigger="groupingSetRemove(i)"
+						:naked="true"
+					/>
+				</div>
+				<div class="row">
+					<my-button image="add.png"
+						@trigger="groupingSetAdd"
+						:caption="capGen.button.add"
+						:naked="true"
+					/>
+				</div>
+			</template>
+		</div>
 	</div>`,
 	props:{
 		allowChoices:   { type:Boolean, required:false, default:true },
 		allowFilters:   { type:Boolean, required:false, default:true },
 		allowFixedLimit:{ type:Boolean, required:false, default:true },
+		allowGrouping:  { type:Boolean, required:false, default:false },
 		allowJoinEdit:  { type:Boolean, required:false, default:true },
 		allowLookups:   { type:Boolean, required:false, default:false },
 		allowOrders:    { type:Boolean, required:false, default:false },
 		builderLanguage:{ type:String,  required:false, default:'' },
 		choices:        { type:Array,   required:false, default:() => [] },          // choices for optional query filters (selectable by users)
+		columns:        { type:Array,   required:false, default:() => [] },          // columns of query consumer, for grouping
 		entityIdMapRef: { type:Object,  required:false, default:() => {return {}} },
 		fieldIdMap:     { type:Object,  required:false, default:() => {return {}} }, // form field map, key: field ID
 		filters:        { type:Array,   required:true },
 		filtersDisable: { type:Array,   required:false, default:() => [] }, // filter content to disable (attribute, javascript, collection, preset, ...)
 		fixedLimit:     { type:Number,  required:true },
 		formId:         { type:String,  required:false, default:'' },       // ID of form in which context the query is used
+		grouping:       { required:false, default:null },                   // grouping of GROUP BY columns (rollup, cube, sets)
+		groupingSets:   { type:Array,   required:false, default:() => [] }, // grouping sets, positions of GROUP BY columns
 		lookups:        { type:Array,   required:false, default:() => [] },
 		joins:          { type:Array,   required:true },                    // available relations, incl. source relation
 		joinsParents:   { type:Array,   required:false, default:() => [] }, // each item is an array of joins from a parent query
@@ -893,8 +941,8 @@ const MyBuilderQuery = {
 		relationIdStart:{ required:false, default:null }                    // when query starts with a defined relation
 	},
 	emits:[
-		'index-removed','set-choices','set-filters','set-fixed-limit',
-		'set-joins','set-lookups','set-orders','set-relation-id'
+		'index-removed','set-choices','set-filters','set-fixed-limit','set-grouping',
+		'set-grouping-sets','set-joins','set-lookups','set-orders','set-relation-id'
 	],
 	data() {
 		return {
@@ -919,6 +967,18 @@ const MyBuilderQuery = {
 			get()  { return this.fixedLimit; },
 			set(v) { this.$emit('set-fixed-limit',v === '' ? 0 : v); }
 		},
+		groupingInput:{
+			get()  { return this.grouping === undefined ? null : this.grouping; },
+			set(v) {
+				this.$emit('set-grouping',v);
+				if(v !== 'sets' && this.groupingSetsInput.length !== 0)
+					this.groupingSetsInput = [];
+			}
+		},
+		groupingSetsInput:{
+			get()  { return this.groupingSets === undefined ? [] : this.groupingSets; },
+			set(v) { this.$emit('set-grouping-sets',v); }
+		},
 		joinsInput:{
 			get()  { return this.joins; },
 			set(v) { this.$emit('set-joins',v); }
@@ -1002,6 +1062,9 @@ const MyBuilderQuery = {
 			};
 		},
 		
+		// positions of GROUP BY columns, grouping sets refer to them
+		groupByColumnPositions:(s) => s.columns.map((c,i) => i).filter(i => s.columns[i].groupBy && !s.columns[i].subQuery),
+		
 		// entities, simple
 		module:  (s) => s.moduleIdMap[s.moduleId]     === undefined ? false : s.moduleIdMap[s.moduleId],
 		relation:(s) => s.relationIdMap[s.relationId] === undefined ? false : s.relationIdMap[s.relationId],
@@ -1021,6 +1084,7 @@ const MyBuilderQuery = {
 		builderOptionGet,
 		builderOptionSet,
 		getDependentModules,
+		getItemTitleColumn,
 		getNilUuid,
 		
 		// presentation
@@ -1063,6 +1127,24 @@ const MyBuilderQuery = {
 			this.choicesInput.splice(i,1);
 			this.choicesInput = this.choicesInput;
 		},
+		groupingSetAdd() {
+			let sets = JSON.parse(JSON.stringify(this.groupingSetsInput));
+			sets.push([]);
+			this.groupingSetsInput = sets;
+		},
+		groupingSetRemove(i) {
+			let sets = JSON.parse(JSON.stringify(this.groupingSetsInput));
+			sets.splice(i,1);
+			this.groupingSetsInput = sets;
+		},
+		groupingSetToggle(i,pos) {
+			let sets = JSON.parse(JSON.stringify(this.groupingSetsInput));
+			const p  = sets[i].indexOf(pos);
+			if(p === -1) sets[i].push(pos);
+			else         sets[i].splice(p,1);
+			sets[i].sort((a,b) => a - b);
+			this.groupingSetsInput = sets;
+		},
 		orderAdd() {
 			this.ordersInput.push({
 				ascending:true,
@@ -1081,6 +1163,13 @@ const MyBuilderQuery = {
 				image:'question.png'
 			});
 		},
+		showGroupingHelp() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.groupingHelp,
+				captionTop:this.capGen.help,
+				image:'question.png'
+			});
+		},
 		showLookupHelp() {
 			this.$store.commit('dialog',{
 				captionBody:this.capApp.lookupsHelp,
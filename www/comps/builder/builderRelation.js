@@ -2,6 +2,7 @@ import MyBuilderAttribute    from './builderAttribute.js';
 import MyBuilderPreset       from './builderPreset.js';
 import MyBuilderPgIndex      from './builderPgIndex.js';
 import MyBuilderPgTriggers   from './builderPgTriggers.js';
+import MyBuilderWebhooks     from './builderWebhooks.js';
 import MyBuilderPresets      from './builderPresets.js';
 import MyInputOffset         from '../inputOffset.js';
 import MyTabs                from '../tabs.js';
@@ -137,6 +138,7 @@ const MyBuilderRelation = {
 		MyBuilderPreset,
 		MyBuilderPgIndex,
 		MyBuilderPgTriggers,
+		MyBuilderWebhooks,
 		MyBuilderPresets,
 		MyBuilderRelationsItemPolicy,
 		MyInputOffset,
@@ -238,6 +240,35 @@ const MyBuilderRelation = {
 								</td>
 								<td>{{ capApp.retentionHint }}</td>
 							</tr>
+							<tr>
+								<td>{{ capApp.softDelete }}</td>
+								<td>
+									<table>
+										<tbody>
+											<tr>
+												<td>{{ capApp.softDeleteActive }}</td>
+												<td><my-bool v-model="softDelete" :readonly="readonly" /></td>
+											</tr>
+											<tr v-if="softDelete">
+												<td>{{ capApp.softDeleteDays }}</td>
+												<td><input v-model.number="softDeleteDays" :disabled="readonly" :placeholder="capApp.softDeleteDaysHint" /></td>
+											</tr>
+										</tbody>
+									</table>
+								</td>
+								<td>{{ capApp.softDeleteHint }}</td>
+							</tr>
+							<tr>
+								<td>{{ capApp.recordLock }}</td>
+								<td>
+									<select v-model="recordLock" :disabled="readonly">
+										<option :value="null">{{ capApp.option.recordLock.none }}</option>
+										<option value="optimistic">{{ capApp.option.recordLock.optimistic }}</option>
+										<option value="pessimistic">{{ capApp.option.recordLock.pessimistic }}</option>
+									</select>
+								</td>
+								<td>{{ capApp.recordLockHint }}</td>
+							</tr>
 						</tbody>
 					</table>
 				</div>
@@ -247,7 +278,7 @@ const MyBuilderRelation = {
 		<div class="content no-padding builder-relation">
 			<my-tabs
 				v-model="tabTarget"
-				:entries="['attributes','indexes','triggers','presets','policies','relationships','data']"
+				:entries="['attributes','indexes','triggers','webhooks','presets','policies','relationships','data']"
 				:entriesText="tabCaptions"
 			/>
 			
@@ -286,6 +317,12 @@ const MyBuilderRelation = {
 						:captionTitle="capApp.attributeEncrypted"
 						:naked="true"
 					/>
+					<my-button image="code.png"
+						v-if="atr.generated !== null"
+						:active="false"
+						:captionTitle="capApp.attributeGenerated"
+						:naked="true"
+					/>
 					<my-button
 						v-if="isAttributeWithLength(atr.content) && atr.length !== 0"
 						:active="false"
@@ -396,6 +433,14 @@ const MyBuilderRelation = {
 				/>
 			</div>
 			
+			<!-- webhooks -->
+			<div class="tab-content" v-if="tabTarget === 'webhooks'">
+				<my-builder-webhooks
+					:readonly="readonly"
+					:relationId="relation.id"
+				/>
+			</div>
+			
 			<!-- presets -->
 			<div class="tab-content" v-if="tabTarget === 'presets'">
 				<my-builder-presets
@@ -538,8 +583,11 @@ const MyBuilderRelation = {
 			indexIdEdit:false,
 			name:'',
 			policies:[],
+			recordLock:null,
 			retentionCount:null,
 			retentionDays:null,
+			softDelete:false,
+			softDeleteDays:null,
 			
 			// states
 			nameFilter:'',
@@ -561,14 +609,17 @@ const MyBuilderRelation = {
 	computed:{
 		tabCaptions:(s) => {
 			let triggerCnt = 0;
+			let webhookCnt = 0;
 			for(const mod of s.modules) {
 				triggerCnt += mod.pgTriggers.filter(trg => trg.relationId === s.id).length;
+				webhookCnt += mod.webhooks.filter(w => w.relationId === s.id).length;
 			}
 
 			return [
 				s.capApp.attributes.replace('{CNT}',s.relation.attributes.length),
 				s.capApp.indexes.replace('{CNT}',s.relation.indexes.length),
 				s.capApp.triggers.replace('{CNT}',triggerCnt),
+				s.capApp.webhooks.replace('{CNT}',webhookCnt),
 				s.capApp.presets.replace('{CNT}',s.relation.presets.length),
 				s.capApp.policies.replace('{CNT}',s.relation.policies.length),
 				s.capApp.graph,
@@ -663,6 +714,9 @@ const MyBuilderRelation = {
 			|| s.encryption               !== s.relation.encryption
 			|| s.retentionCount           !== s.relation.retentionCount
 			|| s.retentionDays            !== s.relation.retentionDays
+			|| s.softDelete               !== s.relation.softDelete
+			|| s.softDeleteDays           !== s.relation.softDeleteDays
+			|| s.recordLock               !== s.relation.recordLock
 			|| JSON.stringify(s.policies) !== JSON.stringify(s.relation.policies),
 		
 		// simple
@@ -747,6 +801,9 @@ const MyBuilderRelation = {
 			this.encryption     = this.relation.encryption;
 			this.retentionCount = this.relation.retentionCount;
 			this.retentionDays  = this.relation.retentionDays;
+			this.softDelete     = this.relation.softDelete;
+			this.softDeleteDays = this.relation.softDeleteDays;
+			this.recordLock     = this.relation.recordLock;
 			this.policies       = JSON.parse(JSON.stringify(this.relation.policies));
 			
 			if(this.tabTarget === 'data')
@@ -791,6 +848,24 @@ const MyBuilderRelation = {
 			);
 		},
 		set() {
+			// disabling soft delete permanently deletes records in trash bin
+			if(!this.relation.softDelete || this.softDelete)
+				return this.setDo();
+
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.softDeleteDisable,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.save,
+					exec:this.setDo,
+					image:'save.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		setDo() {
 			ws.send('relation','set',{
 				id:this.id,
 				moduleId:this.relation.moduleId,
@@ -799,6 +874,9 @@ const MyBuilderRelation = {
 				encryption:this.relation.encryption,
 				retentionCount:this.retentionCount === '' ? null : this.retentionCount,
 				retentionDays:this.retentionDays === '' ? null : this.retentionDays,
+				softDelete:this.softDelete,
+				softDeleteDays:!this.softDelete || this.softDeleteDays === '' ? null : this.softDeleteDays,
+				recordLock:this.recordLock,
 				policies:this.policies
 			},true).then(
 				() => {
@@ -50,6 +50,13 @@ let MyBuilderRelations = {
 						:captionTitle="capApp.retentionHint"
 						:naked="true"
 					/>
+					<my-button image="shred.png"
+						v-if="r.softDelete"
+						:active="false"
+						:caption="r.softDeleteDays !== null ? String(r.softDeleteDays) : ''"
+						:captionTitle="capApp.softDeleteHint"
+						:naked="true"
+					/>
 					<my-button image="files_list2.png"
 						v-if="r.attributes.length !== 0"
 						:active="false"
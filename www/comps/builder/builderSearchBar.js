@@ -6,6 +6,7 @@ import MyBuilderQuery         from './builderQuery.js';
 import MyTabs                 from '../tabs.js';
 import {
 	getItemTitleColumn,
+	getSqlExplain,
 	getSqlPreview
 } from '../shared/builder.js';
 import {
@@ -151,6 +152,10 @@ let MyBuilderSearchBar = {
 						@trigger="getSqlPreview(searchBar.query,searchBar.columns)"
 						:caption="capGen.sqlPreview"
 					/>
+					<my-button image="database.png"
+						@trigger="getSqlExplain(searchBar.query,searchBar.columns)"
+						:caption="capGen.sqlExplain"
+					/>
 				</div>
 				
 				<!-- no global search input warning -->
@@ -341,6 +346,7 @@ let MyBuilderSearchBar = {
 		getItemTitleColumn,
 		getJoinIndexMap,
 		getNilUuid,
+		getSqlExplain,
 		getSqlPreview,
 		
 		// actions
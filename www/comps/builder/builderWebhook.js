@@ -0,0 +1,225 @@
+import {getDependentModules} from '../shared/builder.js';
+import {copyValueDialog}     from '../shared/generic.js';
+export {MyBuilderWebhook as default};
+
+let MyBuilderWebhook = {
+	name:'my-builder-webhook',
+	template:`<div class="app-sub-window under-header" @mousedown.self="$emit('close')">
+		<div class="contentBox builder-webhook float" v-if="values !== null">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/globe.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						@trigger="reset"
+						:active="hasChanges"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="visible1.png"
+						@trigger="copyValueDialog(values.name,id,id)"
+						:active="!isNew"
+						:caption="capGen.id"
+					/>
+					<my-button image="delete.png"
+						@trigger="delAsk"
+						:active="!isNew && !readonly"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+
+			<div class="content default-inputs no-padding">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="values.name" v-focus :disabled="readonly" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.url }}*</td>
+							<td><input v-model="values.url" :disabled="readonly" :placeholder="capApp.urlHint" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.api }}</td>
+							<td>
+								<div class="column gap">
+									<select v-model="values.apiId" :disabled="readonly">
+										<option :value="null">[{{ capApp.apiNone }}]</option>
+										<template v-for="mod in getDependentModules(module)">
+											<option
+												v-for="api in mod.apis.filter(v => v.query.relationId === relationId)"
+												:value="api.id"
+											>{{ mod.id !== module.id ? mod.name + ': ' : '' }}{{ api.name }} (v{{ api.version }})</option>
+										</template>
+									</select>
+									<span class="subtitle">{{ capApp.apiHint }}</span>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>
+								<div class="row gap centered">
+									<my-button image="recordCreate.png" :active="false" :naked="true" />
+									<span>{{ capApp.onInsert }}</span>
+								</div>
+							</td>
+							<td><my-bool v-model="values.onInsert" :readonly="readonly" /></td>
+						</tr>
+						<tr>
+							<td>
+								<div class="row gap centered">
+									<my-button image="recordUpdate.png" :active="false" :naked="true" />
+									<span>{{ capApp.onUpdate }}</span>
+								</div>
+							</td>
+							<td><my-bool v-model="values.onUpdate" :readonly="readonly" /></td>
+						</tr>
+						<tr>
+							<td>
+								<div class="row gap centered">
+									<my-button image="recordDelete.png" :active="false" :naked="true" />
+									<span>{{ capApp.onDelete }}</span>
+								</div>
+							</td>
+							<td><my-bool v-model="values.onDelete" :readonly="readonly" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.skipVerify }}</td>
+							<td><my-bool v-model="values.skipVerify" :readonly="readonly" /></td>
+						</tr>
+						<tr>
+							<td>{{ capGen.comments }}</td>
+							<td>
+								<textarea class="dynamic"
+									v-model="values.comment"
+									:disabled="readonly"
+								></textarea>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		id:        { required:true },
+		readonly:  { type:Boolean, required:true },
+		relationId:{ type:String,  required:true }
+	},
+	emits:['close'],
+	data() {
+		return {
+			values:null,
+			valuesOrg:null
+		};
+	},
+	computed:{
+		// simple
+		canSave:   (s) => s.values !== null && s.values.name !== '' && s.values.url !== '' && s.hasChanges && !s.readonly,
+		hasChanges:(s) => JSON.stringify(s.values) !== JSON.stringify(s.valuesOrg),
+		isNew:     (s) => s.id === null,
+
+		// stores
+		module:       (s) => s.moduleIdMap[s.relationIdMap[s.relationId].moduleId],
+		moduleIdMap:  (s) => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap:(s) => s.$store.getters['schema/relationIdMap'],
+		webhookIdMap: (s) => s.$store.getters['schema/webhookIdMap'],
+		capApp:       (s) => s.$store.getters.captions.builder.webhook,
+		capGen:       (s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.reset();
+		window.addEventListener('keydown',this.handleHotkeys);
+	},
+	unmounted() {
+		window.removeEventListener('keydown',this.handleHotkeys);
+	},
+	methods:{
+		// externals
+		copyValueDialog,
+		getDependentModules,
+
+		// actions
+		handleHotkeys(e) {
+			if(e.ctrlKey && e.key === 's' && this.canSave) {
+				this.set();
+				e.preventDefault();
+			}
+			if(e.key === 'Escape') {
+				this.$emit('close');
+				e.preventDefault();
+			}
+		},
+		reset() {
+			this.values = this.id !== null
+				? JSON.parse(JSON.stringify(this.webhookIdMap[this.id]))
+				: {
+					id:null,
+					moduleId:this.module.id,
+					relationId:this.relationId,
+					apiId:null,
+					name:'',
+					comment:null,
+					url:'',
+					onDelete:false,
+					onInsert:true,
+					onUpdate:false,
+					skipVerify:false
+				};
+
+			this.valuesOrg = JSON.parse(JSON.stringify(this.values));
+		},
+
+		// backend calls
+		delAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.delete,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:this.del,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		del() {
+			ws.send('webhook','del',{id:this.id},true).then(
+				() => {
+					this.$root.schemaReload(this.module.id);
+					this.$emit('close');
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			ws.send('webhook','set',this.values,true).then(
+				() => {
+					this.$root.schemaReload(this.module.id);
+					this.$emit('close');
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -0,0 +1,82 @@
+import MyBuilderWebhook from './builderWebhook.js';
+export {MyBuilderWebhooks as default};
+
+let MyBuilderWebhooks = {
+	name:'my-builder-webhooks',
+	components:{MyBuilderWebhook},
+	template:`<div class="generic-entry-list">
+		<div class="entry"
+			v-if="!readonly"
+			@click="idEdit = null"
+			:class="{ clickable:!readonly }"
+		>
+			<div class="row gap centered">
+				<img class="icon" src="images/add.png" />
+				<span>{{ capGen.button.new }}</span>
+			</div>
+		</div>
+
+		<div class="entry clickable"
+			@click="idEdit = w.id"
+			v-for="w in webhooks"
+		>
+			<my-button image="globe.png"
+				:active="false"
+				:naked="true"
+			/>
+			<div class="lines">
+				<span>{{ w.name }}</span>
+				<span class="subtitle">{{ w.url }}</span>
+			</div>
+			<my-button image="recordCreate.png"
+				v-if="w.onInsert"
+				:active="false"
+				:captionTitle="capApp.onInsert"
+				:naked="true"
+			/>
+			<my-button image="recordUpdate.png"
+				v-if="w.onUpdate"
+				:active="false"
+				:captionTitle="capApp.onUpdate"
+				:naked="true"
+			/>
+			<my-button image="recordDelete.png"
+				v-if="w.onDelete"
+				:active="false"
+				:captionTitle="capApp.onDelete"
+				:naked="true"
+			/>
+		</div>
+
+		<my-builder-webhook
+			v-if="idEdit !== false"
+			@close="idEdit = false"
+			:id="idEdit"
+			:readonly="readonly"
+			:relationId="relationId"
+		/>
+	</div>`,
+	props:{
+		readonly:  { type:Boolean, required:true },
+		relationId:{ type:String,  required:true }
+	},
+	data() {
+		return {
+			idEdit:false
+		};
+	},
+	computed:{
+		webhooks:(s) => {
+			let out = [];
+			for(const mod of s.modules) {
+				out = out.concat(mod.webhooks.filter(v => v.relationId === s.relationId));
+			}
+			return out.sort((a,b) => a.name.localeCompare(b.name));
+		},
+
+		// stores
+		modules:(s) => s.$store.getters['schema/modules'],
+		capApp: (s) => s.$store.getters.captions.builder.webhook,
+		capGen: (s) => s.$store.getters.captions.generic
+	}
+};
@@ -20,6 +20,7 @@ import {
 	getQueryExpressions,
 	getQueryExpressionsDateRange,
 	getQueryFiltersDateRange,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -427,6 +428,7 @@ const MyCalendar = {
 		isDays:    (s) => s.daysShow === 1 || s.daysShow === 3,
 		isMonth:   (s) => s.daysShow === 42,
 		isWeek:    (s) => s.daysShow === 5 || s.daysShow === 7,
+		relationIdsWatched:(s) => s.getQueryRelationIds(s.query), // relations of shown data, reloaded if changed by anyone
 		icsUrl:    (s) => `${location.protocol}//${location.host}/ics/download/cal.ics?field_id=${s.fieldId}&login_id=${s.loginId}&token_fixed=${s.icsToken}`,
 		
 		// start/end date of calendar
@@ -444,6 +446,7 @@ const MyCalendar = {
 		iconIdMap:     (s) => s.$store.getters['schema/iconIdMap'],
 		capApp:        (s) => s.$store.getters.captions.calendar,
 		capGen:        (s) => s.$store.getters.captions.generic,
+		dataChangedLast:(s) => s.$store.getters.dataChangedLast,
 		isMobile:      (s) => s.$store.getters.isMobile,
 		loginId:       (s) => s.$store.getters.loginId,
 		settings:      (s) => s.$store.getters.settings
@@ -481,8 +484,18 @@ const MyCalendar = {
 			// load initial route parameters
 			this.paramsUpdated(false);
 		}
+		
+		// reload when data of shown relations was changed
+		this.$watch('dataChangedLast',v => {
+			if(this.relationIdsWatched.includes(v.relationId))
+				this.get();
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
 		this.ready = true;
 	},
+	beforeUnmount() {
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
+	},
 	methods:{
 		// externals
 		checkDataOptions,
@@ -494,6 +507,7 @@ const MyCalendar = {
 		getQueryExpressions,
 		getQueryExpressionsDateRange,
 		getQueryFiltersDateRange,
+		getQueryRelationIds,
 		getRelationsJoined,
 		getUnixFromDate,
 		getWeek,
@@ -611,6 +625,7 @@ const MyCalendar = {
 			
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.getRelationsJoined(this.query.joins),
 				expressions:this.expressions,
 				filters:this.filters.concat(this.getQueryFiltersDateRange(
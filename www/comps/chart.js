@@ -1,6 +1,8 @@
 import {getCaption} from './shared/language.js';
 import {
 	getQueryExpressions,
+	getQueryGrouping,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -66,6 +68,7 @@ export default {
 		// simple
 		hasChoices:  (s) => s.choices.length > 1 && !s.hasOverwrite,
 		hasOverwrite:(s) => s.optionOverwrite !== null,
+		relationIdsWatched:(s) => s.getQueryRelationIds(s.query), // relations of shown data, reloaded if changed by anyone
 
 		// login options
 		choiceId:(s) => s.$root.getOrFallback(s.loginOptions,'choiceId',s.choices.length === 0 ? null : s.choices[0].id),
@@ -73,6 +76,7 @@ export default {
 		// stores
 		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
 		appResized:    (s) => s.$store.getters.appResized,
+		dataChangedLast:(s) => s.$store.getters.dataChangedLast,
 		settings:      (s) => s.$store.getters.settings
 	},
 	mounted() {
@@ -102,11 +106,23 @@ export default {
 			this.option = this.optionOverwrite;
 			this.ready  = true;
 		}
+		
+		// reload when data of shown relations was changed
+		this.$watch('dataChangedLast',v => {
+			if(this.relationIdsWatched.includes(v.relationId) && !this.isHidden)
+				this.get();
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
+	},
+	beforeUnmount() {
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
 	},
 	methods:{
 		// externals
 		getCaption,
 		getQueryExpressions,
+		getQueryGrouping,
+		getQueryRelationIds,
 		getRelationsJoined,
 		getUnixFormat,
 		getUnixShifted,
@@ -124,12 +140,16 @@ export default {
 			if(this.hasOverwrite)
 				return;
 
+			const grouping = this.getQueryGrouping(this.query,this.columns);
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.getRelationsJoined(this.query.joins),
 				expressions:this.getQueryExpressions(this.columns),
 				filters:this.filters,
 				orders:this.query.orders,
+				grouping:grouping.grouping,
+				groupingSets:grouping.groupingSets,
 				limit:this.limit
 			},true).then(
 				res => {
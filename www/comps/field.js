@@ -915,6 +915,10 @@ export default {
 			// readonly overwrite for 'visible' states
 			if(state !== 'hidden' && (s.logViewer || (s.formBlockInputs && (s.isData || s.isButton || s.isVariable))))
 				state = 'readonly';
+			
+			// generated attributes are computed by the database
+			if(state !== 'hidden' && s.isData && !s.isVariable && s.attribute.generated !== null)
+				state = 'readonly';
 
 			return state;
 		},
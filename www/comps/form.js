@@ -2,6 +2,7 @@ import MyArticles                    from './articles.js';
 import MyField                       from './field.js';
 import MyFormActions                 from './formActions.js';
 import MyFormLog                     from './formLog.js';
+import {hasAccessToRelation}         from './shared/access.js';
 import {getAttributeFileVersionHref} from './shared/attribute.js';
 import {getCollectionValues}         from './shared/collection.js';
 import {getColumnsProcessed}         from './shared/column.js';
@@ -50,6 +51,7 @@ import {
 	getJoinIndexMapExpanded,
 	getQueryAttributePkFilter,
 	getQueryFiltersProcessed,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -215,6 +217,13 @@ export default {
 						:caption="capApp.noAccess"
 						:cancel="true"
 					/>
+					<my-button image="lock.png"
+						v-if="recordLocksForeign.length !== 0"
+						@trigger="lockRecords"
+						:caption="capApp.recordLocked.replace('{NAME}',recordLocksForeign[0].loginName)"
+						:captionTitle="capApp.recordLockedHint"
+						:cancel="true"
+					/>
 				</div>
 				<my-form-actions
 					v-if="hasFormActions"
@@ -288,6 +297,7 @@ export default {
 		<my-form-log
 			v-if="showLog"
 			@close-log="toggleLog"
+			@reverted="get"
 			:entityIdMapEffect
 			:fieldIdMapData
 			:fieldIdMapProcessed
@@ -326,6 +336,13 @@ export default {
 	emits:['close','pop-up-replace','record-deleted','record-updated','records-open','refresh-parent'],
 	mounted() {
 		this.$watch('appResized',() => this.resized());
+		this.$watch('dataChangedLast',this.dataChanged);
+		this.$watch('recordLockLast',this.recordLockChanged);
+		this.$watch('relationIdsWatched',(v,vOld) => {
+			this.$store.commit('relationWatchersDel',vOld);
+			this.$store.commit('relationWatchersAdd',v);
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
 		this.$watch(() => [this.favoriteId,this.formId,this.recordIds],this.reset,{
 			immediate:true
 		});
@@ -343,6 +360,8 @@ export default {
 		
 		window.removeEventListener('keydown',this.handleHotkeys);
 		this.timerClearAll();
+		this.unlockRecords();
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
 	},
 	data() {
 		return {
@@ -379,6 +398,8 @@ export default {
 			},
 			indexMapRecordId:{},          // record IDs for form, key: relation index
 			indexMapRecordKey:{},         // record en-/decryption keys, key: relation index
+			indexMapRecordLock:{},        // record locks (pessimistic lock relations), key: relation index
+			indexMapRecordVersion:{},     // record versions when loaded (optimistic lock relations), key: relation index
 			indexesNoDel:[],              // relation indexes with no DEL permission (via relation policy)
 			indexesNoSet:[],              // relation indexes with no SET permission (via relation policy)
 			loginIdsEncryptFor:[],        // login IDs for which data keys are encrypted (e2ee), for current form relations/records
@@ -512,12 +533,19 @@ export default {
 		formStateIdMap: (s) => s.getFormStateIdMap(s.form.states),
 		joins:          (s) => s.fillRelationRecordIds(s.form.query.joins),
 		relationId:     (s) => s.form.query.relationId,
+		relationIdsWatched:(s) => s.getQueryRelationIds(s.form.query), // relations of loaded records, reloaded if changed by anyone
 		relationsJoined:(s) => s.getRelationsJoined(s.joins),
-		joinsIndexMap:  (s) => s.getJoinIndexMapExpanded(s.joins,s.indexMapRecordId,s.indexesNoDel,s.indexesNoSet,s.entityIdMapEffect.form.data),
+		joinsIndexMap:  (s) => s.getJoinIndexMapExpanded(s.joins,s.indexMapRecordId,
+			s.indexesNoDel.concat(s.indexesLocked),s.indexesNoSet.concat(s.indexesLocked),s.entityIdMapEffect.form.data),
 		joinsIndexesCrt:(s) => { return Object.values(s.joinsIndexMap).filter(v => v.recordCreate); },
 		joinsIndexesDel:(s) => { return Object.values(s.joinsIndexMap).filter(v => v.recordDelete); },
 		joinsIndexesNew:(s) => { return Object.values(s.joinsIndexMap).filter(v => v.recordNew); },
 		joinsIndexesSet:(s) => { return Object.values(s.joinsIndexMap).filter(v => v.recordUpdate); },
+
+		// record locks
+		indexesLocked:     (s) => Object.keys(s.indexMapRecordLock).filter(k => !s.indexMapRecordLock[k].own).map(k => parseInt(k)),
+		recordLocksForeign:(s) => Object.values(s.indexMapRecordLock).filter(l => !l.own),
+		usesVersions:      (s) => s.joins.some(j => s.relationIdMap[j.relationId].recordLock === 'optimistic'),
 		iconSrc:(s) => {
 			if(s.favoriteId  !== null) return 'images/star1.png';
 			if(s.form.iconId !== null) return s.srcBase64(s.iconIdMap[s.form.iconId].file);
@@ -890,6 +918,7 @@ export default {
 		capErr:             (s) => s.$store.getters.captions.error,
 		capGen:             (s) => s.$store.getters.captions.generic,
 		colorMenu:          (s) => s.$store.getters.colorMenu,
+		dataChangedLast:    (s) => s.$store.getters.dataChangedLast,
 		isAdmin:            (s) => s.$store.getters.isAdmin,
 		isAtFavoritesEdit:  (s) => s.$store.getters.isAtFavoritesEdit,
 		isAtHistoryStart:   (s) => s.$store.getters.isAtHistoryStart,
@@ -897,6 +926,7 @@ export default {
 		isNoAuth:           (s) => s.$store.getters.isNoAuth,
 		keyLength:          (s) => s.$store.getters.constants.keyLength,
 		loginId:            (s) => s.$store.getters.loginId,
+		recordLockLast:     (s) => s.$store.getters.recordLockLast,
 		loginPublicKey:     (s) => s.$store.getters.loginPublicKey,
 		loginPrivateKey:    (s) => s.$store.getters.loginPrivateKey,
 		patternStyle:       (s) => s.$store.getters.patternStyle,
@@ -929,10 +959,12 @@ export default {
 		getJoinIndexMapExpanded,
 		getQueryAttributePkFilter,
 		getQueryFiltersProcessed,
+		getQueryRelationIds,
 		getRandomString,
 		getRelationsJoined,
 		getResolvedPlaceholders,
 		getRowsDecrypted,
+		hasAccessToRelation,
 		isAttributeRelationship,
 		isAttributeRelationshipN1,
 		jsFunctionRun,
@@ -1009,6 +1041,9 @@ export default {
 				}
 			}
 			
+			// release record locks, locks for the loaded record are acquired after its retrieval
+			this.unlockRecords();
+
 			// reset form behaviour and load record
 			this.blockInputs = false;
 			this.firstLoad   = false;
@@ -1044,6 +1079,7 @@ export default {
 			this.indexesNoSet              = [];
 			this.indexMapRecordId          = {};
 			this.indexMapRecordKey         = {};
+			this.indexMapRecordVersion     = {};
 			this.fieldIdsTouched           = [];
 		},
 		releaseLoadingOnNextTick() {
@@ -1136,6 +1172,11 @@ export default {
 					this.indexesNoSet.splice(pos,1);
 			}
 			
+			// update record versions for each relation index
+			for(let index in row.indexRecordVersions) {
+				this.indexMapRecordVersion[index] = row.indexRecordVersions[index];
+			}
+			
 			// update record data keys for each relation index
 			for(let index in row.indexRecordEncKeys) {
 				this.indexMapRecordKey[index] = await this.rsaDecrypt(
@@ -1490,9 +1531,20 @@ export default {
 		},
 		
 		// backend calls
+		dataChanged(change) {
+			// records of relation were changed by anyone, reload unless there are unsaved changes
+			if(change === null || this.loading || this.isNew || this.isBulkUpdate || this.hasChanges)
+				return;
+			
+			for(const j of this.joins) {
+				if(j.relationId === change.relationId && this.indexMapRecordId[j.index] !== undefined)
+					return this.get();
+			}
+		},
 		delAsk(deleteAndNew) {
 			this.$store.commit('dialog',{
-				captionBody:this.capApp.dialog.delete,
+				captionBody:this.relationIdMap[this.relationId].softDelete
+					? this.capApp.dialog.deleteTrash : this.capApp.dialog.delete,
 				buttons:[{
 					cancel:true,
 					caption:this.capGen.button.delete,
@@ -1574,11 +1626,13 @@ export default {
 			
 			ws.send('data','get',{
 				relationId:this.relationId,
+				queryId:this.form.query.id,
 				indexSource:0,
 				joins:this.relationsJoined,
 				expressions:expressions,
 				filters:filters,
-				getPerm:true
+				getPerm:true,
+				getVersions:this.usesVersions
 			},true).then(
 				res => {
 					// reset states
@@ -1586,7 +1640,10 @@ export default {
 					this.loading = true;
 					
 					this.valueSetByRows(res.payload.rows,expressions).then(
-						() => this.triggerEventAfter('open'),
+						() => {
+							this.triggerEventAfter('open');
+							this.lockRecords();
+						},
 						err => {
 							this.badLoad = true;
 							this.consoleError(err);
@@ -1673,21 +1730,90 @@ export default {
 			this.triggerEventBefore('open');
 			ws.send('data','get',{
 				relationId:join.relationId,
+				queryId:this.form.query.id,
 				indexSource:join.index,
 				joins:joins,
 				expressions:expressions,
 				filters:filters,
-				getPerm:true
+				getPerm:true,
+				getVersions:this.usesVersions
 			},true).then(
 				res => {
 					this.valueSetByRows(res.payload.rows,expressions).then(
-						() => this.triggerEventAfter('open'),
+						() => {
+							this.triggerEventAfter('open');
+							this.lockRecords();
+						},
 						err => this.$root.genericError
 					);
 				},
 				this.$root.genericError
 			);
 		},
+		lockRecords() {
+			// acquire locks for records of pessimistic lock relations, if they can be updated
+			for(const j of this.joins) {
+				const lock     = this.indexMapRecordLock[j.index];
+				const recordId = this.indexMapRecordId[j.index];
+				
+				if(this.relationIdMap[j.relationId].recordLock !== 'pessimistic' || !Number.isInteger(recordId) || recordId === 0)
+					continue;
+				
+				if(this.form.noDataActions || !j.applyUpdate || this.indexesNoSet.includes(j.index) ||
+					!this.hasAccessToRelation(this.access,j.relationId,2)) {
+					
+					continue;
+				}
+				
+				// lock is already held for this record
+				if(lock !== undefined && lock.own && lock.recordId === recordId)
+					continue;
+				
+				ws.send('data','lock',{relationId:j.relationId,recordId:recordId},false).then(
+					res => {
+						// release lock if record changed while lock was requested
+						if(this.indexMapRecordId[j.index] !== recordId) {
+							if(res.payload.own)
+								ws.send('data','unlock',{relationId:j.relationId,recordId:recordId},false);
+							
+							return;
+						}
+						this.indexMapRecordLock[j.index] = res.payload;
+					},
+					this.$root.genericError
+				);
+			}
+		},
+		recordLockChanged(lock) {
+			if(lock === null)
+				return;
+			
+			for(const j of this.joins) {
+				if(j.relationId !== lock.relationId || this.indexMapRecordId[j.index] !== lock.recordId)
+					continue;
+				
+				const lockEx = this.indexMapRecordLock[j.index];
+				
+				// own locks are set by lock request, another client might use the same login
+				if(lock.locked && (lockEx === undefined || !lockEx.own)) {
+					this.indexMapRecordLock[j.index] = {
+						relationId:lock.relationId,
+						recordId:lock.recordId,
+						loginId:lock.loginId,
+						loginName:lock.loginName,
+						own:false
+					};
+				}
+				
+				// lock of other client was released, record might have been changed
+				if(!lock.locked && lockEx !== undefined && !lockEx.own) {
+					delete this.indexMapRecordLock[j.index];
+					
+					if(!this.hasChanges) this.get();
+					else                 this.lockRecords();
+				}
+			}
+		},
 		set:async function(saveAndNew,saveAndClose) {
 			if(this.fieldIdsInvalid.length !== 0)
 				return this.badSave = true;
@@ -1765,7 +1891,9 @@ export default {
 					indexFrom:j.indexFrom,
 					recordId:j.recordId,
 					attributes:[],
-					encKeysSet:encLoginKeys
+					encKeysSet:encLoginKeys,
+					version:!isNew && this.indexMapRecordVersion[index] !== undefined
+						? this.indexMapRecordVersion[index] : null
 				};
 			};
 			
@@ -1787,6 +1915,9 @@ export default {
 				if(!j.applyCreate && j.recordId === 0) continue;
 				if(!j.applyUpdate && j.recordId !== 0) continue;
 				
+				// ignore values of generated attributes, they are computed by the database
+				if(this.attributeIdMap[d.attributeId].generated !== null) continue;
+				
 				// add join to request to set attribute values and handle encryption keys
 				try        { await addRelationByIndex(d.index); }
 				catch(err) { return handleEncErr(err); }
@@ -1989,6 +2120,14 @@ export default {
 			).finally(
 				() => this.changingRecord = false
 			);
+		},
+		unlockRecords() {
+			for(const index in this.indexMapRecordLock) {
+				const lock = this.indexMapRecordLock[index];
+				if(lock.own)
+					ws.send('data','unlock',{relationId:lock.relationId,recordId:lock.recordId},false);
+			}
+			this.indexMapRecordLock = {};
 		}
 	}
 };
\ No newline at end of file
@@ -45,12 +45,18 @@ export default {
 			<span v-if="logs.length === 0">{{ capGen.nothingThere }}</span>
 			
 			<div class="entry" v-for="(l,i) in logs">
-				<div>
+				<div class="row space-between">
 					<my-button
 						@trigger="toggleLog(i)"
 						:caption="displayTitle(i,l.dateChange,l.loginName)"
 						:naked="true"
 					/>
+					<my-button image="undo.png"
+						v-if="canRevert"
+						@trigger="revertAsk(l.dateChange)"
+						:caption="capApp.button.revert"
+						:naked="true"
+					/>
 				</div>
 				
 				<div class="log-fields" v-if="logsShown.includes(i)">
@@ -134,7 +140,7 @@ export default {
 		values:             { type:Object,  required:true },
 		variableIdMapLocal: { type:Object,  required:true }
 	},
-	emits:['close-log'],
+	emits:['close-log','reverted'],
 	watch:{
 		formLoading(v) {
 			if(!v) this.get();
@@ -162,6 +168,17 @@ export default {
 			}
 			return out;
 		},
+		indexesRevertable:(s) => {
+			let out = [];
+			for(let index in s.joinsIndexMap) {
+				if(s.joinsIndexMap[index].recordUpdate)
+					out.push(parseInt(index));
+			}
+			return out;
+		},
+		
+		// simple
+		canRevert:(s) => !s.formLoading && s.indexesRevertable.length !== 0,
 		
 		// stores
 		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
@@ -238,6 +255,51 @@ export default {
 		},
 		
 		// backend calls
+		revertAsk(dateChange) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.revert.replace('{DATE}',
+					this.getUnixFormat(dateChange,[this.settings.dateFormat,'H:i:S'].join(' '))),
+				buttons:[{
+					caption:this.capApp.button.revert,
+					exec:() => this.revert(dateChange),
+					image:'undo.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		revert(dateChange) {
+			let requests = [];
+			for(const index of this.indexesRevertable) {
+				const j = this.joinsIndexMap[index];
+				
+				// revert attributes on this form, file attribute logs only contain changes
+				let attributeIds = [];
+				for(let k in this.values) {
+					const d = this.getDetailsFromIndexAttributeId(k);
+					const a = this.attributeIdMap[d.attributeId];
+					
+					if(d.index === index && !this.isAttributeFiles(a.content) && !attributeIds.includes(a.id))
+						attributeIds.push(a.id);
+				}
+				
+				if(attributeIds.length !== 0)
+					requests.push(ws.prepare('data','revertLog',{
+						relationId:j.relationId,
+						recordId:j.recordId,
+						date:dateChange,
+						attributeIds:attributeIds
+					}));
+			}
+			if(requests.length === 0)
+				return;
+			
+			ws.sendMultiple(requests,true).then(
+				() => this.$emit('reverted'),
+				this.$root.genericError
+			);
+		},
 		get() {
 			if(this.formLoading)
 				return;
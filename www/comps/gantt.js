@@ -20,6 +20,7 @@ import {
 	getQueryExpressions,
 	getQueryExpressionsDateRange,
 	getQueryFiltersDateRange,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -519,6 +520,7 @@ const MyGantt = {
 		isEmpty:        (s) => s.groups.length === 0,
 		isHours:        (s) => s.stepType === 'hours',
 		joins:          (s) => s.fillRelationRecordIds(s.query.joins),
+		relationIdsWatched:(s) => s.getQueryRelationIds(s.query), // relations of shown data, reloaded if changed by anyone
 		stepPixels:     (s) => s.stepBase * s.stepZoom,
 		styleHeaderItem:(s) => `width:${s.stepPixels}px;`,
 
@@ -534,6 +536,7 @@ const MyGantt = {
 		appResized:    (s) => s.$store.getters.appResized,
 		capApp:        (s) => s.$store.getters.captions.calendar,
 		capGen:        (s) => s.$store.getters.captions.generic,
+		dataChangedLast:(s) => s.$store.getters.dataChangedLast,
 		isMobile:      (s) => s.$store.getters.isMobile,
 		settings:      (s) => s.$store.getters.settings
 	},
@@ -581,10 +584,19 @@ const MyGantt = {
 		this.dateStart = this.getDateNowRounded();
 		this.ready     = true;
 		this.$nextTick(() => this.setSteps(false));
+		
+		// reload when data of shown relations was changed
+		this.$watch('dataChangedLast',v => {
+			if(this.relationIdsWatched.includes(v.relationId))
+				this.get();
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
 	},
 	unmounted() {
 		if(this.usesHotkeys)
 			window.removeEventListener('keydown',this.handleHotkeys);
+		
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
 	},
 	methods:{
 		// external
@@ -596,6 +608,7 @@ const MyGantt = {
 		getQueryExpressions,
 		getQueryExpressionsDateRange,
 		getQueryFiltersDateRange,
+		getQueryRelationIds,
 		getRelationsJoined,
 		getUnixFromDate,
 		getUnixShifted,
@@ -813,6 +826,7 @@ const MyGantt = {
 
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.getRelationsJoined(this.joins),
 				expressions:this.getQueryExpressionsDateRange(
 					this.attributeIdDate0,this.indexDate0,
@@ -17,6 +17,7 @@ import {
 	fillRelationRecordIds,
 	getJoinIndexMap,
 	getQueryExpressions,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -520,6 +521,7 @@ const MyKanban = {
 		hasNullsInY:       (s) => s.attributeIdAxisY !== null && s.attributeIdMap[s.attributeIdAxisY].nullable,
 		joins:             (s) => s.fillRelationRecordIds(s.query.joins),
 		joinsIndexMap:     (s) => s.getJoinIndexMap(s.joins),
+		relationIdsWatched:(s) => s.getQueryRelationIds(s.query), // relations of shown data, reloaded if changed by anyone
 
 		// login options
 		choiceId:    (s) => s.$root.getOrFallback(s.loginOptions,'choiceId',s.choices.length === 0 ? null : s.choices[0].id),
@@ -531,7 +533,8 @@ const MyKanban = {
 		iconIdMap:     (s) => s.$store.getters['schema/iconIdMap'],
 		isMobile:      (s) => s.$store.getters.isMobile,
 		capApp:        (s) => s.$store.getters.captions.calendar,
-		capGen:        (s) => s.$store.getters.captions.generic
+		capGen:        (s) => s.$store.getters.captions.generic,
+		dataChangedLast:(s) => s.$store.getters.dataChangedLast
 	},
 	beforeCreate() {
 		// import at runtime due to circular dependencies
@@ -559,8 +562,18 @@ const MyKanban = {
 					return this.get();
 			}
 		});
+		
+		// reload when data of shown relations was changed
+		this.$watch('dataChangedLast',v => {
+			if(this.relationIdsWatched.includes(v.relationId))
+				this.get();
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
 		this.get();
 	},
+	beforeUnmount() {
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
+	},
 	methods:{
 		// external
 		checkDataOptions,
@@ -572,6 +585,7 @@ const MyKanban = {
 		getColumnBatches,
 		getJoinIndexMap,
 		getQueryExpressions,
+		getQueryRelationIds,
 		getRelationsJoined,
 		routeChangeFieldReload,
 		routeParseParams,
@@ -700,6 +714,7 @@ const MyKanban = {
 			
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.getRelationsJoined(this.joins),
 				expressions:this.expressions,
 				filters:this.filters.concat(this.choiceFilters),
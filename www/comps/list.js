@@ -10,6 +10,7 @@ import MyListInputFlow             from './listInputFlow.js';
 import MyListInputRows             from './listInputRows.js';
 import MyListInputRowsEmpty        from './listInputRowsEmpty.js';
 import MyListOptions               from './listOptions.js';
+import MyListTrash                 from './listTrash.js';
 import {consoleError}              from './shared/error.js';
 import {getRowsDecrypted}          from './shared/form.js';
 import {getCaption}                from './shared/language.js';
@@ -31,6 +32,8 @@ import {
 	getFiltersEncapsulated,
 	getQueryAttributesPkFilter,
 	getQueryExpressions,
+	getQueryGrouping,
+	getQueryRelationIds,
 	getRelationsJoined
 } from './shared/query.js';
 import {
@@ -51,7 +54,8 @@ export default {
 		MyListInputFlow,
 		MyListInputRows,
 		MyListInputRowsEmpty,
-		MyListOptions
+		MyListOptions,
+		MyListTrash
 	},
 	template:`<div class="list" ref="content"
 		@keydown="handleKeydownLocal"
@@ -63,7 +67,7 @@ export default {
 			@click.self.stop="closeHover"
 			:class="{'under-header':!isMobile}"
 		>
-			<div class="contentBox float scroll" :class="{ 'list-csv':showCsv, 'list-filters-wrap':showFilters, 'list-options':showOptions }">
+			<div class="contentBox float scroll" :class="{ 'list-csv':showCsv, 'list-filters-wrap':showFilters, 'list-options':showOptions, 'list-trash-wrap':showTrash }">
 				<div class="top lower">
 					<div class="area">
 						<img class="icon" :src="hoverIconSrc" />
@@ -118,6 +122,11 @@ export default {
 						:moduleId="moduleId"
 						:pageLimit="limit"
 					/>
+					<my-list-trash
+						v-if="showTrash"
+						@reload="get"
+						:relationId="query.relationId"
+					/>
 				</div>
 			</div>
 		</div>
@@ -255,6 +264,13 @@ export default {
 						:naked="true"
 					/>
 					
+					<my-button image="delete.png"
+						v-if="headerActions && hasTrash"
+						@trigger="showTrash = !showTrash"
+						:captionTitle="capApp.button.trashHint"
+						:naked="true"
+					/>
+					
 					<input autocomplete="off" class="short" enterkeyhint="send" type="text"
 						v-if="filterQuick"
 						@keyup.enter="updatedFilterQuick"
@@ -403,7 +419,7 @@ export default {
 									@click.left.exact="clickRow(r,false)"
 									@click.middle.exact="clickRow(r,true)"
 									@keyup.enter.space="clickRow(r,false)"
-									:class="{ rowSelect:rowSelect && !inputIsReadonly, active:popUpFormInline !== null && popUpFormInline.recordIds.includes(r.indexRecordIds['0']) }"
+									:class="{ rowSelect:rowSelect && !inputIsReadonly, grouping:r.grouping !== undefined && r.grouping !== 0, active:popUpFormInline !== null && popUpFormInline.recordIds.includes(r.indexRecordIds['0']) }"
 									:key="ri + '_' + r.indexRecordIds['0']"
 									:ref="refTabindex+String(ri)"
 									:tabindex="isInput ? '0' : '-1'"
@@ -681,6 +697,7 @@ export default {
 			showCsv:false,              // show UI for CSV import/export
 			showFilters:false,          // show UI for user filters
 			showOptions:false,          // show UI for list options
+			showTrash:false,            // show UI for trash bin (soft deleted records)
 			
 			// constants
 			refTabindex:'input_row_', // prefix for vue references to tabindex elements
@@ -748,12 +765,14 @@ export default {
 			if     (s.showCsv)     return s.capApp.button.csv;
 			else if(s.showFilters) return s.capGen.button.filterHint;
 			else if(s.showOptions) return s.capGen.options;
+			else if(s.showTrash)   return s.capApp.trash;
 			return '';
 		},
 		hoverIconSrc:(s) => {
 			if     (s.showCsv)     return 'images/fileSheet.png';
 			else if(s.showFilters) return 'images/filterCog.png';
 			else if(s.showOptions) return 'images/listCog.png';
+			else if(s.showTrash)   return 'images/delete.png';
 			return '';
 		},
 		pageCount:(s) => {
@@ -794,12 +813,14 @@ export default {
 		autoSelect:          (s) => s.inputIsNew && s.inputAutoSelect !== 0 && !s.inputAutoSelectDone,
 		columnBatches:       (s) => s.getColumnBatches(s.moduleId,s.columns,[],s.orders,s.columnBatchSort[0],true),
 		columnBatchesAll:    (s) => s.getColumnBatches(s.moduleId,s.columnsAll,[],s.orders,[],true),
-		expressions:         (s) => s.getQueryExpressions(s.columns),
+		expressions:         (s) => s.getQueryExpressions(s.columns,s.columnsAll),
+		grouping:            (s) => s.getQueryGrouping(s.query,s.columns,s.columnsAll),
 		hasBulkActions:      (s) => !s.isInput && s.rows.length !== 0 && (s.hasUpdateBulk || s.hasDeleteAny),
 		hasChoices:          (s) => s.query.choices.length > 1,
 		hasCreate:           (s) => s.checkDataOptions(4,s.dataOptions) && s.joins.length !== 0 && s.joins[0].applyCreate && s.hasOpenForm,
 		hasPaging:           (s) => s.query.fixedLimit === 0,
 		hasResults:          (s) => s.rowsClear.length !== 0,
+		hasTrash:            (s) => !s.isInput && s.hasDeleteAny && s.relationIdMap[s.query.relationId].softDelete,
 		hasUpdate:           (s) => s.checkDataOptions(2,s.dataOptions) && s.joins.length !== 0 && s.joins[0].applyUpdate && s.hasOpenForm,
 		hasUpdateBulk:       (s) => s.checkDataOptions(2,s.dataOptions) && s.joins.length !== 0 && s.joins[0].applyUpdate && s.hasOpenFormBulk,
 		isCards:             (s) => s.layout === 'cards',
@@ -807,13 +828,14 @@ export default {
 		isTable:             (s) => s.layout === 'table',
 		joins:               (s) => s.fillRelationRecordIds(s.query.joins),
 		ordersOriginal:      (s) => JSON.parse(JSON.stringify(s.query.orders)),
+		relationIdsWatched:  (s) => s.getQueryRelationIds(s.query), // relations of shown data, reloaded if changed by anyone
 		relationsJoined:     (s) => s.getRelationsJoined(s.joins),
 		rowSelect:           (s) => s.isInput || s.hasUpdate,
 		rowsClear:           (s) => s.rows.filter(v => !s.inputRecordIds.includes(v.indexRecordIds['0'])),
 		showActionTitles:    (s) => s.headerElements.includes('actionTitles'),
 		showAllValues:       (s) => s.inputAsFlow || s.inputAsCategory,
 		showCollectionTitles:(s) => s.headerElements.includes('collectionTitles'),
-		showHover:           (s) => s.showCsv || s.showFilters || s.showOptions,
+		showHover:           (s) => s.showCsv || s.showFilters || s.showOptions || s.showTrash,
 		showInputAddAll:     (s) => s.inputMulti && s.hasResults,
 		showInputAddLine:    (s) => !s.showAllValues && (!s.anyInputRows || (s.inputMulti && !s.inputIsReadonly)),
 		showInputHeader:     (s) => s.isInput && (s.filterQuick || s.hasChoices || s.showInputAddAll || s.offset !== 0 || s.count > s.limit),
@@ -842,9 +864,11 @@ export default {
 		
 		// stores
 		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
+		relationIdMap: (s) => s.$store.getters['schema/relationIdMap'],
 		appResized:    (s) => s.$store.getters.appResized,
 		capApp:        (s) => s.$store.getters.captions.list,
 		capGen:        (s) => s.$store.getters.captions.generic,
+		dataChangedLast:(s) => s.$store.getters.dataChangedLast,
 		isMobile:      (s) => s.$store.getters.isMobile,
 		scrollFormId:  (s) => s.$store.getters.constants.scrollFormId,
 		settings:      (s) => s.$store.getters.settings
@@ -928,9 +952,17 @@ export default {
 		this.setAutoRenewTimer(this.autoRenew);
 		this.removeInvalidFilters();
 		this.removeInvalidOrders();
+		
+		// reload when data of shown relations was changed
+		this.$watch('dataChangedLast',v => {
+			if(this.relationIdsWatched.includes(v.relationId) && !this.rowsFetching)
+				this.reloadOutside();
+		});
+		this.$store.commit('relationWatchersAdd',this.relationIdsWatched);
 	},
 	beforeUnmount() {
 		this.clearAutoRenewTimer();
+		this.$store.commit('relationWatchersDel',this.relationIdsWatched);
 	},
 	methods:{
 		// externals
@@ -947,6 +979,8 @@ export default {
 		getOrderIndexesFromColumnBatch,
 		getQueryAttributesPkFilter,
 		getQueryExpressions,
+		getQueryGrouping,
+		getQueryRelationIds,
 		getRelationsJoined,
 		getRowsDecrypted,
 		isAttributeTextSearchable,
@@ -1087,6 +1121,7 @@ export default {
 			this.showCsv     = false;
 			this.showFilters = false;
 			this.showOptions = false;
+			this.showTrash   = false;
 		},
 		escape(ev) {
 			const somethingToClose = (this.isInput && this.dropdownShow) || this.showHover;
@@ -1100,6 +1135,7 @@ export default {
 				this.showCsv     = false;
 				this.showFilters = false;
 				this.showOptions = false;
+				this.showTrash   = false;
 			}
 			if(somethingToClose && ev !== undefined) {
 				ev.stopPropagation();
@@ -1366,7 +1402,7 @@ export default {
 		// backend calls
 		delAsk(rowIndexes) {
 			this.$store.commit('dialog',{
-				captionBody:this.capApp.dialog.delete,
+				captionBody:this.relationIdMap[this.query.relationId].softDelete ? this.capApp.dialog.deleteTrash : this.capApp.dialog.delete,
 				buttons:[{
 					cancel:true,
 					caption:this.capGen.button.delete,
@@ -1415,9 +1451,12 @@ export default {
 			this.rowsFetching = true;
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.relationsJoined,
 				expressions:this.expressions,
 				filters:this.filtersCombined,
+				grouping:this.grouping.grouping,
+				groupingSets:this.grouping.groupingSets,
 				orders:this.orders,
 				limit:this.limit,
 				offset:this.offset
@@ -1471,6 +1510,7 @@ export default {
 			
 			ws.send('data','get',{
 				relationId:this.query.relationId,
+				queryId:this.query.id,
 				joins:this.relationsJoined,
 				expressions:this.expressions,
 				filters:filters,
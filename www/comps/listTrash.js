@@ -0,0 +1,135 @@
+import MyInputOffset from './inputOffset.js';
+import {getCaption}  from './shared/language.js';
+import {
+	getUnixFormat,
+	getUnixShifted
+} from './shared/time.js';
+export {MyListTrash as default};
+
+let MyListTrash = {
+	name:'my-list-trash',
+	components:{ MyInputOffset },
+	template:`<div class="list-trash">
+		<p>{{ relation.softDeleteDays !== null ? capApp.trashHintDays.replace('{DAYS}',relation.softDeleteDays) : capApp.trashHint }}</p>
+
+		<div class="row gap centered">
+			<my-button image="refresh.png"
+				@trigger="get"
+				:caption="capGen.button.refresh"
+			/>
+			<my-input-offset
+				@input="offset = $event;get()"
+				:caption="true"
+				:limit="limit"
+				:offset="offset"
+				:total="total"
+			/>
+		</div>
+
+		<span v-if="total === 0"><i>{{ capApp.trashEmpty }}</i></span>
+
+		<table class="generic-table bright" v-if="total !== 0">
+			<thead>
+				<tr>
+					<th></th>
+					<th>{{ capApp.trashDateDeleted }}</th>
+					<th v-for="atr in attributes">{{ getCaption('attributeTitle',relation.moduleId,atr.id,atr.captions,atr.name) }}</th>
+				</tr>
+			</thead>
+			<tbody>
+				<tr v-for="r in records" :key="r.recordId">
+					<td>
+						<my-button image="undo.png"
+							@trigger="restore(r.recordId)"
+							:caption="capApp.button.trashRestore"
+						/>
+					</td>
+					<td>{{ getUnixFormat(r.dateDeleted,settings.dateFormat + ' H:i') }}</td>
+					<td v-for="atr in attributes">{{ displayValue(atr,r.attributes.find(v => v.attributeId === atr.id)) }}</td>
+				</tr>
+			</tbody>
+		</table>
+	</div>`,
+	props:{
+		relationId:{ type:String, required:true }
+	},
+	emits:['reload'],
+	data() {
+		return {
+			limit:10,
+			offset:0,
+			records:[],
+			total:0
+		};
+	},
+	computed:{
+		// attributes with values in any trash record
+		attributes:(s) => s.relation.attributes.filter(atr => s.records.some(r => r.attributes.some(v => v.attributeId === atr.id))),
+
+		// simple
+		relation:(s) => s.relationIdMap[s.relationId],
+
+		// stores
+		relationIdMap:(s) => s.$store.getters['schema/relationIdMap'],
+		capApp:       (s) => s.$store.getters.captions.list,
+		capGen:       (s) => s.$store.getters.captions.generic,
+		settings:     (s) => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+	},
+	methods:{
+		// externals
+		getCaption,
+		getUnixFormat,
+		getUnixShifted,
+
+		// presentation
+		displayValue(atr,value) {
+			if(value === undefined || value.value === null)
+				return '-';
+
+			const v = value.value;
+			switch(atr.contentUse) {
+				case 'date':     return this.getUnixFormat(this.getUnixShifted(v,true),this.settings.dateFormat); break;
+				case 'datetime': return this.getUnixFormat(v,this.settings.dateFormat + ' H:i');                  break;
+			}
+			if(typeof v === 'boolean')
+				return v ? this.capGen.option.yes : this.capGen.option.no;
+
+			const s = typeof v === 'object' ? JSON.stringify(v) : String(v);
+			return s.length > 50 ? s.substring(0,50) + '...' : s;
+		},
+
+		// backend calls
+		get() {
+			ws.send('data','getTrash',{
+				relationId:this.relationId,
+				limit:this.limit,
+				offset:this.offset
+			},true).then(
+				res => {
+					this.records = res.payload.records;
+					this.total   = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		restore(recordId) {
+			ws.send('data','restore',{
+				relationId:this.relationId,
+				recordIds:[recordId]
+			},true).then(
+				() => {
+					// go back one page if last record on page was restored
+					if(this.records.length === 1 && this.offset !== 0)
+						this.offset -= this.limit;
+
+					this.get();
+					this.$emit('reload');
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
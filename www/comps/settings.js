@@ -3,6 +3,7 @@ import srcBase64Icon       from './shared/image.js';
 import {getCaption}        from './shared/language.js';
 import {set as setSetting} from './shared/settings.js';
 import {getUnixFormat}     from './shared/time.js';
+import MyAdminSchedulerRun from './admin/adminSchedulerRun.js';
 import MyInputColorWrap    from './inputColorWrap.js';
 import MyInputHotkey       from './inputHotkey.js';
 import MyTabs              from './tabs.js';
@@ -820,6 +821,87 @@ const MySettingsClientEvents = {
 	}
 };
 
+const MySettingsTasks = {
+	name:'my-settings-tasks',
+	components:{ MyAdminSchedulerRun },
+	template:`<div class="settings-tasks">
+		<p>{{ capApp.intro }}</p>
+		<span v-if="schedules.length === 0"><i>{{ capApp.noTasks }}</i></span>
+
+		<div class="row gap centered space-between" v-for="s in schedules">
+			<div class="row gap centered">
+				<img class="module-icon" :src="srcBase64Icon(s.module.iconId,'images/module.png')" />
+				<span>{{ displayName(s.pgFunction) }}</span>
+			</div>
+			<my-button image="settingsPlay.png"
+				@trigger="runManual = s"
+				:caption="capApp.button.run"
+			/>
+		</div>
+
+		<!-- manual run of PG function schedule -->
+		<my-admin-scheduler-run
+			v-if="runManual !== null"
+			@close="runManual = null"
+			:name="displayName(runManual.pgFunction)"
+			:pgFunctionId="runManual.pgFunction.id"
+			:pgFunctionScheduleId="runManual.pgFunctionScheduleId"
+		/>
+	</div>`,
+	data() {
+		return {
+			pgFunctionScheduleIds:[], // schedules the login may run manually via its roles
+			runManual:null            // schedule to run manually
+		};
+	},
+	computed:{
+		schedules:(s) => {
+			let out = [];
+			for(const fncId in s.pgFunctionIdMap) {
+				const f = s.pgFunctionIdMap[fncId];
+
+				for(const fs of f.schedules) {
+					if(s.pgFunctionScheduleIds.includes(fs.id))
+						out.push({
+							module:s.moduleIdMap[f.moduleId],
+							pgFunction:f,
+							pgFunctionScheduleId:fs.id
+						});
+				}
+			}
+			return out;
+		},
+
+		// stores
+		moduleIdMap:    (s) => s.$store.getters['schema/moduleIdMap'],
+		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         (s) => s.$store.getters.captions.settings.tasks
+	},
+	mounted() {
+		this.get();
+	},
+	methods:{
+		// externals
+		getCaption,
+		srcBase64Icon,
+
+		// presentation
+		displayName(f) {
+			const m = this.moduleIdMap[f.moduleId];
+			return this.getCaption('moduleTitle',m.id,m.id,m.captions,m.name) + ': '
+				+ this.getCaption('pgFunctionTitle',f.moduleId,f.id,f.captions,f.name);
+		},
+
+		// backend calls
+		get() {
+			ws.send('task','getManual',{},true).then(
+				res => this.pgFunctionScheduleIds = res.payload,
+				this.$root.genericError
+			);
+		}
+	}
+};
+
 const MySettingsFixedTokens = {
 	name:'my-settings-fixed-tokens',
 	components:{MyTabs},
@@ -1218,7 +1300,8 @@ const MySettings = {
 		MySettingsAccount,
 		MySettingsClientEvents,
 		MySettingsEncryption,
-		MySettingsFixedTokens
+		MySettingsFixedTokens,
+		MySettingsTasks
 	},
 	template:`<div class="settings contentBox grow scroll float">
 		<div class="top lower">
@@ -1477,6 +1560,15 @@ const MySettings = {
 				<my-settings-client-events />
 			</div>
 			
+			<!-- tasks (manual runs of scheduled functions) -->
+			<div class="contentPart">
+				<div class="contentPartHeader">
+					<img class="icon" src="images/settingsPlay.png" />
+					<h1>{{ capApp.titleTasks }}</h1>
+				</div>
+				<my-settings-tasks />
+			</div>
+			
 			<!-- encryption -->
 			<div class="contentPart">
 				<div class="contentPartHeader">
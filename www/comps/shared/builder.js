@@ -147,6 +147,79 @@ export function getSqlPreview(query,columns) {
 	);
 };
 
+export function getSqlExplain(query,columns) {
+	getSqlExplainDialog({dataGet:{
+		relationId:query.relationId,
+		joins:getRelationsJoined(query.joins),
+		expressions:getQueryExpressions(columns),
+		filters:getQueryFiltersProcessed(query.filters,getJoinIndexMap(query.joins)),
+		orders:query.orders,
+		limit:query.fixedLimit !== 0 ? query.fixedLimit : 0
+	}});
+};
+export function getSqlExplainApi(apiId) {
+	getSqlExplainDialog({apiId:apiId,getters:{}});
+};
+function getSqlExplainDialog(payload) {
+	const cap          = MyStore.getters.captions.generic;
+	const attributeMap = MyStore.getters['schema/attributeIdMap'];
+	const relationMap  = MyStore.getters['schema/relationIdMap'];
+	
+	ws.send('dataSql','explain',payload,true).then(
+		res => {
+			const e = res.payload;
+			let lines = [
+				cap.sqlExplainTime
+					.replace('{PLANNING}',e.planningTime.toFixed(2))
+					.replace('{EXECUTION}',e.executionTime.toFixed(2)),
+				''
+			];
+			
+			// sequential scans on large relations, with filtered attributes that have no index
+			for(const w of e.warnings) {
+				lines.push('! ' + cap.sqlExplainWarning
+					.replace('{NAME}',relationMap[w.relationId].name)
+					.replace('{COUNT}',w.rowsEstimate));
+				
+				if(w.filter !== '')
+					lines.push(`  Filter: ${w.filter}`);
+				
+				if(w.attributeIds.length !== 0)
+					lines.push('  ' + cap.sqlExplainIndex.replace('{NAMES}',
+						w.attributeIds.map(v => attributeMap[v].name).join(', ')));
+				
+				lines.push('');
+			}
+			
+			// plan tree
+			const addNode = (n,depth) => {
+				const indent = '  '.repeat(depth);
+				let title = n.nodeType;
+				if(n.relation !== '') title += ` on ${n.schema}.${n.relation} ${n.alias}`;
+				if(n.index    !== '') title += ` using ${n.index}`;
+				
+				lines.push(`${indent}${depth !== 0 ? '-> ' : ''}${title} (rows: ${n.rowsActual}/${n.rowsPlanned}, loops: ${n.loops}, time: ${n.timeTotal.toFixed(2)} ms, buffers: ${n.blocksHit} hit/${n.blocksRead} read)`);
+				
+				if(n.condition !== '') lines.push(`${indent}   Cond: ${n.condition}`);
+				if(n.filter    !== '') lines.push(`${indent}   Filter: ${n.filter} (removed: ${n.rowsFiltered})`);
+				
+				for(const c of n.plans)
+					addNode(c,depth+1);
+			};
+			addNode(e.plan,0);
+			
+			MyStore.commit('dialog',{
+				captionTop:cap.sqlExplain,
+				captionBody:lines.join('\n'),
+				image:'database.png',
+				textDisplay:'textarea',
+				width:1000
+			});
+		},
+		MyStore.getters.appFunctions.genericError
+	);
+};
+
 export function getValueFromJson(inputJson,nameChain,valueFallback) {
 	let o = JSON.parse(inputJson);
 	for(let i = 0, j = nameChain.length; i < j; i++) {
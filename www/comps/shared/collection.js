@@ -186,6 +186,7 @@ export function updateCollections(collectionId) {
 			requestIds.push(c.id);
 			dataRequests.push(ws.prepare('data','get',{
 				relationId:q.relationId,
+				queryId:q.id,
 				joins:getRelationsJoined(q.joins),
 				expressions:getQueryExpressions(columns),
 				filters:filters,
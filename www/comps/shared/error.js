@@ -65,6 +65,11 @@ export function resolveErrCode(message) {
 	let   cap  = MyStore.getters.captions.error[errContext][errNumber];
 	
 	// handle cases with error context data
+	if(errContext === 'APP') {
+		switch(errNumber) {
+			case '010': return cap.replace('{NAME}',data.loginName); break;
+		}
+	}
 	if(errContext === 'CSV') {
 		switch(errNumber) {
 			case '001': // fallthrough, invalid number (int)
@@ -14,14 +14,39 @@ import {
 	getUnixNowTime
 } from './time.js';
 
-let getQueryExpressionAttribute = function(column) {
-	return {
+let getQueryExpressionAttribute = function(column,columnPosMap) {
+	let expr = {
 		attributeId:column.attributeId,
 		index:column.index,
 		groupBy:column.groupBy,
 		aggregator:column.aggregator,
-		distincted:column.distincted
+		dateBucket:column.dateBucket,
+		distincted:column.distincted,
+		window:column.window
 	};
+	if(column.window !== null && !column.subQuery) {
+		expr.windowOffset    = column.windowOffset;
+		expr.windowPartition = column.windowPartition
+			.filter(p => columnPosMap[p] !== undefined)
+			.map(p => columnPosMap[p]);
+		expr.windowOrders = column.windowOrders
+			.filter(o => columnPosMap[o.columnPos] !== undefined)
+			.map(o => { return { expressionPos:columnPosMap[o.columnPos], ascending:o.ascending }; });
+	}
+	return expr;
+};
+
+// window & grouping options refer to positions of all columns
+// if only some columns are retrieved (hidden by user), their positions differ
+// returns map of positions, key: position in all columns, value: position in retrieved columns
+let getQueryColumnPosMap = function(columns,columnsAll) {
+	let map = {};
+	for(let i = 0, j = columnsAll.length; i < j; i++) {
+		const pos = columns.findIndex(v => v.id === columnsAll[i].id);
+		if(pos !== -1)
+			map[i] = pos;
+	}
+	return map;
 };
 
 // map of joins keyed by relation index
@@ -75,16 +100,27 @@ export function getRelationsJoined(joins) {
 	return out;
 };
 
-export function getQueryExpressions(columns) {
+export function getQueryRelationIds(query) {
+	// source and joined relations of a query, whose data is shown by it
+	let out = query.relationId === null ? [] : [query.relationId];
+	for(const j of query.joins) {
+		if(!out.includes(j.relationId))
+			out.push(j.relationId);
+	}
+	return out;
+};
+
+export function getQueryExpressions(columns,columnsAll) {
+	const columnPosMap = getQueryColumnPosMap(columns,columnsAll !== undefined && columnsAll.length !== 0 ? columnsAll : columns);
 	let out = [];
 	for(const c of columns) {
 		if(!c.subQuery) {
-			out.push(getQueryExpressionAttribute(c));
+			out.push(getQueryExpressionAttribute(c,columnPosMap));
 			continue;
 		}
 		
 		// move expression aggregator to query (allows ORDER BY in aggregation)
-		let expr = getQueryExpressionAttribute(c);
+		let expr = getQueryExpressionAttribute(c,columnPosMap);
 		expr.aggregator = null;
 		
 		out.push({
@@ -102,6 +138,19 @@ export function getQueryExpressions(columns) {
 	return out;
 };
 
+export function getQueryGrouping(query,columns,columnsAll) {
+	if(query.grouping === undefined || query.grouping === null)
+		return { grouping:'', groupingSets:[] };
+	
+	const columnPosMap = getQueryColumnPosMap(columns,columnsAll !== undefined && columnsAll.length !== 0 ? columnsAll : columns);
+	return {
+		grouping:query.grouping,
+		groupingSets:query.groupingSets.map(set => set
+			.filter(p => columnPosMap[p] !== undefined)
+			.map(p => columnPosMap[p]))
+	};
+};
+
 export function getQueryExpressionsDateRange(attributeId0,index0,attributeId1,index1,attributeIdColor,indexColor) {
 	// fixed date range expressions
 	let expr = [
@@ -293,7 +342,8 @@ export function getQueryAttributesPkFilter(relationId,recordIds,index,not) {
 export function getQueryTemplate() {
 	return {
 		id:'00000000-0000-0000-0000-000000000000',
-		relationId:null,fixedLimit:0,joins:[],filters:[],orders:[],lookups:[],choices:[]
+		relationId:null,fixedLimit:0,joins:[],filters:[],orders:[],lookups:[],choices:[],
+		grouping:null,groupingSets:[]
 	};
 };
 
@@ -18,12 +18,15 @@ import {
 
 // admin
 import MyAdmin               from './comps/admin/admin.js';
+import MyAdminApiKeys        from './comps/admin/adminApiKeys.js';
+import MyAdminApiRates       from './comps/admin/adminApiRates.js';
 import MyAdminBackups        from './comps/admin/adminBackups.js';
 import MyAdminCaptionMap     from './comps/admin/adminCaptionMap.js';
 import MyAdminCluster        from './comps/admin/adminCluster.js';
 import MyAdminConfig         from './comps/admin/adminConfig.js';
 import MyAdminCustom         from './comps/admin/adminCustom.js';
 import MyAdminFiles          from './comps/admin/adminFiles.js';
+import MyAdminJobs           from './comps/admin/adminJobs.js';
 import MyAdminLdaps          from './comps/admin/adminLdaps.js';
 import MyAdminLicense        from './comps/admin/adminLicense.js';
 import MyAdminLogins         from './comps/admin/adminLogins.js';
@@ -36,8 +39,10 @@ import MyAdminMailTraffic    from './comps/admin/adminMailTraffic.js';
 import MyAdminModules        from './comps/admin/adminModules.js';
 import MyAdminOauthClients   from './comps/admin/adminOauthClients.js';
 import MyAdminRepo           from './comps/admin/adminRepo.js';
+import MyAdminRestSpooler    from './comps/admin/adminRestSpooler.js';
 import MyAdminRoles          from './comps/admin/adminRoles.js';
 import MyAdminScheduler      from './comps/admin/adminScheduler.js';
+import MyAdminSlowQueries    from './comps/admin/adminSlowQueries.js';
 import MyAdminSystemMsg      from './comps/admin/adminSystemMsg.js';
 
 // builder
@@ -96,12 +101,15 @@ const MyRouter = VueRouter.createRouter({
 		redirect:'/admin/config',
 		component:MyAdmin,
 		children:[
+			{ path:'api-keys',        component:MyAdminApiKeys },
+			{ path:'api-rates',       component:MyAdminApiRates },
 			{ path:'backups',         component:MyAdminBackups },
 			{ path:'caption-map',     component:MyAdminCaptionMap },
 			{ path:'cluster',         component:MyAdminCluster },
 			{ path:'config',          component:MyAdminConfig },
 			{ path:'custom',          component:MyAdminCustom },
 			{ path:'files',           component:MyAdminFiles },
+			{ path:'jobs',            component:MyAdminJobs },
 			{ path:'ldaps',           component:MyAdminLdaps },
 			{ path:'license',         component:MyAdminLicense },
 			{ path:'logins',          component:MyAdminLogins },
@@ -114,8 +122,10 @@ const MyRouter = VueRouter.createRouter({
 			{ path:'modules',         component:MyAdminModules },
 			{ path:'oauth-clients',   component:MyAdminOauthClients },
 			{ path:'repo',            component:MyAdminRepo },
+			{ path:'rest-spooler',    component:MyAdminRestSpooler },
 			{ path:'roles',           component:MyAdminRoles },
 			{ path:'scheduler',       component:MyAdminScheduler },
+			{ path:'slow-queries',    component:MyAdminSlowQueries },
 			{ path:'system-msg',      component:MyAdminSystemMsg }
 		]
 	},{
@@ -50,6 +50,7 @@ const MyStore = Vuex.createStore({
 			keyLength:64,              // length of new symmetric keys for data encryption
 			scrollFormId:'form-scroll' // ID of form page element (to recover scroll position during routing)
 		},
+		dataChangedLast:null,          // last relation with changed data, informed by backend ({relationId})
 		dialogCaptionTop:'',
 		dialogCaptionBody:'',
 		dialogButtons:[],
@@ -101,6 +102,8 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:null,          // configuration of global pop-up form
 		productionMode:false,          // system in production mode, false if maintenance
 		pwaDomainMap:{},               // map of modules per PWA sub domain, key: sub domain, value: module ID
+		recordLockLast:null,           // last changed record lock, informed by backend ({relationId, recordId, locked, loginId, loginName})
+		relationIdMapWatchers:{},      // relations watched for data changes by open fields & forms, key: relation ID, value: number of watchers
 		routingGuards:[],              // functions to call before routing, abort if any returns falls
 		searchDictionaries:[],         // dictionaries used for full text search for this login, ['english', 'german', ...]
 		settings:{},                   // setting values for logged in user, key: settings name
@@ -224,6 +227,22 @@ const MyStore = Vuex.createStore({
 		pageTitleRefresh:(state,payload) => {
 			MyStore.commit('pageTitle',state.pageTitle);
 		},
+		relationWatchersAdd:(state,payload) => {
+			// expected payload: array of relation IDs
+			for(const id of payload) {
+				state.relationIdMapWatchers[id] = (state.relationIdMapWatchers[id] ?? 0) + 1;
+			}
+		},
+		relationWatchersDel:(state,payload) => {
+			// expected payload: array of relation IDs, as used for adding
+			for(const id of payload) {
+				if(state.relationIdMapWatchers[id] === undefined)
+					continue;
+				
+				if(--state.relationIdMapWatchers[id] === 0)
+					delete(state.relationIdMapWatchers[id]);
+			}
+		},
 		routingGuardAdd:(state,payload) => {
 			state.routingGuards.push(payload);
 		},
@@ -279,6 +298,7 @@ const MyStore = Vuex.createStore({
 		captions:                (state,payload) => state.captions                 = payload,
 		captionMapCustom:        (state,payload) => state.captionMapCustom         = payload,
 		clusterNodeName:         (state,payload) => state.clusterNodeName          = payload,
+		dataChangedLast:         (state,payload) => state.dataChangedLast          = payload,
 		dropdownElm:             (state,payload) => state.dropdownElm              = payload,
 		feedback:                (state,payload) => state.feedback                 = payload,
 		feedbackUrl:             (state,payload) => state.feedbackUrl              = payload,
@@ -316,6 +336,7 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:         (state,payload) => state.popUpFormGlobal          = payload,
 		productionMode:          (state,payload) => state.productionMode           = payload,
 		pwaDomainMap:            (state,payload) => state.pwaDomainMap             = payload,
+		recordLockLast:          (state,payload) => state.recordLockLast           = payload,
 		searchDictionaries:      (state,payload) => state.searchDictionaries       = payload,
 		settings:                (state,payload) => state.settings                 = payload,
 		system:                  (state,payload) => state.system                   = payload,
@@ -446,6 +467,7 @@ const MyStore = Vuex.createStore({
 		config:                  (state) => state.config,
 		constants:               (state) => state.constants,
 		cryptoApiAvailable:      (state) => typeof crypto.subtle !== 'undefined',
+		dataChangedLast:         (state) => state.dataChangedLast,
 		dialogCaptionTop:        (state) => state.dialogCaptionTop,
 		dialogCaptionBody:       (state) => state.dialogCaptionBody,
 		dialogButtons:           (state) => state.dialogButtons,
@@ -499,6 +521,8 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:         (state) => state.popUpFormGlobal,
 		productionMode:          (state) => state.productionMode,
 		pwaDomainMap:            (state) => state.pwaDomainMap,
+		recordLockLast:          (state) => state.recordLockLast,
+		relationIdsWatched:      (state) => Object.keys(state.relationIdMapWatchers).sort(),
 		routingGuards:           (state) => state.routingGuards,
 		searchDictionaries:      (state) => state.searchDictionaries,
 		settings:                (state) => state.settings,
@@ -27,6 +27,7 @@ const MyStoreSchema = {
 		roleIdMap:{},
 		searchBarIdMap:{},
 		variableIdMap:{},
+		webhookIdMap:{},
 		widgetIdMap:{},
 		
 		// computed
@@ -150,6 +151,11 @@ const MyStoreSchema = {
 					state.variableIdMap[variable.id] = variable;
 				}
 				
+				// process webhooks
+				for(const webhook of mod.webhooks) {
+					state.webhookIdMap[webhook.id] = webhook;
+				}
+				
 				// process widgets
 				for(const widget of mod.widgets) {
 					state.widgetIdMap[widget.id] = widget;
@@ -197,6 +203,7 @@ const MyStoreSchema = {
 		roleIdMap:          (state) => state.roleIdMap,
 		searchBarIdMap:     (state) => state.searchBarIdMap,
 		variableIdMap:      (state) => state.variableIdMap,
+		webhookIdMap:       (state) => state.webhookIdMap,
 		widgetIdMap:        (state) => state.widgetIdMap,
 		
 		languageCodesModules:(state) => {
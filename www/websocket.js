@@ -66,6 +66,14 @@ let ws = {
 		if(trans === undefined)
 			return false;
 		
+		// partial result of streaming transaction, final response follows
+		if(msg.responses.length === 1 && msg.responses[0].ressource === 'stream') {
+			if(trans.chunk !== undefined)
+				trans.chunk(msg.responses[0].payload);
+			
+			return true;
+		}
+		
 		// unblock, if blocking transaction has been active
 		if(trans.blocking) {
 			this.blockingCount--;
@@ -91,7 +99,13 @@ let ws = {
 	send(ressource,action,payload,blocking,noDbTx) {
 		return this.sendMultiple([this.prepare(ressource,action,payload)],blocking,noDbTx,true);
 	},
-	sendMultiple(requests,blocking,noDbTx,singleResponse) {
+	
+	// send streaming request, partial results are passed to chunk callback as they arrive
+	// promise resolves with final response once all chunks have been received
+	sendStream(ressource,action,payload,chunk,blocking) {
+		return this.sendMultiple([this.prepare(ressource,action,payload)],blocking,false,true,chunk);
+	},
+	sendMultiple(requests,blocking,noDbTx,singleResponse,chunk) {
 		return new Promise((resolve,reject) => {
 			if(this.conn === null)
 				return reject('websocket connection not open');
@@ -112,6 +126,7 @@ let ws = {
 			// store transaction for response matching
 			this.transactions[transactionNr] = {
 				blocking:blocking,
+				chunk:chunk,
 				reject:reject,
 				resolve:resolve,
 				singleResponse:singleResponse